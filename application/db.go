@@ -3,6 +3,7 @@ package application
 import (
 	"os"
 
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
 	"github.com/go-pg/pg"
 )
 
@@ -22,6 +23,7 @@ func ResolveDB() *pg.DB {
 
 	options.PoolSize = 5
 	db = pg.Connect(options)
+	db.AddQueryHook(tracing.QueryHook{})
 	return db
 }
 
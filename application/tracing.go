@@ -0,0 +1,19 @@
+package application
+
+import (
+	"os"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+)
+
+// ConfigureTracing selects the span exporter from the TRACING_EXPORTER
+// environment variable. "stdout" writes spans as JSON lines to standard
+// output; any other value discards them.
+func ConfigureTracing() {
+	switch os.Getenv("TRACING_EXPORTER") {
+	case "stdout":
+		tracing.SetExporter(tracing.NewStdoutExporter(os.Stdout))
+	default:
+		tracing.SetExporter(tracing.NopExporter{})
+	}
+}
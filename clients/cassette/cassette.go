@@ -0,0 +1,302 @@
+// Package cassette records the HTTP interactions of a client with a service
+// into cassette files and replays them in tests, so that clients can be
+// tested against the responses that the service gave without running it.
+// The responses are only as real as the service they were recorded from.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Mode decides whether a Recorder talks to the service or replays a
+// cassette.
+type Mode string
+
+// The modes that a Recorder can run in.
+const (
+	// ModeReplay answers requests from the cassette and never makes real
+	// requests.
+	ModeReplay Mode = "replay"
+	// ModeRecord makes real requests and records them into the cassette,
+	// replacing what it held.
+	ModeRecord Mode = "record"
+)
+
+// ModeFromEnv selects the mode from the CASSETTE_MODE environment variable.
+// It defaults to replaying so that tests never reach real services unless
+// asked to.
+func ModeFromEnv() (Mode, error) {
+	switch mode := Mode(os.Getenv("CASSETTE_MODE")); mode {
+	case "", ModeReplay:
+		return ModeReplay, nil
+	case ModeRecord:
+		return ModeRecord, nil
+	default:
+		return "", errors.Errorf("%q: unknown cassette mode", mode)
+	}
+}
+
+// RedactedValue replaces the values of redacted headers in cassettes.
+const RedactedValue = "REDACTED"
+
+// Cassette is the content of a cassette file.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a request and the response that the service gave to it.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. URL holds only the path and query, so that
+// cassettes can be replayed against any base URL.
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// New returns a Recorder for the cassette at path. In ModeReplay the
+// cassette is read straight away and must exist.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{
+		path:     path,
+		mode:     mode,
+		cassette: &Cassette{Interactions: []*Interaction{}},
+		redacted: make(map[string]bool),
+	}
+
+	switch mode {
+	case ModeReplay:
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading cassette")
+		}
+		if err = json.Unmarshal(data, r.cassette); err != nil {
+			return nil, errors.Wrapf(err, "parsing cassette %s", path)
+		}
+	case ModeRecord:
+		// The cassette is only written by Save.
+	default:
+		return nil, errors.Errorf("%q: unknown cassette mode", mode)
+	}
+
+	r.played = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// Recorder is an http.RoundTripper that records interactions into a cassette
+// or replays them from it. Requests are matched to recorded interactions by
+// method, path and query, and every interaction is replayed at most once, in
+// the order it was recorded. It is safe for concurrent use.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+	redacted  map[string]bool
+
+	mu       sync.Mutex
+	cassette *Cassette
+	played   []bool
+}
+
+// SetTransport sets the transport that real requests are made with while
+// recording. It defaults to http.DefaultTransport.
+func (r *Recorder) SetTransport(t http.RoundTripper) {
+	r.transport = t
+}
+
+func (r *Recorder) getTransport() http.RoundTripper {
+	if r.transport != nil {
+		return r.transport
+	}
+
+	r.transport = http.DefaultTransport
+	return r.transport
+}
+
+// RedactHeaders keeps the values of the named request and response headers,
+// such as Authorization, out of recorded cassettes.
+func (r *Recorder) RedactHeaders(names ...string) {
+	for _, name := range names {
+		r.redacted[http.CanonicalHeaderKey(name)] = true
+	}
+}
+
+// Client returns an http.Client that sends its requests through r.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip records or replays req. When replaying, a request that matches
+// no interaction left in the cassette fails with an error that names it.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == ModeReplay {
+		return r.replay(req)
+	}
+
+	return r.record(req)
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.played[i] || !matches(interaction.Request, req) {
+			continue
+		}
+
+		r.played[i] = true
+		return &http.Response{
+			Status:        http.StatusText(interaction.Response.StatusCode),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        cloneHeader(interaction.Response.Headers),
+			Body:          ioutil.NopCloser(bytes.NewBufferString(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, errors.Errorf("cassette %s: no unplayed interaction matches %s %s", r.path, req.Method, req.URL.RequestURI())
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	res, err := r.getTransport().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{
+		Request: Request{
+			Method:  req.Method,
+			URL:     req.URL.RequestURI(),
+			Headers: r.redact(req.Header),
+			Body:    string(reqBody),
+		},
+		Response: Response{
+			StatusCode: res.StatusCode,
+			Headers:    r.redact(res.Header),
+			Body:       string(resBody),
+		},
+	})
+	r.played = append(r.played, true)
+
+	return res, nil
+}
+
+// Save writes the recorded interactions to the cassette file. It does
+// nothing when replaying.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+// Unplayed returns the interactions in the cassette that have not been
+// replayed, so that tests can check that every recorded request was made.
+func (r *Recorder) Unplayed() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unplayed := []*Interaction{}
+	for i, interaction := range r.cassette.Interactions {
+		if !r.played[i] {
+			unplayed = append(unplayed, interaction)
+		}
+	}
+
+	return unplayed
+}
+
+// redact returns a copy of header with the values of redacted headers
+// replaced.
+func (r *Recorder) redact(header http.Header) http.Header {
+	redacted := cloneHeader(header)
+	for name := range redacted {
+		if r.redacted[name] {
+			redacted[name] = []string{RedactedValue}
+		}
+	}
+
+	return redacted
+}
+
+// matches reports whether req has the method, path and query of a recorded
+// request. Query parameters may be in any order.
+func matches(recorded Request, req *http.Request) bool {
+	if recorded.Method != req.Method {
+		return false
+	}
+
+	recordedURL, err := req.URL.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+
+	return recordedURL.Path == req.URL.Path &&
+		recordedURL.Query().Encode() == req.URL.Query().Encode()
+}
+
+func cloneHeader(header http.Header) http.Header {
+	clone := make(http.Header, len(header))
+	for name, values := range header {
+		clone[name] = append([]string{}, values...)
+	}
+
+	return clone
+}
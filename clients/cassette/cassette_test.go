@@ -0,0 +1,173 @@
+package cassette_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/clients/cassette"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCassette(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cassette Suite")
+}
+
+var _ = Describe("ModeFromEnv", func() {
+	AfterEach(func() {
+		os.Unsetenv("CASSETTE_MODE")
+	})
+
+	It("replays by default", func() {
+		Expect(cassette.ModeFromEnv()).To(Equal(cassette.ModeReplay))
+	})
+
+	It("records when asked to", func() {
+		os.Setenv("CASSETTE_MODE", "record")
+		Expect(cassette.ModeFromEnv()).To(Equal(cassette.ModeRecord))
+	})
+
+	It("rejects unknown modes", func() {
+		os.Setenv("CASSETTE_MODE", "rewind")
+		_, err := cassette.ModeFromEnv()
+		Expect(err).To(MatchError(`"rewind": unknown cassette mode`))
+	})
+})
+
+var _ = Describe("Recorder", func() {
+	var (
+		dir      string
+		path     string
+		server   *httptest.Server
+		requests int
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "cassette")
+		Expect(err).To(BeNil())
+		path = filepath.Join(dir, "cassette.json")
+
+		requests = 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			body, _ := ioutil.ReadAll(r.Body)
+			w.Header().Set("Set-Cookie", "session=secret")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(r.Method + " " + r.URL.RequestURI() + " " + string(body)))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	// record makes requests through a recording Recorder and saves the
+	// cassette.
+	record := func(requests ...*http.Request) {
+		recorder, err := cassette.New(path, cassette.ModeRecord)
+		Expect(err).To(BeNil())
+		recorder.RedactHeaders("authorization", "Set-Cookie")
+
+		for _, req := range requests {
+			res, err := recorder.Client().Do(req)
+			Expect(err).To(BeNil())
+			res.Body.Close()
+		}
+		Expect(recorder.Save()).To(Succeed())
+	}
+
+	newRequest := func(method, url, body string) *http.Request {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		Expect(err).To(BeNil())
+		return req
+	}
+
+	readBody := func(res *http.Response) string {
+		defer res.Body.Close()
+		body, err := ioutil.ReadAll(res.Body)
+		Expect(err).To(BeNil())
+		return string(body)
+	}
+
+	It("replays recorded responses without making requests", func() {
+		record(
+			newRequest(http.MethodGet, server.URL+"/v1/restaurants?id=1,2", ""),
+			newRequest(http.MethodPost, server.URL+"/v1/restaurants/1/order-cancellations", `{"order_id":3}`),
+		)
+		Expect(requests).To(Equal(2))
+
+		recorder, err := cassette.New(path, cassette.ModeReplay)
+		Expect(err).To(BeNil())
+		client := recorder.Client()
+
+		res, err := client.Post("http://elsewhere.test/v1/restaurants/1/order-cancellations", "application/json", strings.NewReader(`{}`))
+		Expect(err).To(BeNil())
+		Expect(res.StatusCode).To(Equal(http.StatusCreated))
+		Expect(readBody(res)).To(Equal(`POST /v1/restaurants/1/order-cancellations {"order_id":3}`))
+
+		res, err = client.Get("http://elsewhere.test/v1/restaurants?id=1,2")
+		Expect(err).To(BeNil())
+		Expect(readBody(res)).To(Equal("GET /v1/restaurants?id=1,2 "))
+
+		Expect(requests).To(Equal(2))
+		Expect(recorder.Unplayed()).To(BeEmpty())
+	})
+
+	It("matches query parameters in any order", func() {
+		record(newRequest(http.MethodGet, server.URL+"/search?a=1&b=2", ""))
+
+		recorder, err := cassette.New(path, cassette.ModeReplay)
+		Expect(err).To(BeNil())
+
+		res, err := recorder.Client().Get("http://elsewhere.test/search?b=2&a=1")
+		Expect(err).To(BeNil())
+		Expect(readBody(res)).To(Equal("GET /search?a=1&b=2 "))
+	})
+
+	It("fails on requests that match no unplayed interaction", func() {
+		record(newRequest(http.MethodGet, server.URL+"/v1/restaurants?id=1", ""))
+
+		recorder, err := cassette.New(path, cassette.ModeReplay)
+		Expect(err).To(BeNil())
+		client := recorder.Client()
+
+		_, err = client.Get("http://elsewhere.test/v1/restaurants?id=2")
+		Expect(err).To(MatchError(ContainSubstring("cassette " + path + ": no unplayed interaction matches GET /v1/restaurants?id=2")))
+		Expect(recorder.Unplayed()).To(HaveLen(1))
+
+		_, err = client.Get("http://elsewhere.test/v1/restaurants?id=1")
+		Expect(err).To(BeNil())
+		_, err = client.Get("http://elsewhere.test/v1/restaurants?id=1")
+		Expect(err).To(MatchError(ContainSubstring("no unplayed interaction matches GET /v1/restaurants?id=1")))
+	})
+
+	It("redacts headers in the cassette", func() {
+		req := newRequest(http.MethodGet, server.URL+"/private", "")
+		req.Header.Set("Authorization", "Bearer secret")
+		record(req)
+
+		data, err := ioutil.ReadFile(path)
+		Expect(err).To(BeNil())
+		Expect(string(data)).NotTo(ContainSubstring("secret"))
+		Expect(string(data)).To(ContainSubstring(cassette.RedactedValue))
+
+		recorder, err := cassette.New(path, cassette.ModeReplay)
+		Expect(err).To(BeNil())
+		res, err := recorder.Client().Get("http://elsewhere.test/private")
+		Expect(err).To(BeNil())
+		Expect(res.Header.Get("Set-Cookie")).To(Equal(cassette.RedactedValue))
+	})
+
+	It("requires the cassette to exist when replaying", func() {
+		_, err := cassette.New(path, cassette.ModeReplay)
+		Expect(err).To(MatchError(HavePrefix("reading cassette")))
+	})
+})
@@ -5,6 +5,7 @@
 package mock_restaurant
 
 import (
+	context "context"
 	models "github.com/SebastianCoetzee/blog-order-service-example/models"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
@@ -34,14 +35,26 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 }
 
 // GetRestaurantsByIDs mocks base method
-func (m *MockClient) GetRestaurantsByIDs(arg0 []int) (models.Restaurants, error) {
-	ret := m.ctrl.Call(m, "GetRestaurantsByIDs", arg0)
+func (m *MockClient) GetRestaurantsByIDs(arg0 context.Context, arg1 []int) (models.Restaurants, error) {
+	ret := m.ctrl.Call(m, "GetRestaurantsByIDs", arg0, arg1)
 	ret0, _ := ret[0].(models.Restaurants)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetRestaurantsByIDs indicates an expected call of GetRestaurantsByIDs
-func (mr *MockClientMockRecorder) GetRestaurantsByIDs(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRestaurantsByIDs", reflect.TypeOf((*MockClient)(nil).GetRestaurantsByIDs), arg0)
+func (mr *MockClientMockRecorder) GetRestaurantsByIDs(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRestaurantsByIDs", reflect.TypeOf((*MockClient)(nil).GetRestaurantsByIDs), arg0, arg1)
+}
+
+// NotifyOrderCancelled mocks base method
+func (m *MockClient) NotifyOrderCancelled(arg0 context.Context, arg1 *models.Order) error {
+	ret := m.ctrl.Call(m, "NotifyOrderCancelled", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyOrderCancelled indicates an expected call of NotifyOrderCancelled
+func (mr *MockClientMockRecorder) NotifyOrderCancelled(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyOrderCancelled", reflect.TypeOf((*MockClient)(nil).NotifyOrderCancelled), arg0, arg1)
 }
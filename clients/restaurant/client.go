@@ -1,6 +1,8 @@
 package restaurant
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -8,14 +10,17 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
 	"github.com/pkg/errors"
 )
 
 // Client is an interface that describes a RestaurantService client.
 type Client interface {
-	GetRestaurantsByIDs(ids []int) (models.Restaurants, error)
+	GetRestaurantsByIDs(ctx context.Context, ids []int) (models.Restaurants, error)
+	NotifyOrderCancelled(ctx context.Context, order *models.Order) error
 }
 
 // NewClient creates a new Restaurant client.
@@ -25,7 +30,8 @@ func NewClient() *client {
 
 // client is an implementation of a RestaurantService client interface.
 type client struct {
-	baseURL string
+	baseURL    string
+	httpClient *http.Client
 }
 
 // SetBaseURL overrides the default base URL for the restaurants service.
@@ -42,13 +48,37 @@ func (c *client) getBaseURL() string {
 	return c.baseURL
 }
 
+// SetHTTPClient overrides the HTTP client that requests are made with.
+func (c *client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+func (c *client) getHTTPClient() *http.Client {
+	if c.httpClient != nil {
+		return c.httpClient
+	}
+
+	c.httpClient = http.DefaultClient
+	return c.httpClient
+}
+
+// restaurantResponse is a restaurant as the RestaurantService represents it.
+// The ID is decoded here because models.Restaurant leaves it out of JSON.
+type restaurantResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
 // GetRestaurantsByIDs retrieves the Restaurants from the RestaurantService
 // using a slice of integer IDs.
-func (c *client) GetRestaurantsByIDs(ids []int) (models.Restaurants, error) {
+func (c *client) GetRestaurantsByIDs(ctx context.Context, ids []int) (models.Restaurants, error) {
 	if len(ids) == 0 {
 		return []*models.Restaurant{}, nil
 	}
 
+	ctx, span := tracing.StartSpan(ctx, "RestaurantService.GetRestaurantsByIDs")
+	defer span.End()
+
 	idStrings := make([]string, 0, len(ids))
 	for _, id := range ids {
 		idStrings = append(idStrings, strconv.Itoa(id))
@@ -60,24 +90,110 @@ func (c *client) GetRestaurantsByIDs(ids []int) (models.Restaurants, error) {
 		strings.Join(idStrings, ","),
 	)
 
-	res, err := http.Get(url)
+	span.SetAttribute("http.method", http.MethodGet)
+	span.SetAttribute("http.url", url)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
+		span.SetError(err)
 		return nil, err
 	}
+	req = req.WithContext(ctx)
+	tracing.Inject(ctx, req.Header)
 
+	res, err := c.getHTTPClient().Do(req)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	span.SetAttribute("http.status_code", res.StatusCode)
 	if res.StatusCode != 200 {
-		return nil, errors.New("error retrieving restaurants from RestaurantService")
+		err = errors.New("error retrieving restaurants from RestaurantService")
+		span.SetError(err)
+		return nil, err
 	}
 
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
+		span.SetError(err)
 		return nil, err
 	}
 
-	parsedBody := models.Restaurants{}
+	parsedBody := []restaurantResponse{}
 	if err = json.Unmarshal(body, &parsedBody); err != nil {
+		span.SetError(err)
 		return nil, err
 	}
 
-	return parsedBody, nil
+	restaurants := make(models.Restaurants, 0, len(parsedBody))
+	for _, r := range parsedBody {
+		restaurants = append(restaurants, &models.Restaurant{ID: r.ID, Name: r.Name})
+	}
+
+	return restaurants, nil
+}
+
+// orderCancellation is the payload sent to the RestaurantService when an order
+// is cancelled.
+type orderCancellation struct {
+	OrderID     int                       `json:"order_id"`
+	Reason      models.CancellationReason `json:"reason"`
+	CancelledAt time.Time                 `json:"cancelled_at"`
+}
+
+// NotifyOrderCancelled tells the RestaurantService that an order placed with
+// one of its restaurants has been cancelled, so that the restaurant stops
+// preparing it.
+func (c *client) NotifyOrderCancelled(ctx context.Context, order *models.Order) error {
+	ctx, span := tracing.StartSpan(ctx, "RestaurantService.NotifyOrderCancelled")
+	defer span.End()
+
+	payload := orderCancellation{
+		OrderID: order.ID,
+		Reason:  order.CancellationReason,
+	}
+	if order.CancelledAt != nil {
+		payload.CancelledAt = *order.CancelledAt
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+
+	url := fmt.Sprintf(
+		"%s/v1/restaurants/%d/order-cancellations",
+		c.getBaseURL(),
+		order.RestaurantID,
+	)
+	span.SetAttribute("http.method", http.MethodPost)
+	span.SetAttribute("http.url", url)
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	tracing.Inject(ctx, req.Header)
+
+	res, err := c.getHTTPClient().Do(req)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	defer res.Body.Close()
+
+	span.SetAttribute("http.status_code", res.StatusCode)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		err = errors.Errorf("error notifying RestaurantService of cancelled order %d", order.ID)
+		span.SetError(err)
+		return err
+	}
+
+	return nil
 }
@@ -0,0 +1,148 @@
+package restaurant_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/clients/cassette"
+	"github.com/SebastianCoetzee/blog-order-service-example/clients/restaurant"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestRestaurantClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Restaurant Client Suite")
+}
+
+// The specs replay the cassettes in testdata. The cassettes were recorded
+// against the fake RestaurantService that the fake-restaurants command
+// serves, not against the real service, so they check the client against
+// the fake's idea of the API; get_restaurants_error was recorded with
+// -error-rate 1. To record them again, against the real RestaurantService
+// once there is one to reach, run the specs with CASSETTE_MODE=record and
+// RESTAURANT_SERVICE_BASE_URL pointing at it.
+var _ = Describe("Client", func() {
+	var (
+		ctx      = context.Background()
+		mode     cassette.Mode
+		recorder *cassette.Recorder
+	)
+
+	// newClient returns a client whose requests are recorded into or
+	// replayed from the named cassette.
+	newClient := func(name string) restaurant.Client {
+		var err error
+		recorder, err = cassette.New(filepath.Join("testdata", name+".json"), mode)
+		Expect(err).To(BeNil())
+		// The traceparent is different on every request.
+		recorder.RedactHeaders("Authorization", tracing.TraceparentHeader)
+
+		c := restaurant.NewClient()
+		c.SetHTTPClient(recorder.Client())
+		if mode == cassette.ModeReplay {
+			c.SetBaseURL("http://restaurant-service.test")
+		}
+		return c
+	}
+
+	BeforeEach(func() {
+		var err error
+		mode, err = cassette.ModeFromEnv()
+		Expect(err).To(BeNil())
+		recorder = nil
+	})
+
+	AfterEach(func() {
+		if recorder == nil {
+			return
+		}
+		Expect(recorder.Save()).To(Succeed())
+		Expect(recorder.Unplayed()).To(BeEmpty())
+	})
+
+	Describe("GetRestaurantsByIDs", func() {
+		It("returns the restaurants with their IDs", func() {
+			restaurants, err := newClient("get_restaurants").GetRestaurantsByIDs(ctx, []int{2, 1})
+			Expect(err).To(BeNil())
+			Expect(restaurants).To(Equal(models.Restaurants{
+				{ID: 2, Name: "Sushi Spot"},
+				{ID: 1, Name: "Pizza Palace"},
+			}))
+		})
+
+		It("makes no request without IDs", func() {
+			restaurants, err := newClient("no_requests").GetRestaurantsByIDs(ctx, []int{})
+			Expect(err).To(BeNil())
+			Expect(restaurants).To(BeEmpty())
+		})
+
+		It("fails when the RestaurantService does", func() {
+			_, err := newClient("get_restaurants_error").GetRestaurantsByIDs(ctx, []int{1})
+			Expect(err).To(MatchError("error retrieving restaurants from RestaurantService"))
+		})
+
+		It("never reaches the RestaurantService for requests that were not recorded", func() {
+			if mode == cassette.ModeRecord {
+				Skip("only applies to replays")
+			}
+
+			client := newClient("get_restaurants")
+			_, err := client.GetRestaurantsByIDs(ctx, []int{3})
+			Expect(err).To(MatchError(ContainSubstring("no unplayed interaction matches GET /v1/restaurants?id=3")))
+
+			_, err = client.GetRestaurantsByIDs(ctx, []int{2, 1})
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Describe("NotifyOrderCancelled", func() {
+		cancelledAt := time.Date(2019, 5, 16, 12, 0, 0, 0, time.UTC)
+
+		It("notifies the restaurant", func() {
+			err := newClient("notify_order_cancelled").NotifyOrderCancelled(ctx, &models.Order{
+				ID:                 3,
+				RestaurantID:       2,
+				CancelledAt:        &cancelledAt,
+				CancellationReason: models.CancellationReasonChangedMind,
+			})
+			Expect(err).To(BeNil())
+		})
+
+		It("fails for unknown restaurants", func() {
+			err := newClient("notify_unknown_restaurant").NotifyOrderCancelled(ctx, &models.Order{
+				ID:                 3,
+				RestaurantID:       42,
+				CancelledAt:        &cancelledAt,
+				CancellationReason: models.CancellationReasonChangedMind,
+			})
+			Expect(err).To(MatchError("error notifying RestaurantService of cancelled order 3"))
+		})
+	})
+})
+
+var _ = Describe("GetRestaurantsByIDs", func() {
+	It("decodes the IDs of the restaurants", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Query().Get("id")).To(Equal("8,9"))
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"id":8,"name":"Pizza Palace"},{"id":9,"name":"Sushi Spot"}]`))
+		}))
+		defer server.Close()
+
+		c := restaurant.NewClient()
+		c.SetBaseURL(server.URL)
+		restaurants, err := c.GetRestaurantsByIDs(context.Background(), []int{8, 9})
+		Expect(err).To(BeNil())
+		Expect(restaurants).To(Equal(models.Restaurants{
+			{ID: 8, Name: "Pizza Palace"},
+			{ID: 9, Name: "Sushi Spot"},
+		}))
+	})
+})
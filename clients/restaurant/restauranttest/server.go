@@ -0,0 +1,227 @@
+// Package restauranttest provides a fake RestaurantService for local
+// development and integration tests. It serves restaurants from a fixture and
+// can inject latency, errors and missing restaurants.
+package restauranttest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Restaurant is a restaurant served by the fake, in the representation of the
+// RestaurantService API.
+type Restaurant struct {
+	ID   int    `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+}
+
+// Faults are the failures that the fake injects into its responses.
+type Faults struct {
+	// Latency delays every response.
+	Latency time.Duration
+	// ErrorRate is the fraction of requests, between 0 and 1, that fail with
+	// a 500.
+	ErrorRate float64
+	// MissingIDs are restaurants that are left out of responses as if they
+	// did not exist, even though they are in the fixture.
+	MissingIDs []int
+}
+
+// Cancellation is an order cancellation that the fake was notified of.
+type Cancellation struct {
+	RestaurantID int       `json:"-"`
+	OrderID      int       `json:"order_id"`
+	Reason       string    `json:"reason"`
+	CancelledAt  time.Time `json:"cancelled_at"`
+}
+
+// LoadFixture reads restaurants from a JSON or YAML file, which holds a list
+// of objects with an id and a name. The format is chosen by the file's
+// extension.
+func LoadFixture(path string) ([]Restaurant, error) {
+	var unmarshal func([]byte, interface{}) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		unmarshal = json.Unmarshal
+	case ".yaml", ".yml":
+		unmarshal = yaml.Unmarshal
+	default:
+		return nil, errors.Errorf("%s: fixtures must be .json, .yaml or .yml files", path)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	restaurants := []Restaurant{}
+	if err := unmarshal(data, &restaurants); err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", path)
+	}
+
+	return restaurants, nil
+}
+
+// NewHandler returns an http.Handler that acts as the RestaurantService for
+// the given restaurants. It serves GET /v1/restaurants?id=1,2,3 and accepts
+// POST /v1/restaurants/:id/order-cancellations.
+func NewHandler(restaurants []Restaurant, faults Faults) *Handler {
+	h := &Handler{
+		restaurants: make(map[int]Restaurant, len(restaurants)),
+		random:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, r := range restaurants {
+		h.restaurants[r.ID] = r
+	}
+	h.SetFaults(faults)
+
+	return h
+}
+
+// Handler is the http.Handler of the fake RestaurantService. It is safe for
+// concurrent use.
+type Handler struct {
+	mu            sync.Mutex
+	restaurants   map[int]Restaurant
+	faults        Faults
+	missing       map[int]bool
+	random        *rand.Rand
+	cancellations []Cancellation
+}
+
+// SetFaults replaces the faults that are injected into later responses.
+func (h *Handler) SetFaults(faults Faults) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.faults = faults
+	h.missing = make(map[int]bool, len(faults.MissingIDs))
+	for _, id := range faults.MissingIDs {
+		h.missing[id] = true
+	}
+}
+
+// Cancellations returns the order cancellations the fake has been notified
+// of, oldest first.
+func (h *Handler) Cancellations() []Cancellation {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]Cancellation{}, h.cancellations...)
+}
+
+var cancellationPath = regexp.MustCompile(`^/v1/restaurants/(\d+)/order-cancellations$`)
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	latency := h.faults.Latency
+	fail := h.faults.ErrorRate > 0 && h.random.Float64() < h.faults.ErrorRate
+	h.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if fail {
+		http.Error(w, "injected failure", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Path == "/v1/restaurants" && r.Method == http.MethodGet {
+		h.findRestaurants(w, r)
+		return
+	}
+
+	if match := cancellationPath.FindStringSubmatch(r.URL.Path); match != nil && r.Method == http.MethodPost {
+		restaurantID, _ := strconv.Atoi(match[1])
+		h.cancelOrder(w, r, restaurantID)
+		return
+	}
+
+	http.NotFound(w, r)
+}
+
+// findRestaurants responds with the restaurants whose IDs are listed in the id
+// query parameter, separated by commas. Unknown and missing IDs are left out.
+func (h *Handler) findRestaurants(w http.ResponseWriter, r *http.Request) {
+	found := []Restaurant{}
+	h.mu.Lock()
+	for _, param := range strings.Split(r.URL.Query().Get("id"), ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(param))
+		if err != nil {
+			h.mu.Unlock()
+			http.Error(w, "invalid restaurant ID "+strconv.Quote(param), http.StatusBadRequest)
+			return
+		}
+
+		if restaurant, ok := h.restaurants[id]; ok && !h.missing[id] {
+			found = append(found, restaurant)
+		}
+	}
+	h.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(found)
+}
+
+// cancelOrder records an order cancellation. Cancellations of orders at
+// unknown or missing restaurants are rejected with a 404.
+func (h *Handler) cancelOrder(w http.ResponseWriter, r *http.Request, restaurantID int) {
+	cancellation := Cancellation{}
+	if err := json.NewDecoder(r.Body).Decode(&cancellation); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cancellation.RestaurantID = restaurantID
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.restaurants[restaurantID]; !ok || h.missing[restaurantID] {
+		http.NotFound(w, r)
+		return
+	}
+
+	h.cancellations = append(h.cancellations, cancellation)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Server is a fake RestaurantService listening on a local port, for tests.
+type Server struct {
+	*Handler
+	server *httptest.Server
+}
+
+// NewServer starts a fake RestaurantService that serves the given
+// restaurants. Point a restaurant client at its URL and Close it when done.
+func NewServer(restaurants []Restaurant, faults Faults) *Server {
+	h := NewHandler(restaurants, faults)
+	return &Server{Handler: h, server: httptest.NewServer(h)}
+}
+
+// URL is the base URL of the server, for RESTAURANT_SERVICE_BASE_URL or a
+// client's SetBaseURL.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.server.Close()
+}
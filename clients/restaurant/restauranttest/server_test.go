@@ -0,0 +1,110 @@
+package restauranttest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/clients/restaurant"
+	"github.com/SebastianCoetzee/blog-order-service-example/clients/restaurant/restauranttest"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestRestaurantTest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fake RestaurantService Suite")
+}
+
+var _ = Describe("LoadFixture", func() {
+	It("reads the same restaurants from JSON and YAML", func() {
+		fromJSON, err := restauranttest.LoadFixture("testdata/restaurants.json")
+		Expect(err).To(BeNil())
+		fromYAML, err := restauranttest.LoadFixture("testdata/restaurants.yaml")
+		Expect(err).To(BeNil())
+
+		Expect(fromJSON).To(HaveLen(3))
+		Expect(fromJSON[0]).To(Equal(restauranttest.Restaurant{ID: 8, Name: "Pizza Palace"}))
+		Expect(fromYAML).To(Equal(fromJSON))
+	})
+
+	It("rejects other file types", func() {
+		_, err := restauranttest.LoadFixture("testdata/restaurants.txt")
+		Expect(err).To(MatchError("testdata/restaurants.txt: fixtures must be .json, .yaml or .yml files"))
+	})
+})
+
+var _ = Describe("Server", func() {
+	var (
+		server *restauranttest.Server
+		client restaurant.Client
+		ctx    = context.Background()
+	)
+
+	BeforeEach(func() {
+		restaurants, err := restauranttest.LoadFixture("testdata/restaurants.yaml")
+		Expect(err).To(BeNil())
+		server = restauranttest.NewServer(restaurants, restauranttest.Faults{})
+
+		c := restaurant.NewClient()
+		c.SetBaseURL(server.URL())
+		client = c
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("serves the requested restaurants with their IDs", func() {
+		restaurants, err := client.GetRestaurantsByIDs(ctx, []int{9, 8, 42})
+		Expect(err).To(BeNil())
+		Expect(restaurants).To(Equal(models.Restaurants{
+			{ID: 9, Name: "Sushi Spot"},
+			{ID: 8, Name: "Pizza Palace"},
+		}))
+	})
+
+	It("leaves out missing restaurants", func() {
+		server.SetFaults(restauranttest.Faults{MissingIDs: []int{8}})
+
+		restaurants, err := client.GetRestaurantsByIDs(ctx, []int{8, 9})
+		Expect(err).To(BeNil())
+		Expect(restaurants).To(Equal(models.Restaurants{{ID: 9, Name: "Sushi Spot"}}))
+	})
+
+	It("fails requests at the configured error rate", func() {
+		server.SetFaults(restauranttest.Faults{ErrorRate: 1})
+
+		_, err := client.GetRestaurantsByIDs(ctx, []int{8})
+		Expect(err).To(MatchError("error retrieving restaurants from RestaurantService"))
+	})
+
+	It("delays responses by the configured latency", func() {
+		server.SetFaults(restauranttest.Faults{Latency: 50 * time.Millisecond})
+
+		start := time.Now()
+		_, err := client.GetRestaurantsByIDs(ctx, []int{8})
+		Expect(err).To(BeNil())
+		Expect(time.Since(start)).To(BeNumerically(">=", 50*time.Millisecond))
+	})
+
+	It("records order cancellations", func() {
+		cancelledAt := time.Date(2019, 5, 16, 12, 0, 0, 0, time.UTC)
+		Expect(client.NotifyOrderCancelled(ctx, &models.Order{
+			ID:                 3,
+			RestaurantID:       10,
+			CancelledAt:        &cancelledAt,
+			CancellationReason: models.CancellationReasonChangedMind,
+		})).To(Succeed())
+
+		Expect(server.Cancellations()).To(Equal([]restauranttest.Cancellation{
+			{RestaurantID: 10, OrderID: 3, Reason: "changed_mind", CancelledAt: cancelledAt},
+		}))
+	})
+
+	It("rejects cancellations at unknown restaurants", func() {
+		err := client.NotifyOrderCancelled(ctx, &models.Order{ID: 3, RestaurantID: 42})
+		Expect(err).To(MatchError("error notifying RestaurantService of cancelled order 3"))
+	})
+})
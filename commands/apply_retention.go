@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	"github.com/pkg/errors"
+)
+
+const applyRetentionUsage = "usage: apply-retention [-dry-run]"
+
+// ApplyRetention anonymises or deletes the orders that are older than the
+// retention period configured with ORDER_RETENTION_YEARS and
+// ORDER_RETENTION_ACTION. With -dry-run it only reports how many orders would
+// be affected. It is meant to be run periodically.
+func ApplyRetention(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("apply-retention", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	dryRun := flags.Bool("dry-run", false, "report the orders that would be affected without changing them")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 {
+		return errors.New(applyRetentionUsage)
+	}
+
+	report, err := services.NewRetentionService().ApplyRetention(ctx, *dryRun)
+	if report != nil {
+		fmt.Println(FormatRetentionReport(report))
+	}
+	return err
+}
+
+// FormatRetentionReport describes the result of a run of the retention job in
+// a sentence.
+func FormatRetentionReport(report *models.RetentionReport) string {
+	verb := "anonymised"
+	if report.Action == models.RetentionActionDelete {
+		verb = "deleted"
+	}
+
+	cutoff := report.Cutoff.UTC().Format("2006-01-02 15:04:05 MST")
+	if report.DryRun {
+		return fmt.Sprintf("dry run: %d orders placed before %s would be %s", report.Orders, cutoff, verb)
+	}
+
+	return fmt.Sprintf("%s %d orders placed before %s in %d batches", verb, report.Orders, cutoff, report.Batches)
+}
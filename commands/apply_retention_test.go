@@ -0,0 +1,39 @@
+package commands_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/commands"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ApplyRetention", func() {
+	It("rejects unknown arguments", func() {
+		Expect(commands.ApplyRetention(context.Background(), []string{"-force"})).To(MatchError("usage: apply-retention [-dry-run]"))
+	})
+})
+
+var _ = Describe("FormatRetentionReport", func() {
+	cutoff := time.Date(2012, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	It("describes a dry run", func() {
+		Expect(commands.FormatRetentionReport(&models.RetentionReport{
+			Action: models.RetentionActionDelete,
+			Cutoff: cutoff,
+			DryRun: true,
+			Orders: 12,
+		})).To(Equal("dry run: 12 orders placed before 2012-05-10 12:00:00 UTC would be deleted"))
+	})
+
+	It("describes a run", func() {
+		Expect(commands.FormatRetentionReport(&models.RetentionReport{
+			Action:  models.RetentionActionAnonymise,
+			Cutoff:  cutoff,
+			Orders:  12,
+			Batches: 3,
+		})).To(Equal("anonymised 12 orders placed before 2012-05-10 12:00:00 UTC in 3 batches"))
+	})
+})
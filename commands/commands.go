@@ -0,0 +1,20 @@
+// Package commands holds the administrative subcommands of the order service
+// binary, for example "load-rates".
+package commands
+
+import (
+	"context"
+)
+
+// Command is a subcommand that is run with the arguments that follow its name.
+type Command func(ctx context.Context, args []string) error
+
+// Commands maps subcommand names to the commands that they run.
+var Commands = map[string]Command{
+	"apply-retention":        ApplyRetention,
+	"export-user-data":       ExportUserData,
+	"fake-restaurants":       FakeRestaurants,
+	"load-rates":             LoadRates,
+	"purge-idempotency-keys": PurgeIdempotencyKeys,
+	"seed":                   Seed,
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	"github.com/pkg/errors"
+)
+
+const exportUserDataUsage = "usage: export-user-data <user-id> <file.zip>"
+
+// ExportUserData writes everything stored about a user to a ZIP archive, to
+// answer a data subject access request.
+func ExportUserData(ctx context.Context, args []string) error {
+	if len(args) != 2 {
+		return errors.New(exportUserDataUsage)
+	}
+
+	userID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return errors.New(exportUserDataUsage)
+	}
+
+	export, err := services.NewUserDataService().ExportUserData(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(args[1])
+	if err != nil {
+		return err
+	}
+
+	if err = services.WriteUserDataArchive(f, export); err != nil {
+		f.Close()
+		return errors.Wrap(err, args[1])
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote %d orders and %d webhook deliveries of user %d to %s\n", len(export.Orders), len(export.WebhookDeliveries), userID, args[1])
+	return nil
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/clients/restaurant/restauranttest"
+	"github.com/SebastianCoetzee/blog-order-service-example/testing/factories"
+	"github.com/pkg/errors"
+)
+
+const fakeRestaurantsUsage = "usage: fake-restaurants [-addr :4001] [-latency 0s] [-error-rate 0] [-missing-ids 1,2] [fixture.json|fixture.yaml]"
+
+// FakeRestaurants serves a fake RestaurantService from a JSON or YAML fixture
+// of restaurants, for running the order service locally with
+// RESTAURANT_SERVICE_BASE_URL pointing at it. Without a fixture it serves the
+// restaurants that the seed command places orders at. Latency, failures and missing
+// restaurants can be injected with flags. It runs until ctx is cancelled.
+func FakeRestaurants(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("fake-restaurants", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	addr := flags.String("addr", ":4001", "the address to listen on")
+	latency := flags.Duration("latency", 0, "how long to delay every response")
+	errorRate := flags.Float64("error-rate", 0, "the fraction of requests that fail with a 500")
+	missingIDs := flags.String("missing-ids", "", "comma separated IDs of restaurants to leave out of responses")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 1 {
+		return errors.New(fakeRestaurantsUsage)
+	}
+
+	faults := restauranttest.Faults{Latency: *latency, ErrorRate: *errorRate}
+	if faults.ErrorRate < 0 || faults.ErrorRate > 1 {
+		return errors.New("-error-rate must be between 0 and 1")
+	}
+	if *missingIDs != "" {
+		for _, s := range strings.Split(*missingIDs, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				return errors.Errorf("-missing-ids: %q is not a restaurant ID", s)
+			}
+			faults.MissingIDs = append(faults.MissingIDs, id)
+		}
+	}
+
+	restaurants := factories.Restaurants()
+	if flags.NArg() == 1 {
+		var err error
+		if restaurants, err = restauranttest.LoadFixture(flags.Arg(0)); err != nil {
+			return err
+		}
+	}
+
+	server := &http.Server{Addr: *addr, Handler: restauranttest.NewHandler(restaurants, faults)}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	fmt.Printf("serving %d restaurants on %s\n", len(restaurants), *addr)
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package commands_test
+
+import (
+	"context"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/commands"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FakeRestaurants", func() {
+	It("takes at most one fixture", func() {
+		err := commands.FakeRestaurants(context.Background(), []string{"a.json", "b.json"})
+		Expect(err).To(MatchError(HavePrefix("usage: fake-restaurants")))
+	})
+
+	It("rejects error rates that are not fractions", func() {
+		err := commands.FakeRestaurants(context.Background(), []string{"-error-rate", "5", "restaurants.json"})
+		Expect(err).To(MatchError("-error-rate must be between 0 and 1"))
+	})
+
+	It("rejects missing IDs that are not numbers", func() {
+		err := commands.FakeRestaurants(context.Background(), []string{"-missing-ids", "8,x", "restaurants.json"})
+		Expect(err).To(MatchError(`-missing-ids: "x" is not a restaurant ID`))
+	})
+})
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	"github.com/pkg/errors"
+)
+
+// rateColumns are the columns that a CSV file of exchange rates must have, in
+// this order.
+var rateColumns = []string{"base_currency", "quote_currency", "rate", "effective_at"}
+
+// rateRecord is one exchange rate in a JSON file. The rate may be written as
+// a number or a string.
+type rateRecord struct {
+	BaseCurrency  string      `json:"base_currency"`
+	QuoteCurrency string      `json:"quote_currency"`
+	Rate          json.Number `json:"rate"`
+	EffectiveAt   time.Time   `json:"effective_at"`
+}
+
+// LoadRates loads the exchange rates in a .csv or .json file into the
+// exchange_rates table. Rates that already exist for the same pair and
+// effective time are replaced.
+func LoadRates(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: load-rates <file.csv|file.json>")
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rates, err := ParseRates(f, filepath.Ext(args[0]))
+	if err != nil {
+		return errors.Wrap(err, args[0])
+	}
+
+	return services.NewExchangeRateService().LoadRates(ctx, rates)
+}
+
+// ParseRates reads exchange rates in the given format, which is either ".csv"
+// or ".json".
+func ParseRates(r io.Reader, format string) (models.ExchangeRates, error) {
+	switch strings.ToLower(format) {
+	case ".csv":
+		return parseCSVRates(r)
+	case ".json":
+		return parseJSONRates(r)
+	default:
+		return nil, errors.Errorf("unsupported rates format %q", format)
+	}
+}
+
+func parseCSVRates(r io.Reader) (models.ExchangeRates, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(rateColumns)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "reading header")
+	}
+	for i, column := range rateColumns {
+		if strings.TrimSpace(header[i]) != column {
+			return nil, errors.Errorf("expected columns %s", strings.Join(rateColumns, ","))
+		}
+	}
+
+	rates := models.ExchangeRates{}
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return rates, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		effectiveAt, err := time.Parse(time.RFC3339, row[3])
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", line)
+		}
+
+		rate, err := newExchangeRate(row[0], row[1], row[2], effectiveAt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", line)
+		}
+		rates = append(rates, rate)
+	}
+}
+
+func parseJSONRates(r io.Reader) (models.ExchangeRates, error) {
+	records := []rateRecord{}
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, err
+	}
+
+	rates := make(models.ExchangeRates, 0, len(records))
+	for i, record := range records {
+		rate, err := newExchangeRate(record.BaseCurrency, record.QuoteCurrency, record.Rate.String(), record.EffectiveAt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "rate %d", i+1)
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+func newExchangeRate(base, quote, rate string, effectiveAt time.Time) (*models.ExchangeRate, error) {
+	baseCurrency, err := models.ParseCurrency(base)
+	if err != nil {
+		return nil, err
+	}
+
+	quoteCurrency, err := models.ParseCurrency(quote)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &models.ExchangeRate{
+		BaseCurrency:  baseCurrency,
+		QuoteCurrency: quoteCurrency,
+		Rate:          strings.TrimSpace(rate),
+		EffectiveAt:   effectiveAt,
+	}
+	if err = r.Validate(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
@@ -0,0 +1,65 @@
+package commands_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/commands"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCommands(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Commands Suite")
+}
+
+var _ = Describe("ParseRates", func() {
+	var (
+		effectiveAt = time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)
+		expected    = models.ExchangeRates{
+			{BaseCurrency: models.GBP, QuoteCurrency: models.EUR, Rate: "1.1625", EffectiveAt: effectiveAt},
+		}
+	)
+
+	It("reads CSV files with a header row", func() {
+		rates, err := commands.ParseRates(strings.NewReader(
+			"base_currency,quote_currency,rate,effective_at\ngbp,EUR,1.1625,2019-04-01T00:00:00Z\n",
+		), ".csv")
+		Expect(err).To(BeNil())
+		Expect(rates).To(Equal(expected))
+	})
+
+	It("reads JSON arrays with string or numeric rates", func() {
+		rates, err := commands.ParseRates(strings.NewReader(
+			`[{"base_currency": "GBP", "quote_currency": "EUR", "rate": 1.1625, "effective_at": "2019-04-01T00:00:00Z"}]`,
+		), ".JSON")
+		Expect(err).To(BeNil())
+		Expect(rates).To(Equal(expected))
+
+		rates, err = commands.ParseRates(strings.NewReader(
+			`[{"base_currency": "GBP", "quote_currency": "EUR", "rate": "1.1625", "effective_at": "2019-04-01T00:00:00Z"}]`,
+		), ".json")
+		Expect(err).To(BeNil())
+		Expect(rates).To(Equal(expected))
+	})
+
+	It("reports the line of an invalid rate", func() {
+		_, err := commands.ParseRates(strings.NewReader(
+			"base_currency,quote_currency,rate,effective_at\nGBP,EUR,1.16,2019-04-01T00:00:00Z\nGBP,XYZ,1.16,2019-04-01T00:00:00Z\n",
+		), ".csv")
+		Expect(err).To(MatchError(`line 3: "XYZ": unknown currency`))
+	})
+
+	It("rejects files with unexpected columns", func() {
+		_, err := commands.ParseRates(strings.NewReader("from,to,rate,at\n"), ".csv")
+		Expect(err).To(MatchError("expected columns base_currency,quote_currency,rate,effective_at"))
+	})
+
+	It("rejects other formats", func() {
+		_, err := commands.ParseRates(strings.NewReader(""), ".xml")
+		Expect(err).To(MatchError(`unsupported rates format ".xml"`))
+	})
+})
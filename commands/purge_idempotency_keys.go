@@ -0,0 +1,25 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	"github.com/pkg/errors"
+)
+
+// PurgeIdempotencyKeys deletes the idempotency keys whose responses can no
+// longer be replayed. It is meant to be run periodically.
+func PurgeIdempotencyKeys(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: purge-idempotency-keys")
+	}
+
+	n, err := services.NewIdempotencyService().PurgeExpired(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("deleted %d expired idempotency keys\n", n)
+	return nil
+}
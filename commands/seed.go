@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/testing/factories"
+	"github.com/go-pg/pg"
+	"github.com/pkg/errors"
+)
+
+const seedUsage = "usage: seed [-users 10] [-orders 5]"
+
+// Seed fills a development database with orders placed by users 1 to -users,
+// each with -orders orders at the restaurants that fake-restaurants serves
+// by default. The orders are inserted in a single transaction, together with
+// an OrderPlaced event for each of them, so that the relay publishes them.
+func Seed(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	users := flags.Int("users", 10, "the number of users to place orders for")
+	orders := flags.Int("orders", 5, "the number of orders to place for each user")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 || *users < 1 || *orders < 1 {
+		return errors.New(seedUsage)
+	}
+
+	builders := SeedOrders(*users, *orders, time.Now().UTC())
+	err := application.ResolveDB().RunInTransaction(func(tx *pg.Tx) error {
+		orders, err := factories.InsertOrders(tx, builders...)
+		if err != nil {
+			return err
+		}
+
+		events, err := SeedEvents(orders)
+		if err != nil {
+			return err
+		}
+
+		return repositories.NewOutboxRepository(tx).AddEvents(ctx, events)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("seeded %d orders for %d users\n", len(builders), *users)
+	return nil
+}
+
+// SeedEvents returns an OrderPlaced event for each of orders, which should
+// have been inserted.
+func SeedEvents(orders models.Orders) (models.OutboxEvents, error) {
+	events := make(models.OutboxEvents, 0, len(orders))
+	for _, order := range orders {
+		event, err := models.NewOrderPlacedEvent(order)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// seedMenu holds the dishes that seeded orders are made up of.
+var seedMenu = []struct {
+	name      string
+	unitPrice int64
+}{
+	{"Margherita", 950},
+	{"Salmon nigiri", 600},
+	{"Cheeseburger", 850},
+	{"Chicken tikka masala", 1150},
+	{"Carnitas taco", 400},
+	{"Garlic bread", 450},
+}
+
+// SeedOrders returns builders for ordersPerUser orders by each of users 1 to
+// users, placed a day apart up to now. Every order has items and a status
+// history, and they cycle through being placed, delivered, delivered and
+// partly refunded, and cancelled.
+func SeedOrders(users, ordersPerUser int, now time.Time) []*factories.OrderBuilder {
+	restaurants := factories.Restaurants()
+	builders := make([]*factories.OrderBuilder, 0, users*ordersPerUser)
+	for userID := 1; userID <= users; userID++ {
+		for i := 0; i < ordersPerUser; i++ {
+			restaurantID := restaurants[(userID+i)%len(restaurants)].ID
+			placedAt := now.Add(-time.Duration(i*24+userID) * time.Hour).Truncate(time.Minute)
+
+			b := factories.Order().
+				WithUserID(userID).
+				WithRestaurantID(restaurantID).
+				WithPlacedAt(placedAt).
+				WithStatusChange(models.OrderStatusPlaced, placedAt)
+
+			var total int64
+			for n := 0; n <= i%2; n++ {
+				dish := seedMenu[(restaurantID-1+n*5)%len(seedMenu)]
+				quantity := 1 + (userID+n)%2
+				b.WithItem(dish.name, quantity, dish.unitPrice)
+				total += int64(quantity) * dish.unitPrice
+			}
+			b.WithTotal(total, models.GBP)
+
+			switch i % 4 {
+			case 1, 2:
+				b.WithStatus(models.OrderStatusDelivered).
+					WithStatusChange(models.OrderStatusAccepted, placedAt.Add(2*time.Minute)).
+					WithStatusChange(models.OrderStatusDelivered, placedAt.Add(40*time.Minute))
+				if i%4 == 2 {
+					b.WithRefund(total/2, "Part of the order was missing", "agent:1", placedAt.Add(2*time.Hour))
+				}
+			case 3:
+				cancelledAt := placedAt.Add(3 * time.Minute)
+				b.Cancelled(models.UserActorID(userID), models.CancellationReasonChangedMind, cancelledAt).
+					WithStatusChange(models.OrderStatusCancelled, cancelledAt)
+			}
+
+			builders = append(builders, b)
+		}
+	}
+
+	return builders
+}
@@ -0,0 +1,74 @@
+package commands_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/commands"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/testing/factories"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Seed", func() {
+	It("rejects invalid counts", func() {
+		err := commands.Seed(context.Background(), []string{"-users", "0"})
+		Expect(err).To(MatchError("usage: seed [-users 10] [-orders 5]"))
+	})
+})
+
+var _ = Describe("SeedOrders", func() {
+	now := time.Date(2019, 5, 16, 12, 0, 0, 0, time.UTC)
+
+	It("places orders for every user at the default restaurants", func() {
+		builders := commands.SeedOrders(3, 4, now)
+		Expect(builders).To(HaveLen(12))
+
+		ordersByUser := make(map[int]int)
+		statuses := make(map[models.OrderStatus]int)
+		for _, b := range builders {
+			order := b.Build()
+			ordersByUser[order.UserID]++
+			statuses[order.Status]++
+
+			Expect(factories.Restaurant(order.RestaurantID)).NotTo(BeNil())
+			Expect(order.Total.IsPositive()).To(BeTrue())
+			Expect(order.PlacedAt.Before(now)).To(BeTrue())
+		}
+
+		Expect(ordersByUser).To(Equal(map[int]int{1: 4, 2: 4, 3: 4}))
+		Expect(statuses).To(Equal(map[models.OrderStatus]int{
+			models.OrderStatusPlaced:    3,
+			models.OrderStatusDelivered: 6,
+			models.OrderStatusCancelled: 3,
+		}))
+	})
+
+	It("records who cancelled cancelled orders", func() {
+		order := commands.SeedOrders(1, 4, now)[3].Build()
+
+		Expect(order.Status).To(Equal(models.OrderStatusCancelled))
+		Expect(order.CancelledBy).To(Equal("user:1"))
+		Expect(order.CancelledAt.After(order.PlacedAt)).To(BeTrue())
+	})
+})
+
+var _ = Describe("SeedEvents", func() {
+	It("returns an OrderPlaced event for each order, due when it was placed", func() {
+		placedAt := time.Date(2019, 5, 16, 12, 0, 0, 0, time.UTC)
+		orders := models.Orders{
+			factories.Order().WithID(3).WithUserID(5).WithPlacedAt(placedAt).Build(),
+			factories.Order().WithID(4).WithUserID(6).WithPlacedAt(placedAt).Build(),
+		}
+
+		events, err := commands.SeedEvents(orders)
+		Expect(err).To(BeNil())
+		Expect(len(events)).To(Equal(2))
+		Expect(events[0].Type).To(Equal(models.EventTypeOrderPlaced))
+		Expect(events[0].OrderID).To(Equal(3))
+		Expect(events[0].UserID).To(Equal(5))
+		Expect(events[0].NextAttemptAt).To(Equal(placedAt))
+		Expect(events[1].OrderID).To(Equal(4))
+	})
+})
@@ -0,0 +1,152 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/clients/restaurant/restauranttest"
+	"github.com/SebastianCoetzee/blog-order-service-example/events"
+	"github.com/SebastianCoetzee/blog-order-service-example/handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/testing/factories"
+	"github.com/gin-gonic/gin"
+	"github.com/go-pg/pg"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the responses received")
+
+func TestEndToEnd(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "End-to-end Suite")
+}
+
+// harness serves requests through the router of the service with its real
+// services and repositories. Every request runs inside a database
+// transaction that is rolled back after each spec, and the RestaurantService
+// is a fake.
+type harness struct {
+	tx          *pg.Tx
+	router      *gin.Engine
+	restaurants *restauranttest.Server
+	baseURL     string
+}
+
+func (h *harness) Setup() {
+	var err error
+	h.tx, err = application.ResolveDB().Begin()
+	Expect(err).To(BeNil())
+	_, err = h.tx.Exec("SET LOCAL TIME ZONE 'UTC'")
+	Expect(err).To(BeNil())
+
+	h.restaurants = factories.NewRestaurantServer(restauranttest.Faults{})
+	h.baseURL = os.Getenv("RESTAURANT_SERVICE_BASE_URL")
+	os.Setenv("RESTAURANT_SERVICE_BASE_URL", h.restaurants.URL())
+
+	gin.SetMode(gin.TestMode)
+	h.router = gin.New()
+	handlers.Routes(h.router)
+}
+
+func (h *harness) Teardown() {
+	os.Setenv("RESTAURANT_SERVICE_BASE_URL", h.baseURL)
+	h.restaurants.Close()
+	Expect(h.tx.Rollback()).To(Succeed())
+}
+
+// Insert stores orders directly in the test transaction.
+func (h *harness) Insert(orders ...*factories.OrderBuilder) {
+	_, err := factories.InsertOrders(h.tx, orders...)
+	ExpectWithOffset(1, err).To(BeNil())
+}
+
+// Do serves a request inside the test transaction. headers alternate
+// between names and values.
+func (h *harness) Do(method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req = req.WithContext(repositories.ContextWithTx(req.Context(), h.tx))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	res := httptest.NewRecorder()
+	h.router.ServeHTTP(res, req)
+	return res
+}
+
+// RelayEvents publishes the outbox events that are due to publisher inside
+// the test transaction, the way the relay started by main does.
+func (h *harness) RelayEvents(publisher events.Publisher) {
+	_, err := events.NewRelay(publisher).RelayBatch(repositories.ContextWithTx(context.Background(), h.tx))
+	ExpectWithOffset(1, err).To(BeNil())
+}
+
+// volatileValue replaces the values of fields that differ between runs,
+// such as the current time, in responses compared with golden files.
+const volatileValue = "<volatile>"
+
+// expectGolden compares a JSON response body with testdata/golden/name.json.
+// The values of the fields named in volatile are replaced before comparing.
+// A field is named either on its own, such as "placed_at", or after the
+// field that holds it, such as "status_history.changed_at". Run the suite with -update to
+// rewrite the golden files from the responses received.
+func expectGolden(name string, res *httptest.ResponseRecorder, volatile ...string) {
+	var body interface{}
+	ExpectWithOffset(1, json.Unmarshal(res.Body.Bytes(), &body)).To(Succeed(), res.Body.String())
+
+	fields := make(map[string]bool, len(volatile))
+	for _, field := range volatile {
+		fields[field] = true
+	}
+	actual, err := json.MarshalIndent(maskFields(body, "", fields), "", "  ")
+	ExpectWithOffset(1, err).To(BeNil())
+
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *update {
+		ExpectWithOffset(1, ioutil.WriteFile(path, append(actual, '\n'), 0644)).To(Succeed())
+		return
+	}
+
+	golden, err := ioutil.ReadFile(path)
+	ExpectWithOffset(1, err).To(BeNil())
+	ExpectWithOffset(1, actual).To(MatchJSON(golden))
+}
+
+// maskFields replaces the non-null values of fields in decoded JSON. parent
+// is the name of the field that holds value.
+func maskFields(value interface{}, parent string, fields map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if field != nil && (fields[key] || fields[parent+"."+key]) {
+				v[key] = volatileValue
+				continue
+			}
+			v[key] = maskFields(field, key, fields)
+		}
+	case []interface{}:
+		for i, element := range v {
+			v[i] = maskFields(element, parent, fields)
+		}
+	}
+
+	return value
+}
+
+// expectStatus checks the status of a response, showing the body when it
+// does not match.
+func expectStatus(res *httptest.ResponseRecorder, status int) {
+	ExpectWithOffset(1, res.Code).To(Equal(status), res.Body.String())
+}
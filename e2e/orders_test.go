@@ -0,0 +1,150 @@
+package e2e_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/clients/restaurant/restauranttest"
+	"github.com/SebastianCoetzee/blog-order-service-example/events"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/testing/factories"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Orders", func() {
+	var (
+		h        *harness
+		placedAt = factories.Epoch
+	)
+
+	BeforeEach(func() {
+		h = &harness{}
+		h.Setup()
+
+		h.Insert(
+			factories.Order().
+				WithID(910001).
+				WithUserID(910).
+				WithStatus(models.OrderStatusDelivered).
+				WithItem("Margherita", 2, 1000).
+				WithItem("Garlic bread", 1, 500).
+				WithStatusChange(models.OrderStatusPlaced, placedAt).
+				WithStatusChange(models.OrderStatusDelivered, placedAt.Add(45*time.Minute)).
+				WithRefund(500, "Cold garlic bread", "agent:7", placedAt.Add(2*time.Hour)).
+				WithRefundID(910101),
+			factories.Order().
+				WithID(910002).
+				WithUserID(910).
+				WithRestaurantID(2).
+				WithTotal(1800, models.EUR).
+				WithPlacedAt(placedAt.Add(30*time.Hour)),
+			factories.Order().
+				WithID(910003).
+				WithUserID(911).
+				WithTotal(1200, models.GBP),
+			factories.Order().
+				WithID(910004).
+				WithUserID(910).
+				WithRestaurantID(3).
+				WithStatus(models.OrderStatusDelivered).
+				WithVersion(2).
+				Deleted(placedAt.Add(24*time.Hour)),
+		)
+	})
+
+	AfterEach(func() {
+		h.Teardown()
+	})
+
+	Describe("GET /users/:id/orders", func() {
+		It("lists the user's orders with their restaurants, items, history and refunds", func() {
+			res := h.Do(http.MethodGet, "/users/910/orders", "")
+
+			expectStatus(res, http.StatusOK)
+			expectGolden("user_orders", res)
+		})
+
+		It("fails when the RestaurantService does", func() {
+			h.restaurants.SetFaults(restauranttest.Faults{ErrorRate: 1})
+
+			res := h.Do(http.MethodGet, "/users/910/orders", "")
+
+			expectStatus(res, http.StatusInternalServerError)
+		})
+	})
+
+	Describe("GET /orders/:id", func() {
+		It("returns the order to its user", func() {
+			res := h.Do(http.MethodGet, "/orders/910001", "", "X-Actor-ID", "user:910")
+
+			expectStatus(res, http.StatusOK)
+			Expect(res.Header().Get("ETag")).To(Equal(`"v1"`))
+			expectGolden("order", res)
+		})
+
+		It("hides the orders of other users", func() {
+			res := h.Do(http.MethodGet, "/orders/910003", "", "X-Actor-ID", "user:910")
+
+			expectStatus(res, http.StatusNotFound)
+			expectGolden("order_not_found", res)
+		})
+
+		It("hides deleted orders", func() {
+			res := h.Do(http.MethodGet, "/orders/910004", "", "X-Actor-ID", "user:910")
+
+			expectStatus(res, http.StatusNotFound)
+		})
+	})
+
+	Describe("POST /orders/:id/cancel", func() {
+		BeforeEach(func() {
+			h.Insert(factories.Order().
+				WithID(910005).
+				WithUserID(910).
+				WithRestaurantID(2).
+				WithTotal(1800, models.GBP).
+				WithPlacedAt(time.Now()))
+		})
+
+		It("cancels the order and notifies its restaurant", func() {
+			res := h.Do(http.MethodPost, "/orders/910005/cancel", `{"reason":"changed_mind"}`,
+				"X-Actor-ID", "user:910",
+				"If-Match", `"v1"`,
+			)
+
+			expectStatus(res, http.StatusOK)
+			Expect(res.Header().Get("ETag")).To(Equal(`"v2"`))
+			expectGolden("cancelled_order", res, "placed_at", "cancelled_at", "status_history.changed_at")
+			Expect(h.restaurants.Cancellations()).To(BeEmpty())
+
+			h.RelayEvents(events.NewRestaurantNotifier())
+			cancellations := h.restaurants.Cancellations()
+			Expect(cancellations).To(HaveLen(1))
+			Expect(cancellations[0].RestaurantID).To(Equal(2))
+			Expect(cancellations[0].OrderID).To(Equal(910005))
+			Expect(cancellations[0].Reason).To(Equal("changed_mind"))
+		})
+
+		It("refuses to cancel an order that has changed", func() {
+			res := h.Do(http.MethodPost, "/orders/910005/cancel", `{"reason":"changed_mind"}`,
+				"X-Actor-ID", "user:910",
+				"If-Match", `"v3"`,
+			)
+
+			expectStatus(res, http.StatusPreconditionFailed)
+			expectGolden("order_version_mismatch", res)
+			Expect(h.restaurants.Cancellations()).To(BeEmpty())
+		})
+
+		It("refuses to cancel a delivered order", func() {
+			res := h.Do(http.MethodPost, "/orders/910001/cancel", `{"reason":"changed_mind"}`,
+				"X-Actor-ID", "user:910",
+				"If-Match", "*",
+			)
+
+			expectStatus(res, http.StatusConflict)
+			expectGolden("order_not_cancellable", res)
+		})
+	})
+})
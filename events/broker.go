@@ -0,0 +1,126 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+)
+
+// subscriptionBuffer is how many events a subscriber may fall behind by
+// before it is dropped.
+const subscriptionBuffer = 64
+
+var (
+	defaultBroker     *Broker
+	defaultBrokerOnce sync.Once
+)
+
+// DefaultBroker returns the broker that order event notifications are passed
+// to and that order streams subscribe to.
+func DefaultBroker() *Broker {
+	defaultBrokerOnce.Do(func() {
+		defaultBroker = NewBroker()
+	})
+
+	return defaultBroker
+}
+
+// NewBroker returns a broker without subscribers.
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[int]map[*Subscription]struct{}),
+	}
+}
+
+// Broker is a Publisher that passes events on to the subscribers of the user
+// who placed each order, within this process. Events reach the brokers of
+// every instance through Postgres notifications.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[int]map[*Subscription]struct{}
+}
+
+// Subscription receives the events about one user's orders.
+type Subscription struct {
+	broker *Broker
+	userID int
+	events chan *models.OutboxEvent
+	once   sync.Once
+}
+
+// Subscribe returns a subscription to the events about a user's orders. The
+// subscription must be closed once it is no longer read from.
+func (b *Broker) Subscribe(userID int) *Subscription {
+	s := &Subscription{
+		broker: b,
+		userID: userID,
+		events: make(chan *models.OutboxEvent, subscriptionBuffer),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[*Subscription]struct{})
+	}
+	b.subscribers[userID][s] = struct{}{}
+	return s
+}
+
+// Publish passes event on to the subscribers of the order's user without
+// waiting for them. A subscriber whose buffer is full is dropped, and its
+// channel closed, rather than holding up the publisher; it can resume from the
+// last event it received.
+func (b *Broker) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subscribers[event.UserID] {
+		select {
+		case s.events <- event:
+		default:
+			b.remove(s)
+		}
+	}
+
+	return nil
+}
+
+// remove drops s. b.mu must be held.
+func (b *Broker) remove(s *Subscription) {
+	if _, ok := b.subscribers[s.userID][s]; !ok {
+		return
+	}
+
+	delete(b.subscribers[s.userID], s)
+	if len(b.subscribers[s.userID]) == 0 {
+		delete(b.subscribers, s.userID)
+	}
+	close(s.events)
+}
+
+// Subscribers returns how many subscriptions there are to the events about a
+// user's orders.
+func (b *Broker) Subscribers(userID int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subscribers[userID])
+}
+
+// Events returns the channel that events are received on. It is closed when
+// the subscription is closed or dropped.
+func (s *Subscription) Events() <-chan *models.OutboxEvent {
+	return s.events
+}
+
+// Close stops the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.broker.mu.Lock()
+		defer s.broker.mu.Unlock()
+
+		s.broker.remove(s)
+	})
+}
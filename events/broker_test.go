@@ -0,0 +1,55 @@
+package events_test
+
+import (
+	"context"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/events"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Broker", func() {
+	var broker *events.Broker
+
+	BeforeEach(func() {
+		broker = events.NewBroker()
+	})
+
+	It("passes events on to the subscribers of the order's user", func() {
+		mine := broker.Subscribe(5)
+		theirs := broker.Subscribe(6)
+		defer mine.Close()
+		defer theirs.Close()
+
+		Expect(broker.Publish(context.Background(), &models.OutboxEvent{ID: 1, UserID: 5})).To(Succeed())
+
+		Expect((<-mine.Events()).ID).To(Equal(int64(1)))
+		Expect(theirs.Events()).NotTo(Receive())
+	})
+
+	It("drops subscribers that fall behind instead of blocking", func() {
+		slow := broker.Subscribe(5)
+		defer slow.Close()
+
+		for i := 0; i < 100; i++ {
+			Expect(broker.Publish(context.Background(), &models.OutboxEvent{ID: int64(i), UserID: 5})).To(Succeed())
+		}
+
+		Expect(broker.Subscribers(5)).To(Equal(0))
+		received := 0
+		for range slow.Events() {
+			received++
+		}
+		Expect(received).To(BeNumerically("<", 100))
+	})
+
+	It("forgets closed subscriptions", func() {
+		s := broker.Subscribe(5)
+		s.Close()
+		s.Close()
+
+		Expect(broker.Subscribers(5)).To(Equal(0))
+		Expect(broker.Publish(context.Background(), &models.OutboxEvent{ID: 1, UserID: 5})).To(Succeed())
+	})
+})
@@ -0,0 +1,232 @@
+package events
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	"github.com/pkg/errors"
+)
+
+// NewWebhookDispatcher returns a dispatcher for the webhook delivery log.
+func NewWebhookDispatcher() *webhookDispatcher {
+	return &webhookDispatcher{}
+}
+
+// webhookDispatcher sends the pending entries of the webhook delivery log.
+// Each entry is posted to its subscription's URL, signed with the
+// subscription's secret, and never to an address on this service's own
+// network. Failed entries are retried with exponential backoff
+// until they have been attempted maxAttempts times, after which they are
+// marked failed and can only be replayed.
+type webhookDispatcher struct {
+	transactor        repositories.Transactor
+	webhookRepository repositories.WebhookRepository
+	client            *http.Client
+	batchSize         int
+	pollInterval      time.Duration
+	maxAttempts       int
+	now               func() time.Time
+}
+
+func (d *webhookDispatcher) SetTransactor(t repositories.Transactor) {
+	d.transactor = t
+}
+
+func (d *webhookDispatcher) getTransactor() repositories.Transactor {
+	if d.transactor != nil {
+		return d.transactor
+	}
+
+	d.transactor = repositories.NewTransactor(application.ResolveDB())
+	return d.transactor
+}
+
+func (d *webhookDispatcher) SetWebhookRepository(r repositories.WebhookRepository) {
+	d.webhookRepository = r
+}
+
+func (d *webhookDispatcher) getWebhookRepository() repositories.WebhookRepository {
+	if d.webhookRepository != nil {
+		return d.webhookRepository
+	}
+
+	d.webhookRepository = repositories.NewWebhookRepository(application.ResolveDB())
+	return d.webhookRepository
+}
+
+// SetHTTPClient overrides the client used to make requests.
+func (d *webhookDispatcher) SetHTTPClient(c *http.Client) {
+	d.client = c
+}
+
+func (d *webhookDispatcher) getHTTPClient() *http.Client {
+	if d.client != nil {
+		return d.client
+	}
+
+	d.client = newSubscriberClient()
+	return d.client
+}
+
+// SetBatchSize overrides how many deliveries are claimed at a time.
+func (d *webhookDispatcher) SetBatchSize(n int) {
+	d.batchSize = n
+}
+
+func (d *webhookDispatcher) getBatchSize() int {
+	if d.batchSize > 0 {
+		return d.batchSize
+	}
+
+	return defaultBatchSize
+}
+
+// SetPollInterval overrides how long Run waits before looking for new
+// deliveries once the log has been drained.
+func (d *webhookDispatcher) SetPollInterval(interval time.Duration) {
+	d.pollInterval = interval
+}
+
+func (d *webhookDispatcher) getPollInterval() time.Duration {
+	if d.pollInterval > 0 {
+		return d.pollInterval
+	}
+
+	return defaultPollInterval
+}
+
+// SetMaxAttempts overrides how many times a delivery is attempted before it
+// is marked failed.
+func (d *webhookDispatcher) SetMaxAttempts(n int) {
+	d.maxAttempts = n
+}
+
+func (d *webhookDispatcher) getMaxAttempts() int {
+	if d.maxAttempts > 0 {
+		return d.maxAttempts
+	}
+
+	return defaultMaxAttempts
+}
+
+// SetClock overrides the function used to tell the current time.
+func (d *webhookDispatcher) SetClock(now func() time.Time) {
+	d.now = now
+}
+
+func (d *webhookDispatcher) getClock() func() time.Time {
+	if d.now != nil {
+		return d.now
+	}
+
+	return time.Now
+}
+
+// Run sends deliveries until ctx is done.
+func (d *webhookDispatcher) Run(ctx context.Context) {
+	poll(ctx, "dispatching webhooks", d.DispatchBatch, d.getBatchSize(), d.getPollInterval())
+}
+
+// DispatchBatch sends the deliveries that are due and returns how many were
+// attempted. The deliveries are claimed in one short transaction, sent outside
+// of any transaction, and then saved one at a time, so that slow subscribers
+// do not hold a transaction open. Claimed deliveries are leased for long
+// enough to send the whole batch, so that other dispatchers do not send them
+// at the same time.
+func (d *webhookDispatcher) DispatchBatch(ctx context.Context) (int, error) {
+	ctx, span := tracing.StartSpan(ctx, "WebhookDispatcher.DispatchBatch")
+	defer span.End()
+
+	var deliveries models.WebhookDeliveries
+	byID := map[int]*models.WebhookSubscription{}
+	err := d.getTransactor().RunInTransaction(ctx, func(ctx context.Context) error {
+		now := d.getClock()()
+		claimed, err := d.getWebhookRepository().ClaimDueDeliveries(ctx, d.getBatchSize(), now, now.Add(d.lease()))
+		if err != nil || len(claimed) == 0 {
+			deliveries = claimed
+			return err
+		}
+
+		ids := make([]int, 0, len(claimed))
+		for _, delivery := range claimed {
+			ids = append(ids, delivery.SubscriptionID)
+		}
+		subscriptions, err := d.getWebhookRepository().FindSubscriptionsByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, subscription := range subscriptions {
+			byID[subscription.ID] = subscription
+		}
+
+		deliveries = claimed
+		return nil
+	})
+	if err != nil {
+		span.SetError(err)
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		d.send(ctx, byID[delivery.SubscriptionID], delivery)
+
+		err := d.getTransactor().RunInTransaction(ctx, func(ctx context.Context) error {
+			return d.getWebhookRepository().UpdateDelivery(ctx, delivery)
+		})
+		if err != nil {
+			span.SetError(err)
+			return 0, err
+		}
+	}
+
+	span.SetAttribute("deliveries.count", len(deliveries))
+	return len(deliveries), nil
+}
+
+// lease returns how long claimed deliveries are left to this dispatcher. Each
+// delivery in a batch may take up to webhookTimeout to send, so the lease
+// covers a whole batch of them with claimLease to spare.
+func (d *webhookDispatcher) lease() time.Duration {
+	return time.Duration(d.getBatchSize())*webhookTimeout + claimLease
+}
+
+// send attempts delivery and records the outcome on it.
+func (d *webhookDispatcher) send(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) {
+	ctx, span := tracing.StartSpan(ctx, "WebhookDispatcher.send")
+	defer span.End()
+
+	span.SetAttribute("delivery.id", delivery.ID)
+	span.SetAttribute("subscription.id", delivery.SubscriptionID)
+
+	// Deleting a subscription deletes its deliveries, but it may have been
+	// deleted since the delivery was claimed, and there is nowhere to send it
+	// without one.
+	if subscription == nil {
+		err := errors.Errorf("webhook subscription %d not found", delivery.SubscriptionID)
+		span.SetError(err)
+		delivery.MarkFailed(0, err, d.getClock()(), 1, 0)
+		return
+	}
+
+	header := http.Header{}
+	header.Set(HeaderEventID, strconv.FormatInt(delivery.EventID, 10))
+	header.Set(HeaderEventType, string(delivery.EventType))
+	header.Set(HeaderDeliveryID, strconv.FormatInt(delivery.ID, 10))
+
+	now := d.getClock()()
+	status, err := postWebhook(ctx, d.getHTTPClient(), subscription.URL, subscription.Secret, header, delivery.Body, now)
+	span.SetAttribute("http.status_code", status)
+	if err != nil {
+		span.SetError(err)
+		delivery.MarkFailed(status, err, now, d.getMaxAttempts(), backoff(delivery.Attempts+1))
+		return
+	}
+
+	delivery.MarkSucceeded(status, now)
+}
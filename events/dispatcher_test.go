@@ -0,0 +1,189 @@
+package events_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/events"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WebhookDispatcher", func() {
+	var (
+		ctrl        *gomock.Controller
+		webhookRepo *mock_repositories.MockWebhookRepository
+		transactor  *mock_repositories.MockTransactor
+		inTx        bool
+		sentInTx    []bool
+		server      *httptest.Server
+		client      *http.Client
+		status      int
+		requests    []*http.Request
+		bodies      [][]byte
+		delivery    *models.WebhookDelivery
+		n           int
+		err         error
+
+		now = time.Date(2019, 5, 6, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		webhookRepo = mock_repositories.NewMockWebhookRepository(ctrl)
+
+		inTx = false
+		sentInTx = nil
+		transactor = mock_repositories.NewMockTransactor(ctrl)
+		transactor.EXPECT().
+			RunInTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				inTx = true
+				defer func() { inTx = false }()
+				return fn(ctx)
+			}).
+			AnyTimes()
+
+		status = http.StatusOK
+		requests = nil
+		bodies = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			sentInTx = append(sentInTx, inTx)
+			requests = append(requests, r)
+			bodies = append(bodies, body)
+			w.WriteHeader(status)
+		}))
+		client = server.Client()
+
+		delivery = &models.WebhookDelivery{
+			ID:             11,
+			SubscriptionID: 2,
+			EventID:        7,
+			EventType:      models.EventTypeOrderRefunded,
+			Body:           json.RawMessage(`{"id":7,"type":"OrderRefunded"}`),
+			Status:         models.WebhookDeliveryStatusPending,
+			Attempts:       1,
+		}
+		webhookRepo.EXPECT().ClaimDueDeliveries(gomock.Any(), gomock.Eq(10), gomock.Eq(now), gomock.Eq(now.Add(10*10*time.Second+5*time.Minute))).Return(models.WebhookDeliveries{delivery}, error(nil))
+		webhookRepo.EXPECT().FindSubscriptionsByIDs(gomock.Any(), gomock.Eq([]int{2})).Return(models.WebhookSubscriptions{
+			{ID: 2, RestaurantID: 9, URL: server.URL + "/hooks", Secret: "0123456789abcdef"},
+		}, error(nil))
+	})
+
+	JustBeforeEach(func() {
+		dispatcher := events.NewWebhookDispatcher()
+		dispatcher.SetTransactor(transactor)
+		dispatcher.SetWebhookRepository(webhookRepo)
+		if client != nil {
+			dispatcher.SetHTTPClient(client)
+		}
+		dispatcher.SetBatchSize(10)
+		dispatcher.SetMaxAttempts(3)
+		dispatcher.SetClock(func() time.Time { return now })
+
+		n, err = dispatcher.DispatchBatch(context.Background())
+	})
+
+	Describe("DispatchBatch", func() {
+		Describe("when the subscriber accepts the delivery", func() {
+			BeforeEach(func() {
+				webhookRepo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Eq(delivery)).Return(nil)
+			})
+
+			It("posts the body with a signature over the timestamp and body", func() {
+				Expect(err).To(BeNil())
+				Expect(n).To(Equal(1))
+				Expect(len(requests)).To(Equal(1))
+				Expect(requests[0].URL.Path).To(Equal("/hooks"))
+				Expect(requests[0].Header.Get(events.HeaderDeliveryID)).To(Equal("11"))
+				Expect(requests[0].Header.Get(events.HeaderEventType)).To(Equal("OrderRefunded"))
+				Expect(bodies[0]).To(MatchJSON(`{"id":7,"type":"OrderRefunded"}`))
+
+				timestamp := requests[0].Header.Get(events.HeaderTimestamp)
+				Expect(timestamp).To(Equal("1557144000"))
+				mac := hmac.New(sha256.New, []byte("0123456789abcdef"))
+				mac.Write([]byte(timestamp + "."))
+				mac.Write(bodies[0])
+				Expect(requests[0].Header.Get(events.HeaderSignature)).To(Equal("sha256=" + hex.EncodeToString(mac.Sum(nil))))
+			})
+
+			It("posts outside of the transaction that claimed the delivery", func() {
+				Expect(sentInTx).To(Equal([]bool{false}))
+			})
+
+			It("logs the successful attempt", func() {
+				Expect(delivery.Status).To(Equal(models.WebhookDeliveryStatusSucceeded))
+				Expect(delivery.Attempts).To(Equal(2))
+				Expect(delivery.ResponseStatus).To(Equal(http.StatusOK))
+				Expect(*delivery.DeliveredAt).To(Equal(now))
+			})
+		})
+
+		Describe("when the subscriber fails", func() {
+			BeforeEach(func() {
+				status = http.StatusBadGateway
+				webhookRepo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Eq(delivery)).Return(nil)
+			})
+
+			It("logs the failure and schedules a retry with exponential backoff", func() {
+				Expect(err).To(BeNil())
+				Expect(delivery.Status).To(Equal(models.WebhookDeliveryStatusPending))
+				Expect(delivery.ResponseStatus).To(Equal(http.StatusBadGateway))
+				Expect(delivery.LastError).To(Equal("webhook responded with status 502"))
+				Expect(delivery.NextAttemptAt).To(Equal(now.Add(2 * time.Second)))
+			})
+
+			Describe("for the last time", func() {
+				BeforeEach(func() {
+					delivery.Attempts = 2
+				})
+
+				It("marks the delivery failed", func() {
+					Expect(delivery.Status).To(Equal(models.WebhookDeliveryStatusFailed))
+				})
+			})
+		})
+
+		Describe("when the subscription's URL is on this service's own network", func() {
+			BeforeEach(func() {
+				client = nil
+				webhookRepo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Eq(delivery)).Return(nil)
+			})
+
+			It("does not connect to it", func() {
+				Expect(err).To(BeNil())
+				Expect(requests).To(BeEmpty())
+				Expect(delivery.Status).To(Equal(models.WebhookDeliveryStatusPending))
+				Expect(delivery.LastError).To(ContainSubstring(events.ErrInternalWebhookAddress.Error()))
+			})
+		})
+
+		Describe("when the log cannot be updated", func() {
+			BeforeEach(func() {
+				webhookRepo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).Return(errors.New("connection reset"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("connection reset"))
+			})
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+		ctrl.Finish()
+	})
+})
@@ -0,0 +1,61 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+)
+
+// NewInMemoryPublisher returns a publisher that keeps the events it is given.
+// It is meant for tests and local development.
+func NewInMemoryPublisher() *InMemoryPublisher {
+	return &InMemoryPublisher{}
+}
+
+// InMemoryPublisher is a Publisher that keeps events in memory.
+type InMemoryPublisher struct {
+	mu     sync.Mutex
+	events models.OutboxEvents
+	err    error
+}
+
+// Publish keeps a copy of event, or returns the error set with FailWith.
+func (p *InMemoryPublisher) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+
+	published := *event
+	p.events = append(p.events, &published)
+	return nil
+}
+
+// FailWith makes later calls to Publish return err, until it is called again
+// with nil.
+func (p *InMemoryPublisher) FailWith(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.err = err
+}
+
+// Events returns the events published so far, in the order they were
+// published.
+func (p *InMemoryPublisher) Events() models.OutboxEvents {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append(models.OutboxEvents{}, p.events...)
+}
+
+// Reset forgets the events published so far.
+func (p *InMemoryPublisher) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = nil
+}
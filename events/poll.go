@@ -0,0 +1,30 @@
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// poll calls batch until ctx is done. After a full batch it goes again
+// straight away, otherwise it waits for interval. Errors are logged under
+// name and the batch is retried after the interval.
+func poll(ctx context.Context, name string, batch func(ctx context.Context) (int, error), batchSize int, interval time.Duration) {
+	for {
+		n, err := batch(ctx)
+		if err != nil {
+			log.Printf("%s: %v", name, err)
+		}
+
+		wait := interval
+		if err == nil && n == batchSize {
+			wait = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Package events delivers the order domain events stored in the outbox to
+// the services that consume them.
+package events
+
+import (
+	"context"
+	"os"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/pkg/errors"
+)
+
+// Publisher delivers an event to its consumers. Events may be published more
+// than once, so consumers should de-duplicate them by ID.
+type Publisher interface {
+	Publish(ctx context.Context, event *models.OutboxEvent) error
+}
+
+// PublisherFromEnv returns the publisher selected by the EVENT_PUBLISHER
+// environment variable: "webhook" posts events to EVENT_WEBHOOK_URL and
+// "memory" keeps them in memory. It returns nil when no publisher is
+// configured.
+func PublisherFromEnv() (Publisher, error) {
+	switch name := os.Getenv("EVENT_PUBLISHER"); name {
+	case "":
+		return nil, nil
+	case "memory":
+		return NewInMemoryPublisher(), nil
+	case "webhook":
+		url := os.Getenv("EVENT_WEBHOOK_URL")
+		if url == "" {
+			return nil, errors.New("EVENT_WEBHOOK_URL must be set for the webhook publisher")
+		}
+		return NewWebhookPublisher(url, os.Getenv("EVENT_WEBHOOK_SECRET")), nil
+	default:
+		return nil, errors.Errorf("%q: unknown event publisher", name)
+	}
+}
@@ -0,0 +1,187 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+)
+
+// Relay defaults.
+const (
+	defaultBatchSize    = 50
+	defaultPollInterval = time.Second
+	defaultMaxAttempts  = 10
+	// maxBackoff caps the delay between attempts to publish an event.
+	maxBackoff = 10 * time.Minute
+	// claimLease is how long claimed events are left to the relay that
+	// claimed them before another relay may claim them again.
+	claimLease = 5 * time.Minute
+)
+
+// NewRelay returns a relay that delivers outbox events to publisher.
+func NewRelay(publisher Publisher) *relay {
+	return &relay{
+		publisher: publisher,
+	}
+}
+
+// relay moves events from the outbox to a Publisher. An event is only marked
+// delivered after the publisher has accepted it, so delivery is at least
+// once: an event is published again if the relay stops before marking it.
+// Events that fail are retried with exponential backoff until they have been
+// attempted maxAttempts times, after which they are marked dead.
+type relay struct {
+	transactor       repositories.Transactor
+	outboxRepository repositories.OutboxRepository
+	publisher        Publisher
+	batchSize        int
+	pollInterval     time.Duration
+	maxAttempts      int
+	now              func() time.Time
+}
+
+func (r *relay) SetTransactor(t repositories.Transactor) {
+	r.transactor = t
+}
+
+func (r *relay) getTransactor() repositories.Transactor {
+	if r.transactor != nil {
+		return r.transactor
+	}
+
+	r.transactor = repositories.NewTransactor(application.ResolveDB())
+	return r.transactor
+}
+
+func (r *relay) SetOutboxRepository(o repositories.OutboxRepository) {
+	r.outboxRepository = o
+}
+
+func (r *relay) getOutboxRepository() repositories.OutboxRepository {
+	if r.outboxRepository != nil {
+		return r.outboxRepository
+	}
+
+	r.outboxRepository = repositories.NewOutboxRepository(application.ResolveDB())
+	return r.outboxRepository
+}
+
+// SetBatchSize overrides how many events are claimed at a time.
+func (r *relay) SetBatchSize(n int) {
+	r.batchSize = n
+}
+
+func (r *relay) getBatchSize() int {
+	if r.batchSize > 0 {
+		return r.batchSize
+	}
+
+	return defaultBatchSize
+}
+
+// SetPollInterval overrides how long Run waits before looking for new events
+// once the outbox has been drained.
+func (r *relay) SetPollInterval(d time.Duration) {
+	r.pollInterval = d
+}
+
+func (r *relay) getPollInterval() time.Duration {
+	if r.pollInterval > 0 {
+		return r.pollInterval
+	}
+
+	return defaultPollInterval
+}
+
+// SetMaxAttempts overrides how many times an event is attempted before it is
+// marked dead.
+func (r *relay) SetMaxAttempts(n int) {
+	r.maxAttempts = n
+}
+
+func (r *relay) getMaxAttempts() int {
+	if r.maxAttempts > 0 {
+		return r.maxAttempts
+	}
+
+	return defaultMaxAttempts
+}
+
+// SetClock overrides the function used to tell the current time.
+func (r *relay) SetClock(now func() time.Time) {
+	r.now = now
+}
+
+func (r *relay) getClock() func() time.Time {
+	if r.now != nil {
+		return r.now
+	}
+
+	return time.Now
+}
+
+// Run relays events until ctx is done. Errors are logged and the batch is
+// retried after the poll interval.
+func (r *relay) Run(ctx context.Context) {
+	poll(ctx, "relaying outbox events", r.RelayBatch, r.getBatchSize(), r.getPollInterval())
+}
+
+// RelayBatch publishes the events that are due and returns how many were
+// attempted. The events are claimed in one short transaction, published
+// outside of any transaction, and then marked delivered or failed one at a
+// time, so that a slow publisher does not hold a transaction open. Claimed
+// events are leased for claimLease, so that other relays do not publish them
+// at the same time.
+func (r *relay) RelayBatch(ctx context.Context) (int, error) {
+	ctx, span := tracing.StartSpan(ctx, "Relay.RelayBatch")
+	defer span.End()
+
+	var events models.OutboxEvents
+	err := r.getTransactor().RunInTransaction(ctx, func(ctx context.Context) error {
+		now := r.getClock()()
+		claimed, err := r.getOutboxRepository().ClaimDueEvents(ctx, r.getBatchSize(), now, now.Add(claimLease))
+		events = claimed
+		return err
+	})
+	if err != nil {
+		span.SetError(err)
+		return 0, err
+	}
+
+	for _, event := range events {
+		if err := r.publisher.Publish(ctx, event); err != nil {
+			event.MarkFailed(err, r.getClock()(), r.getMaxAttempts(), backoff(event.Attempts+1))
+		} else {
+			event.MarkDelivered(r.getClock()())
+		}
+
+		err := r.getTransactor().RunInTransaction(ctx, func(ctx context.Context) error {
+			return r.getOutboxRepository().UpdateEvent(ctx, event)
+		})
+		if err != nil {
+			span.SetError(err)
+			return 0, err
+		}
+	}
+
+	span.SetAttribute("events.count", len(events))
+	return len(events), nil
+}
+
+// backoff returns the delay before the attempt that follows the given failed
+// attempt: one second after the first, doubling each time, up to maxBackoff.
+func backoff(attempt int) time.Duration {
+	d := time.Second
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	return d
+}
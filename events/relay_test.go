@@ -0,0 +1,148 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/events"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestEvents(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Events Suite")
+}
+
+func passThroughTransactor(ctrl *gomock.Controller) *mock_repositories.MockTransactor {
+	transactorMock := mock_repositories.NewMockTransactor(ctrl)
+	transactorMock.EXPECT().
+		RunInTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).
+		AnyTimes()
+	return transactorMock
+}
+
+type publisherFunc func(ctx context.Context, event *models.OutboxEvent) error
+
+func (f publisherFunc) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	return f(ctx, event)
+}
+
+var _ = Describe("Relay", func() {
+	var (
+		ctrl          *gomock.Controller
+		outboxRepo    *mock_repositories.MockOutboxRepository
+		publisher     *events.InMemoryPublisher
+		transactor    *mock_repositories.MockTransactor
+		inTx          bool
+		publishedInTx []bool
+		due           models.OutboxEvents
+		n             int
+		err           error
+
+		now = time.Date(2019, 5, 3, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		outboxRepo = mock_repositories.NewMockOutboxRepository(ctrl)
+		publisher = events.NewInMemoryPublisher()
+
+		inTx = false
+		publishedInTx = nil
+		transactor = mock_repositories.NewMockTransactor(ctrl)
+		transactor.EXPECT().
+			RunInTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				inTx = true
+				defer func() { inTx = false }()
+				return fn(ctx)
+			}).
+			AnyTimes()
+
+		due = models.OutboxEvents{
+			{ID: 1, Type: models.EventTypeOrderStatusChanged, OrderID: 3, Status: models.OutboxStatusPending},
+			{ID: 2, Type: models.EventTypeOrderRefunded, OrderID: 3, Status: models.OutboxStatusPending, Attempts: 2},
+		}
+		outboxRepo.EXPECT().ClaimDueEvents(gomock.Any(), gomock.Eq(10), gomock.Eq(now), gomock.Eq(now.Add(5*time.Minute))).Return(due, error(nil))
+	})
+
+	JustBeforeEach(func() {
+		relay := events.NewRelay(publisherFunc(func(ctx context.Context, event *models.OutboxEvent) error {
+			publishedInTx = append(publishedInTx, inTx)
+			return publisher.Publish(ctx, event)
+		}))
+		relay.SetTransactor(transactor)
+		relay.SetOutboxRepository(outboxRepo)
+		relay.SetBatchSize(10)
+		relay.SetMaxAttempts(3)
+		relay.SetClock(func() time.Time { return now })
+
+		n, err = relay.RelayBatch(context.Background())
+	})
+
+	Describe("RelayBatch", func() {
+		Describe("when the publisher accepts the events", func() {
+			BeforeEach(func() {
+				outboxRepo.EXPECT().UpdateEvent(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+			})
+
+			It("publishes them in order and marks them delivered", func() {
+				Expect(err).To(BeNil())
+				Expect(n).To(Equal(2))
+				Expect(len(publisher.Events())).To(Equal(2))
+				Expect(publisher.Events()[0].ID).To(Equal(int64(1)))
+				Expect(due[0].Status).To(Equal(models.OutboxStatusDelivered))
+				Expect(*due[1].DeliveredAt).To(Equal(now))
+			})
+
+			It("publishes them outside of a transaction", func() {
+				Expect(publishedInTx).To(Equal([]bool{false, false}))
+			})
+		})
+
+		Describe("when the publisher fails", func() {
+			BeforeEach(func() {
+				publisher.FailWith(errors.New("connection refused"))
+				outboxRepo.EXPECT().UpdateEvent(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+			})
+
+			It("schedules a retry with exponential backoff", func() {
+				Expect(err).To(BeNil())
+				Expect(due[0].Status).To(Equal(models.OutboxStatusPending))
+				Expect(due[0].Attempts).To(Equal(1))
+				Expect(due[0].LastError).To(Equal("connection refused"))
+				Expect(due[0].NextAttemptAt).To(Equal(now.Add(time.Second)))
+			})
+
+			It("marks events that have run out of attempts dead", func() {
+				Expect(due[1].Status).To(Equal(models.OutboxStatusDead))
+				Expect(due[1].Attempts).To(Equal(3))
+			})
+		})
+
+		Describe("when the delivery state cannot be saved", func() {
+			BeforeEach(func() {
+				outboxRepo.EXPECT().UpdateEvent(gomock.Any(), gomock.Any()).Return(errors.New("connection reset"))
+			})
+
+			It("returns the error so that the events are retried once their lease runs out", func() {
+				Expect(err).To(MatchError("connection reset"))
+				Expect(n).To(Equal(0))
+			})
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
@@ -0,0 +1,89 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/clients/restaurant"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+)
+
+// NewRestaurantNotifier returns a publisher that tells the RestaurantService
+// about cancelled orders.
+func NewRestaurantNotifier() *restaurantNotifier {
+	return &restaurantNotifier{}
+}
+
+// restaurantNotifier is a Publisher that notifies the restaurant of an order
+// when an OrderStatusChanged event cancels it, so that the restaurant stops
+// preparing it. Other events are ignored. Since cancellations reach the
+// restaurant through the outbox, they are only sent once the cancellation is
+// committed, and are retried until the RestaurantService accepts them.
+type restaurantNotifier struct {
+	orderRepository  repositories.OrderRepository
+	restaurantClient restaurant.Client
+}
+
+func (n *restaurantNotifier) SetOrderRepository(r repositories.OrderRepository) {
+	n.orderRepository = r
+}
+
+func (n *restaurantNotifier) getOrderRepository() repositories.OrderRepository {
+	if n.orderRepository != nil {
+		return n.orderRepository
+	}
+
+	n.orderRepository = repositories.NewOrderRepository(application.ResolveDB())
+	return n.orderRepository
+}
+
+func (n *restaurantNotifier) SetRestaurantClient(c restaurant.Client) {
+	n.restaurantClient = c
+}
+
+func (n *restaurantNotifier) getRestaurantClient() restaurant.Client {
+	if n.restaurantClient != nil {
+		return n.restaurantClient
+	}
+
+	n.restaurantClient = restaurant.NewClient()
+	return n.restaurantClient
+}
+
+func (n *restaurantNotifier) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	if event.Type != models.EventTypeOrderStatusChanged {
+		return nil
+	}
+
+	payload := models.OrderStatusChangedPayload{}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+	if payload.Status != models.OrderStatusCancelled {
+		return nil
+	}
+
+	ctx, span := tracing.StartSpan(ctx, "RestaurantNotifier.Publish")
+	defer span.End()
+
+	order, err := n.getOrderRepository().FindOrderByID(ctx, event.OrderID)
+	if err == repositories.ErrNotFound {
+		// The order has been deleted since it was cancelled, so there is
+		// nothing left for the restaurant to stop.
+		return nil
+	}
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+
+	if err = n.getRestaurantClient().NotifyOrderCancelled(ctx, order); err != nil {
+		span.SetError(err)
+		return err
+	}
+
+	return nil
+}
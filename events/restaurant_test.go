@@ -0,0 +1,106 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/clients/mock_restaurant"
+	"github.com/SebastianCoetzee/blog-order-service-example/events"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RestaurantNotifier", func() {
+	var (
+		ctrl             *gomock.Controller
+		orderRepo        *mock_repositories.MockOrderRepository
+		restaurantClient *mock_restaurant.MockClient
+		event            *models.OutboxEvent
+		order            *models.Order
+		err              error
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		orderRepo = mock_repositories.NewMockOrderRepository(ctrl)
+		restaurantClient = mock_restaurant.NewMockClient(ctrl)
+
+		order = &models.Order{ID: 3, RestaurantID: 9, Status: models.OrderStatusCancelled, CancellationReason: models.CancellationReasonChangedMind}
+		event = &models.OutboxEvent{
+			ID:      7,
+			Type:    models.EventTypeOrderStatusChanged,
+			OrderID: 3,
+			Payload: []byte(`{"order_id":3,"status":"cancelled","cancellation_reason":"changed_mind"}`),
+		}
+	})
+
+	JustBeforeEach(func() {
+		notifier := events.NewRestaurantNotifier()
+		notifier.SetOrderRepository(orderRepo)
+		notifier.SetRestaurantClient(restaurantClient)
+
+		err = notifier.Publish(context.Background(), event)
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	Describe("when an order is cancelled", func() {
+		BeforeEach(func() {
+			orderRepo.EXPECT().FindOrderByID(gomock.Any(), gomock.Eq(3)).Return(order, error(nil))
+			restaurantClient.EXPECT().NotifyOrderCancelled(gomock.Any(), gomock.Eq(order)).Return(nil)
+		})
+
+		It("notifies the order's restaurant", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Describe("when the restaurant cannot be notified", func() {
+		BeforeEach(func() {
+			orderRepo.EXPECT().FindOrderByID(gomock.Any(), gomock.Eq(3)).Return(order, error(nil))
+			restaurantClient.EXPECT().NotifyOrderCancelled(gomock.Any(), gomock.Any()).Return(errors.New("timeout"))
+		})
+
+		It("fails so that the event is retried", func() {
+			Expect(err).To(MatchError("timeout"))
+		})
+	})
+
+	Describe("when the order has been deleted since", func() {
+		BeforeEach(func() {
+			orderRepo.EXPECT().FindOrderByID(gomock.Any(), gomock.Eq(3)).Return(nil, repositories.ErrNotFound)
+		})
+
+		It("has nothing to notify", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Describe("when the order moves into another status", func() {
+		BeforeEach(func() {
+			event.Payload = []byte(`{"order_id":3,"status":"preparing"}`)
+		})
+
+		It("ignores the event", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Describe("with other events", func() {
+		BeforeEach(func() {
+			event.Type = models.EventTypeOrderRefunded
+			event.Payload = []byte(`{"order_id":3}`)
+		})
+
+		It("ignores them", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,129 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+)
+
+// NewSubscriptionPublisher returns a publisher that queues events for the
+// webhook subscriptions of the restaurant that each order was placed with.
+func NewSubscriptionPublisher() *subscriptionPublisher {
+	return &subscriptionPublisher{}
+}
+
+// subscriptionPublisher is a Publisher that adds an entry to the delivery log
+// of each webhook subscription that wants an event. The entries are sent by a
+// webhook dispatcher. The relay publishes events at least once, but an event
+// is only queued once for each subscription however often it is published.
+// Receivers should still use the X-Event-ID header to ignore events they have
+// already handled, since a delivery may be sent again if the dispatcher stops
+// before it is saved.
+type subscriptionPublisher struct {
+	orderRepository   repositories.OrderRepository
+	webhookRepository repositories.WebhookRepository
+	now               func() time.Time
+}
+
+func (p *subscriptionPublisher) SetOrderRepository(r repositories.OrderRepository) {
+	p.orderRepository = r
+}
+
+func (p *subscriptionPublisher) getOrderRepository() repositories.OrderRepository {
+	if p.orderRepository != nil {
+		return p.orderRepository
+	}
+
+	p.orderRepository = repositories.NewOrderRepository(application.ResolveDB())
+	return p.orderRepository
+}
+
+func (p *subscriptionPublisher) SetWebhookRepository(r repositories.WebhookRepository) {
+	p.webhookRepository = r
+}
+
+func (p *subscriptionPublisher) getWebhookRepository() repositories.WebhookRepository {
+	if p.webhookRepository != nil {
+		return p.webhookRepository
+	}
+
+	p.webhookRepository = repositories.NewWebhookRepository(application.ResolveDB())
+	return p.webhookRepository
+}
+
+// SetClock overrides the function used to tell the current time.
+func (p *subscriptionPublisher) SetClock(now func() time.Time) {
+	p.now = now
+}
+
+func (p *subscriptionPublisher) getClock() func() time.Time {
+	if p.now != nil {
+		return p.now
+	}
+
+	return time.Now
+}
+
+func (p *subscriptionPublisher) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	ctx, span := tracing.StartSpan(ctx, "SubscriptionPublisher.Publish")
+	defer span.End()
+
+	order, err := p.getOrderRepository().FindOrderByID(ctx, event.OrderID)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+
+	subscriptions, err := p.getWebhookRepository().FindSubscriptionsByRestaurantID(ctx, order.RestaurantID)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+
+	now := p.getClock()()
+	deliveries := models.WebhookDeliveries{}
+	for _, subscription := range subscriptions {
+		if !subscription.Wants(event.Type) {
+			continue
+		}
+
+		delivery, err := models.NewWebhookDelivery(subscription, event, now)
+		if err != nil {
+			span.SetError(err)
+			return err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	span.SetAttribute("deliveries.count", len(deliveries))
+
+	if err = p.getWebhookRepository().AddDeliveries(ctx, deliveries); err != nil {
+		span.SetError(err)
+		return err
+	}
+
+	return nil
+}
+
+// Publishers returns a publisher that publishes each event to every one of
+// publishers in turn, stopping at the first error. Since a failed event is
+// retried in full, publishers that come before the failure may see the event
+// more than once.
+func Publishers(publishers ...Publisher) Publisher {
+	return multiPublisher(publishers)
+}
+
+type multiPublisher []Publisher
+
+func (m multiPublisher) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	for _, publisher := range m {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/events"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SubscriptionPublisher", func() {
+	var (
+		ctrl        *gomock.Controller
+		orderRepo   *mock_repositories.MockOrderRepository
+		webhookRepo *mock_repositories.MockWebhookRepository
+		event       *models.OutboxEvent
+		added       models.WebhookDeliveries
+		err         error
+
+		now = time.Date(2019, 5, 6, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		orderRepo = mock_repositories.NewMockOrderRepository(ctrl)
+		webhookRepo = mock_repositories.NewMockWebhookRepository(ctrl)
+
+		event = &models.OutboxEvent{ID: 7, Type: models.EventTypeOrderRefunded, OrderID: 3, Payload: []byte(`{}`)}
+		orderRepo.EXPECT().FindOrderByID(gomock.Any(), gomock.Eq(3)).Return(&models.Order{ID: 3, RestaurantID: 9}, error(nil))
+		webhookRepo.EXPECT().FindSubscriptionsByRestaurantID(gomock.Any(), gomock.Eq(9)).Return(models.WebhookSubscriptions{
+			{ID: 1, RestaurantID: 9, EventTypes: []models.EventType{}},
+			{ID: 2, RestaurantID: 9, EventTypes: []models.EventType{models.EventTypeOrderPlaced}},
+			{ID: 3, RestaurantID: 9, EventTypes: []models.EventType{models.EventTypeOrderRefunded}},
+		}, error(nil))
+		webhookRepo.EXPECT().AddDeliveries(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, d models.WebhookDeliveries) { added = d }).
+			Return(nil)
+	})
+
+	JustBeforeEach(func() {
+		publisher := events.NewSubscriptionPublisher()
+		publisher.SetOrderRepository(orderRepo)
+		publisher.SetWebhookRepository(webhookRepo)
+		publisher.SetClock(func() time.Time { return now })
+
+		err = events.Publishers(publisher).Publish(context.Background(), event)
+	})
+
+	It("logs a delivery for each of the restaurant's subscriptions that wants the event", func() {
+		Expect(err).To(BeNil())
+		Expect(len(added)).To(Equal(2))
+		Expect(added[0].SubscriptionID).To(Equal(1))
+		Expect(added[1].SubscriptionID).To(Equal(3))
+		Expect(added[1].EventID).To(Equal(int64(7)))
+		Expect(added[1].NextAttemptAt).To(Equal(now))
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
+
+var _ = Describe("Publishers", func() {
+	It("stops at the first publisher that fails", func() {
+		failing := events.NewInMemoryPublisher()
+		failing.FailWith(errors.New("unavailable"))
+		after := events.NewInMemoryPublisher()
+
+		err := events.Publishers(failing, after).Publish(context.Background(), &models.OutboxEvent{ID: 1})
+		Expect(err).To(MatchError("unavailable"))
+		Expect(after.Events()).To(BeEmpty())
+	})
+})
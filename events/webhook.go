@@ -0,0 +1,231 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	"github.com/pkg/errors"
+)
+
+// Headers set on every webhook request.
+const (
+	HeaderEventID   = "X-Event-ID"
+	HeaderEventType = "X-Event-Type"
+	// HeaderTimestamp holds the Unix time at which the request was signed.
+	// Consumers should reject requests with old timestamps, so that captured
+	// requests cannot be replayed.
+	HeaderTimestamp = "X-Event-Timestamp"
+	// HeaderSignature holds the signature of the request, as returned by
+	// Sign.
+	HeaderSignature = "X-Event-Signature"
+	// HeaderDeliveryID identifies an entry in a webhook subscription's
+	// delivery log. Replays of a delivery have their own IDs.
+	HeaderDeliveryID = "X-Delivery-ID"
+)
+
+// webhookTimeout bounds each delivery, so that a slow consumer cannot hold
+// a claim on a batch indefinitely.
+const webhookTimeout = 10 * time.Second
+
+// NewWebhookPublisher returns a publisher that posts events to url. When
+// secret is not empty, requests are signed with it.
+func NewWebhookPublisher(url, secret string) *webhookPublisher {
+	return &webhookPublisher{
+		url:    url,
+		secret: secret,
+	}
+}
+
+// webhookPublisher is a Publisher that posts each event's JSON envelope to a
+// URL. Any response other than a 2xx is treated as a failed delivery.
+type webhookPublisher struct {
+	url    string
+	secret string
+	client *http.Client
+	now    func() time.Time
+}
+
+// SetHTTPClient overrides the client used to make requests.
+func (p *webhookPublisher) SetHTTPClient(c *http.Client) {
+	p.client = c
+}
+
+func (p *webhookPublisher) getHTTPClient() *http.Client {
+	if p.client != nil {
+		return p.client
+	}
+
+	p.client = newWebhookClient()
+	return p.client
+}
+
+// SetClock overrides the function used to tell the current time.
+func (p *webhookPublisher) SetClock(now func() time.Time) {
+	p.now = now
+}
+
+func (p *webhookPublisher) getClock() func() time.Time {
+	if p.now != nil {
+		return p.now
+	}
+
+	return time.Now
+}
+
+func (p *webhookPublisher) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	ctx, span := tracing.StartSpan(ctx, "WebhookPublisher.Publish")
+	defer span.End()
+
+	span.SetAttribute("event.id", event.ID)
+	span.SetAttribute("event.type", string(event.Type))
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+
+	header := http.Header{}
+	header.Set(HeaderEventID, strconv.FormatInt(event.ID, 10))
+	header.Set(HeaderEventType, string(event.Type))
+
+	status, err := postWebhook(ctx, p.getHTTPClient(), p.url, p.secret, header, body, p.getClock()())
+	span.SetAttribute("http.status_code", status)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+
+	return nil
+}
+
+func newWebhookClient() *http.Client {
+	return &http.Client{Timeout: webhookTimeout}
+}
+
+// ErrInternalWebhookAddress is returned for webhook URLs whose host is, or
+// resolves to, a loopback, private, link-local or unspecified address, so
+// that restaurants cannot make this service send requests to its own network.
+var ErrInternalWebhookAddress = errors.New("webhooks may not be sent to loopback, private, link-local or unspecified addresses")
+
+// Resolver looks up the addresses of a host. *net.Resolver is a Resolver.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// CheckWebhookHost returns ErrInternalWebhookAddress when host is, or any of
+// the addresses resolver resolves it to is, an address that webhooks may not
+// be sent to.
+func CheckWebhookHost(ctx context.Context, resolver Resolver, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		return checkWebhookIP(ip)
+	}
+
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if err := checkWebhookIP(addr.IP); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkWebhookIP(ip net.IP) error {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return ErrInternalWebhookAddress
+	}
+
+	return nil
+}
+
+// newSubscriberClient returns a client for the URLs of webhook
+// subscriptions. Since a host can be pointed elsewhere after it was
+// subscribed, the address of every connection, including those made for
+// redirects, is checked again once it has been resolved. Proxies are not
+// used, as they would hide the address.
+func newSubscriberClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   webhookTimeout,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil {
+				return errors.Errorf("cannot connect to unresolved address %q", address)
+			}
+			return checkWebhookIP(ip)
+		},
+	}
+
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// postWebhook posts body to url with the given headers, signed with secret
+// at t when secret is not empty. It returns the response status, which is 0
+// when no response was received, and an error unless the status is 2xx.
+func postWebhook(ctx context.Context, client *http.Client, url, secret string, header http.Header, body []byte, t time.Time) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	for key := range header {
+		req.Header.Set(key, header.Get(key))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set(HeaderTimestamp, strconv.FormatInt(t.Unix(), 10))
+		req.Header.Set(HeaderSignature, Sign(secret, t, body))
+	}
+	tracing.Inject(ctx, req.Header)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, errors.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// Sign returns the signature header value of body sent at t: the hex encoded
+// HMAC-SHA256, keyed with secret, of the Unix timestamp, a full stop and the
+// body, prefixed with "sha256=". Consumers compute the same value from the
+// timestamp header and the body to check that a request came from this
+// service.
+func Sign(secret string, t time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(t.Unix(), 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
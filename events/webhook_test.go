@@ -0,0 +1,124 @@
+package events_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/events"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WebhookPublisher", func() {
+	var (
+		server  *httptest.Server
+		status  int
+		request *http.Request
+		body    []byte
+		event   *models.OutboxEvent
+		err     error
+
+		now = time.Date(2019, 5, 3, 12, 0, 5, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		status = http.StatusNoContent
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+			body, _ = ioutil.ReadAll(r.Body)
+			w.WriteHeader(status)
+		}))
+
+		event = &models.OutboxEvent{
+			ID:            7,
+			Type:          models.EventTypeOrderStatusChanged,
+			SchemaVersion: 1,
+			OrderID:       3,
+			Payload:       json.RawMessage(`{"order_id":3,"status":"cancelled"}`),
+			OccurredAt:    time.Date(2019, 5, 3, 12, 0, 0, 0, time.UTC),
+		}
+	})
+
+	JustBeforeEach(func() {
+		publisher := events.NewWebhookPublisher(server.URL, "s3cret")
+		publisher.SetClock(func() time.Time { return now })
+		err = publisher.Publish(context.Background(), event)
+	})
+
+	Describe("when the consumer accepts the event", func() {
+		It("posts the signed event envelope", func() {
+			Expect(err).To(BeNil())
+			Expect(request.Method).To(Equal(http.MethodPost))
+			Expect(request.Header.Get(events.HeaderEventID)).To(Equal("7"))
+			Expect(request.Header.Get(events.HeaderEventType)).To(Equal("OrderStatusChanged"))
+			Expect(request.Header.Get(events.HeaderTimestamp)).To(Equal("1556884805"))
+			Expect(request.Header.Get(events.HeaderSignature)).To(Equal(events.Sign("s3cret", now, body)))
+			Expect(body).To(MatchJSON(`{
+				"id": 7,
+				"type": "OrderStatusChanged",
+				"schema_version": 1,
+				"order_id": 3,
+				"payload": {"order_id": 3, "status": "cancelled"},
+				"occurred_at": "2019-05-03T12:00:00Z"
+			}`))
+		})
+	})
+
+	Describe("when the consumer responds with an error", func() {
+		BeforeEach(func() {
+			status = http.StatusServiceUnavailable
+		})
+
+		It("fails the delivery", func() {
+			Expect(err).To(MatchError("webhook responded with status 503"))
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+})
+
+// resolverFunc is a Resolver that answers lookups with a function.
+type resolverFunc func(ctx context.Context, host string) ([]net.IPAddr, error)
+
+func (f resolverFunc) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	return f(ctx, host)
+}
+
+var _ = Describe("CheckWebhookHost", func() {
+	resolver := resolverFunc(func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		switch host {
+		case "hooks.example.com":
+			return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}}, nil
+		case "rebound.example.com":
+			return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}, {IP: net.ParseIP("10.0.0.5")}}, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	})
+
+	It("accepts hosts that resolve to public addresses", func() {
+		Expect(events.CheckWebhookHost(context.Background(), resolver, "hooks.example.com")).To(Succeed())
+		Expect(events.CheckWebhookHost(context.Background(), resolver, "93.184.216.34")).To(Succeed())
+	})
+
+	It("rejects loopback, private, link-local and unspecified addresses", func() {
+		for _, host := range []string{"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "fd00::1", "169.254.169.254", "fe80::1", "0.0.0.0", "::"} {
+			Expect(events.CheckWebhookHost(context.Background(), resolver, host)).To(Equal(events.ErrInternalWebhookAddress), host)
+		}
+	})
+
+	It("rejects hosts with any address that may not be sent to", func() {
+		Expect(events.CheckWebhookHost(context.Background(), resolver, "rebound.example.com")).To(Equal(events.ErrInternalWebhookAddress))
+	})
+
+	It("returns lookup errors", func() {
+		Expect(events.CheckWebhookHost(context.Background(), resolver, "missing.example.com")).To(MatchError(ContainSubstring("no such host")))
+	})
+})
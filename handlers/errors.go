@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+)
+
+// errorResponse is the body returned for errors that clients can act on.
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// renderError writes the response for an error returned by a service.
+// Business rule violations are returned to the client as a structured error,
+// anything else is reported as a 500 without details.
+func renderError(c Context, err error) {
+	serviceErr, ok := err.(*services.Error)
+	if !ok {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(errorStatus(serviceErr.Kind), errorResponse{
+		Error: errorBody{
+			Code:    serviceErr.Code,
+			Message: serviceErr.Message,
+			Details: serviceErr.Details,
+		},
+	})
+}
+
+// renderBadRequest writes a structured 400 response.
+func renderBadRequest(c Context, code, message string) {
+	c.JSON(http.StatusBadRequest, errorResponse{
+		Error: errorBody{Code: code, Message: message},
+	})
+}
+
+func errorStatus(kind services.ErrorKind) int {
+	switch kind {
+	case services.ErrorKindInvalid:
+		return http.StatusBadRequest
+	case services.ErrorKindNotFound:
+		return http.StatusNotFound
+	case services.ErrorKindForbidden:
+		return http.StatusForbidden
+	case services.ErrorKindConflict:
+		return http.StatusConflict
+	case services.ErrorKindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+)
+
+// The headers used for conditional requests.
+const (
+	etagHeader        = "ETag"
+	ifMatchHeader     = "If-Match"
+	ifNoneMatchHeader = "If-None-Match"
+)
+
+// orderETag returns the strong ETag of a single order, which is its version.
+func orderETag(order *models.Order) string {
+	return `"v` + strconv.Itoa(order.Version) + `"`
+}
+
+// ordersETag returns a weak ETag for a list of orders. It changes whenever an
+// order is added to or removed from the list, or changes itself.
+func ordersETag(orders models.Orders) string {
+	h := sha256.New()
+	for _, order := range orders {
+		fmt.Fprintf(h, "%d:%d,", order.ID, order.Version)
+	}
+
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// notModified writes a 304 response and returns true when the If-None-Match
+// header of the request matches etag. ETags are compared weakly, as RFC 7232
+// requires for If-None-Match.
+func notModified(c Context, etag string) bool {
+	header := c.GetHeader(ifNoneMatchHeader)
+	if header == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			c.Header(etagHeader, etag)
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// requireIfMatch returns the order version that the If-Match header of the
+// request refers to. "*" matches any version, and an ETag that is not the
+// ETag of an order version matches none. When the header is missing it
+// writes a 428 response and returns false.
+func requireIfMatch(c Context) (int, bool) {
+	header := strings.TrimSpace(c.GetHeader(ifMatchHeader))
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, errorResponse{
+			Error: errorBody{
+				Code:    "if_match_required",
+				Message: "changes to an order must be made with an If-Match header holding the order's ETag",
+			},
+		})
+		return 0, false
+	}
+
+	if header == "*" {
+		return services.AnyVersion, true
+	}
+
+	if strings.HasPrefix(header, `"v`) && strings.HasSuffix(header, `"`) {
+		if version, err := strconv.Atoi(header[2 : len(header)-1]); err == nil && version > 0 {
+			return version, true
+		}
+	}
+
+	return -1, true
+}
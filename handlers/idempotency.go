@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// The headers used to make POST and PATCH requests idempotent.
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// Idempotency is middleware that makes POST and PATCH requests with an
+// Idempotency-Key header safe to retry.
+func Idempotency(c *gin.Context) {
+	p := &Provider{}
+	p.Idempotency(c)
+}
+
+// Idempotency is the provider method behind the Idempotency middleware. The
+// first request with a key is handled as usual and its response is stored.
+// Repeats of it get the stored response back without being handled again.
+// Responses with a 5xx status are not stored, so that the request can be
+// retried with the same key. Keys are scoped to the caller, so requests
+// without an X-Actor-ID header are handled as if they had no key: anonymous
+// callers would otherwise share a scope and see each other's responses.
+func (p *Provider) Idempotency(c *gin.Context) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	scope := c.GetHeader(actorIDHeader)
+	if key == "" || scope == "" || (c.Request.Method != http.MethodPost && c.Request.Method != http.MethodPatch) {
+		c.Next()
+		return
+	}
+
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.Idempotency")
+	defer span.End()
+
+	if len(key) > maxIdempotencyKeyLength {
+		renderBadRequest(c, "invalid_idempotency_key", "the Idempotency-Key header must be at most 255 characters")
+		c.Abort()
+		return
+	}
+
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		span.SetError(err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	claim, err := p.getIdempotencyService().Begin(ctx, scope, key, requestFingerprint(c.Request, body))
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		c.Abort()
+		return
+	}
+
+	if claim.IsCompleted() {
+		span.SetAttribute("idempotency.replayed", true)
+		c.Header(idempotentReplayedHeader, "true")
+		c.Data(claim.ResponseStatus, claim.ResponseContentType, claim.ResponseBody)
+		c.Abort()
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	c.Next()
+
+	status := recorder.Status()
+	if status >= http.StatusInternalServerError {
+		err = p.getIdempotencyService().Release(ctx, claim)
+	} else {
+		err = p.getIdempotencyService().Complete(ctx, claim, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+	}
+	if err != nil {
+		span.SetError(err)
+	}
+}
+
+// requestFingerprint identifies a request by its method, path, query and
+// body, so that a key that is reused for a different request can be
+// detected.
+func requestFingerprint(req *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.Path + "?" + req.URL.RawQuery + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body as it is written.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,175 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_services"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Idempotency", func() {
+	var (
+		ctrl                   *gomock.Controller
+		mockIdempotencyService *mock_services.MockIdempotencyService
+		app                    *gin.Engine
+		handled                int
+		status                 int
+		res                    *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockIdempotencyService = mock_services.NewMockIdempotencyService(ctrl)
+
+		p := &handlers.Provider{}
+		p.SetIdempotencyService(mockIdempotencyService)
+
+		handled = 0
+		status = http.StatusCreated
+
+		gin.SetMode(gin.TestMode)
+		app = gin.New()
+		app.Use(p.Idempotency)
+		app.POST("/orders", func(c *gin.Context) {
+			handled++
+			c.JSON(status, gin.H{"id": 1})
+		})
+	})
+
+	send := func(actorID, target, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set("Idempotency-Key", key)
+		if actorID != "" {
+			req.Header.Set("X-Actor-ID", actorID)
+		}
+		res := httptest.NewRecorder()
+		app.ServeHTTP(res, req)
+		return res
+	}
+
+	post := func(key, body string) *httptest.ResponseRecorder {
+		return send("user:5", "/orders", key, body)
+	}
+
+	Describe("when the key is new", func() {
+		BeforeEach(func() {
+			claim := &models.IdempotencyKey{Scope: "user:5", Key: "abc"}
+			mockIdempotencyService.EXPECT().Begin(gomock.Any(), gomock.Eq("user:5"), gomock.Eq("abc"), gomock.Any()).Return(claim, error(nil))
+			mockIdempotencyService.EXPECT().
+				Complete(gomock.Any(), gomock.Eq(claim), gomock.Eq(http.StatusCreated), gomock.Eq("application/json; charset=utf-8"), gomock.Eq([]byte(`{"id":1}`))).
+				Return(error(nil))
+
+			res = post("abc", `{"restaurant_id":8}`)
+		})
+
+		It("handles the request and stores the response", func() {
+			Expect(handled).To(Equal(1))
+			Expect(res.Code).To(Equal(http.StatusCreated))
+		})
+	})
+
+	Describe("when the request fails", func() {
+		BeforeEach(func() {
+			status = http.StatusInternalServerError
+			claim := &models.IdempotencyKey{Scope: "user:5", Key: "abc"}
+			mockIdempotencyService.EXPECT().Begin(gomock.Any(), gomock.Eq("user:5"), gomock.Eq("abc"), gomock.Any()).Return(claim, error(nil))
+			mockIdempotencyService.EXPECT().Release(gomock.Any(), gomock.Eq(claim)).Return(error(nil))
+
+			res = post("abc", `{"restaurant_id":8}`)
+		})
+
+		It("releases the key so that the request can be retried", func() {
+			Expect(res.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	Describe("when the request is a repeat", func() {
+		BeforeEach(func() {
+			completedAt := time.Now()
+			mockIdempotencyService.EXPECT().Begin(gomock.Any(), gomock.Eq("user:5"), gomock.Eq("abc"), gomock.Any()).Return(&models.IdempotencyKey{
+				ResponseStatus:      http.StatusCreated,
+				ResponseContentType: "application/json; charset=utf-8",
+				ResponseBody:        []byte(`{"id":1}`),
+				CompletedAt:         &completedAt,
+			}, error(nil))
+
+			res = post("abc", `{"restaurant_id":8}`)
+		})
+
+		It("replays the stored response without handling the request", func() {
+			Expect(handled).To(Equal(0))
+			Expect(res.Code).To(Equal(http.StatusCreated))
+			Expect(res.Body.String()).To(Equal(`{"id":1}`))
+			Expect(res.Header().Get("Idempotent-Replayed")).To(Equal("true"))
+		})
+	})
+
+	Describe("when the key was used for a different request", func() {
+		BeforeEach(func() {
+			mockIdempotencyService.EXPECT().Begin(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil, &services.Error{Kind: services.ErrorKindConflict, Code: "idempotency_key_reused"})
+
+			res = post("abc", `{"restaurant_id":9}`)
+		})
+
+		It("returns a 409", func() {
+			Expect(handled).To(Equal(0))
+			Expect(res.Code).To(Equal(http.StatusConflict))
+		})
+	})
+
+	Describe("when there is no key", func() {
+		BeforeEach(func() {
+			res = post("", `{"restaurant_id":8}`)
+		})
+
+		It("handles the request as usual", func() {
+			Expect(handled).To(Equal(1))
+		})
+	})
+
+	Describe("when the caller is anonymous", func() {
+		BeforeEach(func() {
+			res = send("", "/orders", "abc", `{"restaurant_id":8}`)
+		})
+
+		It("handles the request without storing the response", func() {
+			Expect(handled).To(Equal(1))
+			Expect(res.Header().Get("Idempotent-Replayed")).To(BeEmpty())
+		})
+	})
+
+	Describe("when requests differ only in their query", func() {
+		var fingerprints []string
+
+		BeforeEach(func() {
+			fingerprints = nil
+			mockIdempotencyService.EXPECT().Begin(gomock.Any(), gomock.Eq("user:5"), gomock.Eq("abc"), gomock.Any()).
+				Do(func(_ interface{}, _, _, fingerprint string) { fingerprints = append(fingerprints, fingerprint) }).
+				Return(nil, &services.Error{Kind: services.ErrorKindConflict, Code: "idempotency_key_reused"}).
+				Times(2)
+
+			send("user:5", "/orders?notify=true", "abc", `{"restaurant_id":8}`)
+			send("user:5", "/orders?notify=false", "abc", `{"restaurant_id":8}`)
+		})
+
+		It("gives them different fingerprints", func() {
+			Expect(fingerprints).To(HaveLen(2))
+			Expect(fingerprints[0]).NotTo(Equal(fingerprints[1]))
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
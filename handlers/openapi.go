@@ -0,0 +1,706 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/openapi"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	apiDocumentOnce sync.Once
+	apiDocument     *openapi.Document
+)
+
+// APIDocument returns the OpenAPI document that describes the routes
+// registered by Routes.
+func APIDocument() *openapi.Document {
+	apiDocumentOnce.Do(func() {
+		apiDocument = newAPIDocument()
+	})
+
+	return apiDocument
+}
+
+// OpenAPI serves the OpenAPI document of the service.
+func OpenAPI(c *gin.Context) {
+	c.JSON(http.StatusOK, APIDocument())
+}
+
+// APIDocs serves a page that renders the OpenAPI document for people to read.
+func APIDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(apiDocsPage))
+}
+
+const apiDescription = `The order service stores the orders that users place at restaurants.
+
+Callers identify themselves with the X-Actor-ID header, for example user:42,
+restaurant:7 or agent:3, and list their scopes in X-Actor-Scopes separated by
+spaces. Every response carries an X-Request-ID header, which is taken from the
+request when it has one.
+
+POST and PATCH requests with an Idempotency-Key header are only handled once
+for each caller; repeats get the first response back with an
+Idempotent-Replayed header.`
+
+// newAPIDocument describes every route of the service.
+func newAPIDocument() *openapi.Document {
+	doc := openapi.NewDocument("Order Service", apiDescription, "1.0.0")
+
+	// Types that encode themselves or take a fixed set of values are
+	// described by hand; everything else is generated from its JSON tags.
+	doc.DefineSchema("Currency", models.Currency(""), &openapi.Schema{
+		Type:        "string",
+		Pattern:     "^[A-Z]{3}$",
+		Description: "An ISO 4217 alphabetic currency code.",
+	})
+	doc.DefineSchema("Money", models.Money{}, &openapi.Schema{
+		Type:        "object",
+		Description: "An amount in the minor unit of a currency, such as pence for GBP, with its decimal representation.",
+		Properties: map[string]*openapi.Schema{
+			"minor_units": {Type: "integer", Format: "int64"},
+			"currency":    openapi.Ref("Currency"),
+			"decimal":     {Type: "string"},
+		},
+		Required: []string{"minor_units", "currency", "decimal"},
+	})
+	defineEnum(doc, "OrderStatus", models.OrderStatus(""),
+		models.OrderStatusPlaced, models.OrderStatusAccepted, models.OrderStatusPreparing,
+		models.OrderStatusDispatched, models.OrderStatusDelivered, models.OrderStatusCancelled)
+	defineEnum(doc, "CancellationReason", models.CancellationReason(""),
+		models.CancellationReasonChangedMind, models.CancellationReasonOrderedByMistake,
+		models.CancellationReasonTakingTooLong, models.CancellationReasonRestaurantUnavailable,
+		models.CancellationReasonOther)
+	defineEnum(doc, "StatsPeriod", models.StatsPeriod(""), models.StatsPeriodWeek, models.StatsPeriodMonth)
+	defineEnum(doc, "EventType", models.EventType(""),
+		models.EventTypeOrderPlaced, models.EventTypeOrderStatusChanged, models.EventTypeOrderRefunded)
+	defineEnum(doc, "WebhookDeliveryStatus", models.WebhookDeliveryStatus(""),
+		models.WebhookDeliveryStatusPending, models.WebhookDeliveryStatusSucceeded, models.WebhookDeliveryStatusFailed)
+	defineEnum(doc, "OrderAuditAction", models.OrderAuditAction(""),
+		models.OrderAuditActionCancel, models.OrderAuditActionRefund, models.OrderAuditActionDelete)
+
+	doc.DefineSchema("ErrorBody", errorBody{}, nil)
+	errorSchema := doc.DefineSchema("Error", errorResponse{}, nil)
+
+	order := doc.SchemaFor(models.Order{})
+	orders := &openapi.Schema{Type: "array", Items: order}
+	cancelRequest := doc.DefineSchema("CancelOrderRequest", cancelOrderRequest{}, nil)
+	refundRequest := doc.SchemaFor(services.RefundRequest{})
+
+	subscriptionRequest := doc.DefineSchema("WebhookSubscriptionRequest", services.WebhookSubscriptionRequest{}, nil)
+	doc.Components.Schemas["WebhookSubscriptionRequest"].Required = []string{"url"}
+	subscriptionUpdate := *doc.Components.Schemas["WebhookSubscriptionRequest"]
+	subscriptionUpdate.Required = nil
+	doc.Components.Schemas["WebhookSubscriptionUpdate"] = &subscriptionUpdate
+	subscription := doc.SchemaFor(models.WebhookSubscription{})
+	subscriptionWithSecret := doc.DefineSchema("WebhookSubscriptionWithSecret", webhookSubscriptionWithSecret{}, nil)
+	delivery := doc.SchemaFor(models.WebhookDelivery{})
+
+	actorID := doc.AddParameter("ActorID", &openapi.Parameter{
+		Name:        actorIDHeader,
+		In:          "header",
+		Description: "The caller, for example user:42, restaurant:7 or agent:3.",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	})
+	actorScopes := doc.AddParameter("ActorScopes", &openapi.Parameter{
+		Name:        actorScopesHeader,
+		In:          "header",
+		Description: "The scopes granted to the caller, separated by spaces, for example orders:admin.",
+		Schema:      &openapi.Schema{Type: "string"},
+	})
+	ifMatch := doc.AddParameter("IfMatch", &openapi.Parameter{
+		Name:        ifMatchHeader,
+		In:          "header",
+		Description: `The ETag of the order version that the change is based on, such as "v3", or * for any version.`,
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	})
+	ifNoneMatch := doc.AddParameter("IfNoneMatch", &openapi.Parameter{
+		Name:        ifNoneMatchHeader,
+		In:          "header",
+		Description: "An ETag from an earlier response. A 304 is returned when it is still current.",
+		Schema:      &openapi.Schema{Type: "string"},
+	})
+	idempotencyKey := doc.AddParameter("IdempotencyKey", &openapi.Parameter{
+		Name:        idempotencyKeyHeader,
+		In:          "header",
+		Description: "Makes the request safe to retry. At most 255 characters. Ignored without an X-Actor-ID header.",
+		Schema:      &openapi.Schema{Type: "string"},
+	})
+
+	badRequest := doc.AddResponse("BadRequest", &openapi.Response{
+		Description: "The request is invalid. Invalid parameters and bodies are described by the error code.",
+		Content:     openapi.JSONContent(errorSchema),
+	})
+	unauthorized := doc.AddResponse("Unauthorized", &openapi.Response{
+		Description: "The X-Actor-ID header is missing.",
+	})
+	forbidden := doc.AddResponse("Forbidden", &openapi.Response{
+		Description: "The caller is not allowed to make the request.",
+		Content:     openapi.JSONContent(errorSchema),
+	})
+	notFound := doc.AddResponse("NotFound", &openapi.Response{
+		Description: "The resource does not exist or is hidden from the caller.",
+		Content:     openapi.JSONContent(errorSchema),
+	})
+	conflict := doc.AddResponse("Conflict", &openapi.Response{
+		Description: "The request breaks a business rule, such as cancelling an order that is being prepared.",
+		Content:     openapi.JSONContent(errorSchema),
+	})
+	notModified := doc.AddResponse("NotModified", &openapi.Response{
+		Description: "The resource still has the ETag given in If-None-Match.",
+		Headers:     map[string]*openapi.Header{etagHeader: etagResponseHeader},
+	})
+	preconditionFailed := doc.AddResponse("PreconditionFailed", &openapi.Response{
+		Description: "The order has changed since the version in If-Match. The current version is in the error details.",
+		Content:     openapi.JSONContent(errorSchema),
+	})
+	preconditionRequired := doc.AddResponse("PreconditionRequired", &openapi.Response{
+		Description: "The If-Match header is missing.",
+		Content:     openapi.JSONContent(errorSchema),
+	})
+	internalError := doc.AddResponse("InternalServerError", &openapi.Response{
+		Description: "The request failed unexpectedly.",
+	})
+
+	userID := pathParameter("id", "The ID of the user.")
+	orderID := pathParameter("id", "The ID of the order.")
+	restaurantID := pathParameter("id", "The ID of the restaurant.")
+	subscriptionID := pathParameter("id", "The ID of the webhook subscription.")
+
+	doc.AddOperation(http.MethodGet, "/users/:id/orders", &openapi.Operation{
+		OperationID: "findOrdersForUser",
+		Summary:     "List the orders of a user, newest first",
+		Tags:        []string{"Orders"},
+		Parameters:  []*openapi.Parameter{userID, ifNoneMatch},
+		Responses: map[string]*openapi.Response{
+			"200": etagged("The user's orders.", orders),
+			"304": notModified,
+			"400": badRequest,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodGet, "/users/:id/orders/stats", &openapi.Operation{
+		OperationID: "findOrderStatsForUser",
+		Summary:     "Summarise the orders of a user",
+		Description: "Only available to the user and to callers with the orders:admin scope.",
+		Tags:        []string{"Orders"},
+		Parameters: []*openapi.Parameter{
+			userID,
+			actorID,
+			actorScopes,
+			queryParameter("period", "The period that orders are counted in. Defaults to month.", openapi.Ref("StatsPeriod")),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The user's order statistics.", doc.SchemaFor(models.OrderStats{})),
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodGet, "/users/:id/orders/stream", &openapi.Operation{
+		OperationID: "streamOrdersForUser",
+		Summary:     "Stream changes to the orders of a user as server-sent events",
+		Tags:        []string{"Orders"},
+		Parameters: []*openapi.Parameter{
+			userID,
+			actorID,
+			actorScopes,
+			{Name: lastEventIDHeader, In: "header", Description: "The ID of the last event received, to resume a stream.", Schema: &openapi.Schema{Type: "string"}},
+			queryParameter("last_event_id", "The same as the Last-Event-ID header, for clients that cannot set headers.", &openapi.Schema{Type: "string"}),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "A stream of order events.",
+				Content:     map[string]*openapi.MediaType{"text/event-stream": {Schema: &openapi.Schema{Type: "string"}}},
+			},
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodGet, "/users/:id/spend", &openapi.Operation{
+		OperationID: "spendForUser",
+		Summary:     "Total what a user has spent in one currency",
+		Description: "Only available to the user and to callers with the orders:admin scope.",
+		Tags:        []string{"Orders"},
+		Parameters: []*openapi.Parameter{
+			userID,
+			actorID,
+			actorScopes,
+			{Name: "currency", In: "query", Description: "The currency to total the spend in.", Required: true, Schema: openapi.Ref("Currency")},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The user's spend.", doc.SchemaFor(models.SpendSummary{})),
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodGet, "/users/:id/data-export", &openapi.Operation{
+		OperationID: "exportUserData",
+		Summary:     "Export everything stored about a user",
+		Description: "Only available to callers with the orders:admin scope.",
+		Tags:        []string{"User data"},
+		Parameters:  []*openapi.Parameter{userID, actorID, actorScopes},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "A ZIP archive of the user's data.",
+				Content:     map[string]*openapi.MediaType{"application/zip": {Schema: &openapi.Schema{Type: "string", Format: "binary"}}},
+			},
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodPost, "/users/:id/erasure", &openapi.Operation{
+		OperationID: "eraseUserData",
+		Summary:     "Erase the personal data of a user",
+		Description: "Only available to callers with the orders:admin scope.",
+		Tags:        []string{"User data"},
+		Parameters:  []*openapi.Parameter{userID, actorID, actorScopes, idempotencyKey},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The user's data had already been erased.", doc.SchemaFor(models.UserErasure{})),
+			"201": jsonResponse("The user's data was erased.", doc.SchemaFor(models.UserErasure{})),
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodGet, "/users/:id/erasure", &openapi.Operation{
+		OperationID: "verifyUserErasure",
+		Summary:     "Check that nothing stored identifies a user",
+		Description: "Only available to callers with the orders:admin scope.",
+		Tags:        []string{"User data"},
+		Parameters:  []*openapi.Parameter{userID, actorID, actorScopes},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The result of the check.", doc.SchemaFor(models.ErasureVerification{})),
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodGet, "/restaurants/:id/orders", &openapi.Operation{
+		OperationID: "findOrdersForRestaurant",
+		Summary:     "List a page of the orders placed with a restaurant, newest first",
+		Description: "Available to the restaurant itself and to callers with the orders:admin scope. The customer is only shown to callers with the orders:customer_details or orders:admin scope.",
+		Tags:        []string{"Restaurants"},
+		Parameters: []*openapi.Parameter{
+			restaurantID,
+			actorID,
+			actorScopes,
+			ifNoneMatch,
+			queryParameter("from", "Only orders placed at or after this RFC 3339 time.", &openapi.Schema{Type: "string", Format: "date-time"}),
+			queryParameter("to", "Only orders placed before this RFC 3339 time.", &openapi.Schema{Type: "string", Format: "date-time"}),
+			queryParameter("status", "Only orders in these statuses, separated by commas.", &openapi.Schema{Type: "string"}),
+			queryParameter("limit", "The number of orders on the page.", &openapi.Schema{Type: "integer", Format: "int64"}),
+			queryParameter("cursor", "The next_cursor of the previous page.", &openapi.Schema{Type: "string"}),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": etagged("A page of the restaurant's orders.", doc.SchemaFor(models.RestaurantOrderPage{})),
+			"304": notModified,
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodGet, "/orders/:id", &openapi.Operation{
+		OperationID: "findOrder",
+		Summary:     "Get an order",
+		Description: "Users can only see their own orders unless they have the orders:admin scope.",
+		Tags:        []string{"Orders"},
+		Parameters:  []*openapi.Parameter{orderID, actorID, actorScopes, ifNoneMatch},
+		Responses: map[string]*openapi.Response{
+			"200": etagged("The order.", order),
+			"304": notModified,
+			"400": badRequest,
+			"401": unauthorized,
+			"404": notFound,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodPost, "/orders/:id/cancel", &openapi.Operation{
+		OperationID: "cancelOrder",
+		Summary:     "Cancel an order",
+		Description: "Orders can only be cancelled shortly after they are placed and before the restaurant starts preparing them.",
+		Tags:        []string{"Orders"},
+		Parameters:  []*openapi.Parameter{orderID, actorID, actorScopes, ifMatch, idempotencyKey},
+		RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSONContent(cancelRequest)},
+		Responses: map[string]*openapi.Response{
+			"200": etagged("The cancelled order.", order),
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"404": notFound,
+			"409": conflict,
+			"412": preconditionFailed,
+			"428": preconditionRequired,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodPost, "/orders/:id/refunds", &openapi.Operation{
+		OperationID: "refundOrder",
+		Summary:     "Refund all or part of an order",
+		Description: "Only available to callers with the orders:admin scope. Refunds must be in the currency of the order and may not add up to more than its total.",
+		Tags:        []string{"Orders"},
+		Parameters:  []*openapi.Parameter{orderID, actorID, actorScopes, ifMatch, idempotencyKey},
+		RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSONContent(refundRequest)},
+		Responses: map[string]*openapi.Response{
+			"201": etagged("The order with all of its refunds.", order),
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"404": notFound,
+			"409": conflict,
+			"412": preconditionFailed,
+			"428": preconditionRequired,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodDelete, "/orders/:id", &openapi.Operation{
+		OperationID: "deleteOrder",
+		Summary:     "Delete an order",
+		Description: "Only available to callers with the orders:admin scope.",
+		Tags:        []string{"Orders"},
+		Parameters:  []*openapi.Parameter{orderID, actorID, actorScopes, ifMatch},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "The order was deleted."},
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"404": notFound,
+			"412": preconditionFailed,
+			"428": preconditionRequired,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodGet, "/orders/:id/audit", &openapi.Operation{
+		OperationID: "findOrderAudit",
+		Summary:     "List the changes made to an order, oldest first",
+		Description: "Only available to callers with the orders:admin scope.",
+		Tags:        []string{"Orders"},
+		Parameters:  []*openapi.Parameter{orderID, actorID, actorScopes},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The audit log of the order.", &openapi.Schema{Type: "array", Items: doc.SchemaFor(models.OrderAuditEntry{})}),
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"404": notFound,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodPost, "/restaurants/:id/webhooks", &openapi.Operation{
+		OperationID: "createWebhookSubscription",
+		Summary:     "Subscribe a URL to the events about a restaurant's orders",
+		Description: "A secret for signing deliveries is generated when none is given. It is only returned by this request.",
+		Tags:        []string{"Webhooks"},
+		Parameters:  []*openapi.Parameter{restaurantID, actorID, actorScopes, idempotencyKey},
+		RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSONContent(subscriptionRequest)},
+		Responses: map[string]*openapi.Response{
+			"201": jsonResponse("The subscription with its secret.", subscriptionWithSecret),
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodGet, "/restaurants/:id/webhooks", &openapi.Operation{
+		OperationID: "findWebhookSubscriptionsForRestaurant",
+		Summary:     "List the webhook subscriptions of a restaurant",
+		Tags:        []string{"Webhooks"},
+		Parameters:  []*openapi.Parameter{restaurantID, actorID, actorScopes},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The restaurant's subscriptions.", &openapi.Schema{Type: "array", Items: subscription}),
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodGet, "/webhooks/:id", &openapi.Operation{
+		OperationID: "findWebhookSubscription",
+		Summary:     "Get a webhook subscription",
+		Tags:        []string{"Webhooks"},
+		Parameters:  []*openapi.Parameter{subscriptionID, actorID, actorScopes},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The subscription.", subscription),
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"404": notFound,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodPatch, "/webhooks/:id", &openapi.Operation{
+		OperationID: "updateWebhookSubscription",
+		Summary:     "Change a webhook subscription",
+		Description: "Fields that are left out of the body are not changed.",
+		Tags:        []string{"Webhooks"},
+		Parameters:  []*openapi.Parameter{subscriptionID, actorID, actorScopes, idempotencyKey},
+		RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSONContent(openapi.Ref("WebhookSubscriptionUpdate"))},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The changed subscription.", subscription),
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"404": notFound,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodDelete, "/webhooks/:id", &openapi.Operation{
+		OperationID: "deleteWebhookSubscription",
+		Summary:     "Delete a webhook subscription",
+		Tags:        []string{"Webhooks"},
+		Parameters:  []*openapi.Parameter{subscriptionID, actorID, actorScopes},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "The subscription was deleted."},
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"404": notFound,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodGet, "/webhooks/:id/deliveries", &openapi.Operation{
+		OperationID: "findWebhookDeliveries",
+		Summary:     "List the deliveries of a webhook subscription",
+		Tags:        []string{"Webhooks"},
+		Parameters:  []*openapi.Parameter{subscriptionID, actorID, actorScopes},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The subscription's delivery log.", &openapi.Schema{Type: "array", Items: delivery}),
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"404": notFound,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodPost, "/webhooks/:id/deliveries/:delivery_id/replay", &openapi.Operation{
+		OperationID: "replayWebhookDelivery",
+		Summary:     "Deliver an event to a webhook subscription again",
+		Tags:        []string{"Webhooks"},
+		Parameters: []*openapi.Parameter{
+			subscriptionID,
+			pathParameter("delivery_id", "The ID of the delivery to replay."),
+			actorID,
+			actorScopes,
+			idempotencyKey,
+		},
+		Responses: map[string]*openapi.Response{
+			"202": jsonResponse("The new delivery, which is made in the background.", delivery),
+			"400": badRequest,
+			"401": unauthorized,
+			"403": forbidden,
+			"404": notFound,
+			"500": internalError,
+		},
+	})
+	doc.AddOperation(http.MethodGet, "/openapi.json", &openapi.Operation{
+		OperationID: "openAPI",
+		Summary:     "Get this document",
+		Tags:        []string{"Documentation"},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The OpenAPI document of the service.", &openapi.Schema{Type: "object"}),
+		},
+	})
+	doc.AddOperation(http.MethodGet, "/docs", &openapi.Operation{
+		OperationID: "apiDocs",
+		Summary:     "Read this document as a web page",
+		Tags:        []string{"Documentation"},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "A page that renders the OpenAPI document.",
+				Content:     map[string]*openapi.MediaType{"text/html": {Schema: &openapi.Schema{Type: "string"}}},
+			},
+		},
+	})
+
+	return doc
+}
+
+var etagResponseHeader = &openapi.Header{
+	Description: "The version of the resource, for If-None-Match and If-Match.",
+	Schema:      &openapi.Schema{Type: "string"},
+}
+
+// defineEnum adds a component schema for a string type that only takes the
+// given values.
+func defineEnum(doc *openapi.Document, name string, v interface{}, values ...interface{}) {
+	schema := &openapi.Schema{Type: "string"}
+	for _, value := range values {
+		schema.Enum = append(schema.Enum, fmt.Sprint(value))
+	}
+
+	doc.DefineSchema(name, v, schema)
+}
+
+func pathParameter(name, description string) *openapi.Parameter {
+	return &openapi.Parameter{
+		Name:        name,
+		In:          "path",
+		Description: description,
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "integer", Format: "int64"},
+	}
+}
+
+func queryParameter(name, description string, schema *openapi.Schema) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func jsonResponse(description string, schema *openapi.Schema) *openapi.Response {
+	return &openapi.Response{Description: description, Content: openapi.JSONContent(schema)}
+}
+
+// etagged is a JSON response that carries the ETag of the resource.
+func etagged(description string, schema *openapi.Schema) *openapi.Response {
+	response := jsonResponse(description, schema)
+	response.Headers = map[string]*openapi.Header{etagHeader: etagResponseHeader}
+	return response
+}
+
+// apiDocsPage renders /openapi.json without loading anything else, so that
+// the docs work offline.
+const apiDocsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Order Service API</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 60em; color: #222; }
+h2 { border-bottom: 1px solid #ccc; padding-bottom: 0.2em; }
+details { border: 1px solid #ddd; border-radius: 4px; margin: 0.5em 0; padding: 0.5em; }
+summary { cursor: pointer; }
+.method { display: inline-block; width: 5em; font-weight: bold; text-transform: uppercase; }
+.path { font-family: monospace; }
+table { border-collapse: collapse; margin: 0.5em 0; }
+td, th { border: 1px solid #ddd; padding: 0.2em 0.5em; text-align: left; vertical-align: top; }
+pre { background: #f6f6f6; padding: 0.5em; overflow: auto; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1 id="title">Order Service API</h1>
+<pre id="description"></pre>
+<div id="operations">Loading /openapi.json…</div>
+<h2>Schemas</h2>
+<div id="schemas"></div>
+<script>
+function el(tag, text, className) {
+  var e = document.createElement(tag);
+  if (text !== undefined) e.textContent = text;
+  if (className) e.className = className;
+  return e;
+}
+
+function resolve(doc, value) {
+  while (value && value.$ref) {
+    var parts = value.$ref.split('/');
+    value = doc.components[parts[2]][parts[3]];
+  }
+  return value;
+}
+
+function schemaName(schema) {
+  if (!schema) return '';
+  if (schema.$ref) return schema.$ref.split('/').pop();
+  if (schema.type === 'array') return schemaName(schema.items) + '[]';
+  if (schema.allOf) return schema.allOf.map(schemaName).join(' & ');
+  return schema.type || 'any';
+}
+
+function render(doc) {
+  document.title = doc.info.title;
+  document.getElementById('title').textContent = doc.info.title + ' ' + doc.info.version;
+  document.getElementById('description').textContent = doc.info.description || '';
+
+  var operations = document.getElementById('operations');
+  operations.textContent = '';
+  var byTag = {};
+  Object.keys(doc.paths).sort().forEach(function(path) {
+    Object.keys(doc.paths[path]).forEach(function(method) {
+      var op = doc.paths[path][method];
+      var tag = (op.tags || ['Other'])[0];
+      (byTag[tag] = byTag[tag] || []).push({path: path, method: method, op: op});
+    });
+  });
+
+  Object.keys(byTag).sort().forEach(function(tag) {
+    operations.appendChild(el('h2', tag));
+    byTag[tag].forEach(function(entry) {
+      var details = el('details');
+      details.id = entry.op.operationId;
+      var summary = el('summary');
+      summary.appendChild(el('span', entry.method, 'method'));
+      summary.appendChild(el('span', entry.path, 'path'));
+      summary.appendChild(document.createTextNode(' ' + (entry.op.summary || '')));
+      details.appendChild(summary);
+      if (entry.op.description) details.appendChild(el('p', entry.op.description));
+
+      var params = (entry.op.parameters || []).map(function(p) { return resolve(doc, p); });
+      if (params.length) {
+        var table = el('table');
+        table.appendChild(el('tr')).innerHTML = '<th>Parameter</th><th>In</th><th>Type</th><th>Description</th>';
+        params.forEach(function(p) {
+          var row = table.appendChild(el('tr'));
+          row.appendChild(el('td', p.name + (p.required ? ' *' : '')));
+          row.appendChild(el('td', p['in']));
+          row.appendChild(el('td', schemaName(p.schema)));
+          row.appendChild(el('td', p.description || ''));
+        });
+        details.appendChild(table);
+      }
+
+      if (entry.op.requestBody) {
+        var body = entry.op.requestBody.content['application/json'];
+        details.appendChild(el('p', 'Body: ' + schemaName(body && body.schema)));
+      }
+
+      var responses = el('table');
+      responses.appendChild(el('tr')).innerHTML = '<th>Status</th><th>Body</th><th>Description</th>';
+      Object.keys(entry.op.responses).sort().forEach(function(status) {
+        var r = resolve(doc, entry.op.responses[status]);
+        var types = Object.keys(r.content || {}).map(function(type) {
+          return type === 'application/json' ? schemaName(r.content[type].schema) : type;
+        });
+        var row = responses.appendChild(el('tr'));
+        row.appendChild(el('td', status));
+        row.appendChild(el('td', types.join(', ')));
+        row.appendChild(el('td', r.description));
+      });
+      details.appendChild(responses);
+      operations.appendChild(details);
+    });
+  });
+
+  var schemas = document.getElementById('schemas');
+  Object.keys(doc.components.schemas).sort().forEach(function(name) {
+    var details = el('details');
+    details.id = 'schema-' + name;
+    details.appendChild(el('summary', name));
+    details.appendChild(el('pre', JSON.stringify(doc.components.schemas[name], null, 2)));
+    schemas.appendChild(details);
+  });
+}
+
+fetch('openapi.json')
+  .then(function(res) { return res.json(); })
+  .then(render)
+  .catch(function(err) {
+    document.getElementById('operations').textContent = 'Could not load /openapi.json: ' + err;
+  });
+</script>
+</body>
+</html>
+`
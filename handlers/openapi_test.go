@@ -0,0 +1,78 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/openapi"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OpenAPI", func() {
+	var app *gin.Engine
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		app = gin.New()
+		handlers.Routes(app)
+	})
+
+	It("describes every registered route", func() {
+		doc := handlers.APIDocument()
+		for _, route := range app.Routes() {
+			Expect(doc.Operation(route.Method, route.Path)).NotTo(BeNil(), "%s %s is missing from the OpenAPI document", route.Method, route.Path)
+		}
+	})
+
+	It("only describes registered routes", func() {
+		registered := make(map[string]bool)
+		for _, route := range app.Routes() {
+			registered[route.Method+" "+openapi.Path(route.Path)] = true
+		}
+
+		described := make(map[string]bool)
+		for path, item := range handlers.APIDocument().Paths {
+			for method := range *item {
+				described[strings.ToUpper(method)+" "+path] = true
+			}
+		}
+		Expect(described).To(Equal(registered))
+	})
+
+	It("describes the order and restaurant schemas", func() {
+		schemas := handlers.APIDocument().Components.Schemas
+		Expect(schemas).To(HaveKey("Order"))
+		Expect(schemas["Order"].Properties).To(HaveKey("restaurant"))
+		Expect(schemas["Order"].Properties).To(HaveKey("total"))
+		Expect(schemas["Order"].Properties).NotTo(HaveKey("user_id"))
+		Expect(schemas["Order"].Required).To(ContainElement("version"))
+		Expect(schemas["Order"].Required).NotTo(ContainElement("net_total"))
+		Expect(schemas).To(HaveKey("Restaurant"))
+		Expect(schemas["Restaurant"].Properties).To(HaveKey("name"))
+	})
+
+	It("serves the document at /openapi.json", func() {
+		res := httptest.NewRecorder()
+		app.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+		Expect(res.Code).To(Equal(http.StatusOK))
+		body := map[string]interface{}{}
+		Expect(json.Unmarshal(res.Body.Bytes(), &body)).To(Succeed())
+		Expect(body).To(HaveKeyWithValue("openapi", "3.0.3"))
+		Expect(body["paths"]).To(HaveKey("/orders/{id}/cancel"))
+	})
+
+	It("serves a docs page that loads the document", func() {
+		res := httptest.NewRecorder()
+		app.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+		Expect(res.Code).To(Equal(http.StatusOK))
+		Expect(res.Header().Get("Content-Type")).To(HavePrefix("text/html"))
+		Expect(res.Body.String()).To(ContainSubstring("openapi.json"))
+	})
+})
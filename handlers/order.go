@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,17 +18,200 @@ func FindOrdersForUser(c *gin.Context) {
 // FindOrdersForUser is the provider method that gets the orders for a user from
 // the user's ID.
 func (p *Provider) FindOrdersForUser(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.FindOrdersForUser")
+	defer span.End()
+
 	userID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.Status(http.StatusBadRequest)
 		return
 	}
 
-	orders, err := p.getOrderService().FindAllOrdersByUserID(userID)
+	orders, err := p.getOrderService().FindAllOrdersByUserID(ctx, userID)
 	if err != nil {
+		span.SetError(err)
 		c.Status(http.StatusInternalServerError)
 		return
 	}
 
+	etag := ordersETag(orders)
+	if notModified(c, etag) {
+		return
+	}
+
+	c.Header(etagHeader, etag)
 	c.JSON(http.StatusOK, orders)
 }
+
+// FindOrder gets a single order from its ID.
+func FindOrder(c *gin.Context) {
+	p := &Provider{}
+	p.FindOrder(c)
+}
+
+// FindOrder is the provider method that gets a single order from its ID. The
+// response carries the order's ETag and honours If-None-Match.
+func (p *Provider) FindOrder(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.FindOrder")
+	defer span.End()
+
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	actor := requestActor(c)
+	if actor == nil {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	order, err := p.getOrderService().FindOrderByID(ctx, orderID, actor)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	etag := orderETag(order)
+	if notModified(c, etag) {
+		return
+	}
+
+	c.Header(etagHeader, etag)
+	c.JSON(http.StatusOK, order)
+}
+
+// cancelOrderRequest is the body of a request to cancel an order.
+type cancelOrderRequest struct {
+	Reason models.CancellationReason `json:"reason"`
+}
+
+// CancelOrder cancels an order.
+func CancelOrder(c *gin.Context) {
+	p := &Provider{}
+	p.CancelOrder(c)
+}
+
+// CancelOrder is the provider method that cancels an order on behalf of the
+// caller. The If-Match header must hold the order's current ETag.
+func (p *Provider) CancelOrder(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.CancelOrder")
+	defer span.End()
+
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	actor := requestActor(c)
+	if actor == nil {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	req := cancelOrderRequest{}
+	if err = c.ShouldBindJSON(&req); err != nil {
+		renderBadRequest(c, "invalid_body", "the request body must be a JSON object with a reason")
+		return
+	}
+
+	order, err := p.getOrderService().CancelOrder(ctx, orderID, version, req.Reason, actor)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	c.Header(etagHeader, orderETag(order))
+	c.JSON(http.StatusOK, order)
+}
+
+// DeleteOrder soft deletes an order.
+func DeleteOrder(c *gin.Context) {
+	p := &Provider{}
+	p.DeleteOrder(c)
+}
+
+// DeleteOrder is the provider method that soft deletes an order. It is only
+// available to callers with the orders:admin scope, and the If-Match header
+// must hold the order's current ETag.
+func (p *Provider) DeleteOrder(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.DeleteOrder")
+	defer span.End()
+
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	actor := requestActor(c)
+	if actor == nil {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+	if !actor.HasScope(models.ScopeOrdersAdmin) {
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	if err = p.getOrderService().DeleteOrder(ctx, orderID, version, actor); err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// FindOrderAudit gets the audit log of an order.
+func FindOrderAudit(c *gin.Context) {
+	p := &Provider{}
+	p.FindOrderAudit(c)
+}
+
+// FindOrderAudit is the provider method that lists every change made to an
+// order, oldest first, with who made it and in which request. It is only
+// available to callers with the orders:admin scope.
+func (p *Provider) FindOrderAudit(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.FindOrderAudit")
+	defer span.End()
+
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	actor := requestActor(c)
+	if actor == nil {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+	if !actor.HasScope(models.ScopeOrdersAdmin) {
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	entries, err := p.getOrderService().FindOrderAudit(ctx, orderID, actor)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// lastEventIDHeader is sent by EventSource clients when they reconnect.
+const lastEventIDHeader = "Last-Event-ID"
+
+// defaultHeartbeatInterval is how often a comment is sent on an idle stream,
+// so that proxies do not close it and clients notice when it is gone.
+const defaultHeartbeatInterval = 15 * time.Second
+
+// StreamOrdersForUser streams updates to a user's orders as Server-Sent
+// Events.
+func StreamOrdersForUser(c *gin.Context) {
+	p := &Provider{}
+	p.StreamOrdersForUser(c)
+}
+
+// StreamOrdersForUser is the provider method that streams the events about a
+// user's orders as Server-Sent Events, with the event's ID, its type as the
+// event name and its JSON envelope as the data. A client that reconnects with
+// a Last-Event-ID header, or a last_event_id query parameter, is first sent
+// the events it missed. The stream stays open until the client disconnects.
+func (p *Provider) StreamOrdersForUser(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.StreamOrdersForUser")
+	defer span.End()
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	actor := requestActor(c)
+	if actor == nil {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	lastEventID := c.GetHeader(lastEventIDHeader)
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var afterID int64
+	if lastEventID != "" {
+		if afterID, err = strconv.ParseInt(lastEventID, 10, 64); err != nil {
+			renderBadRequest(c, "invalid_last_event_id", "the last event ID must be an event ID")
+			return
+		}
+	}
+
+	// Subscribe before reading the missed events, so that none are lost in
+	// between. Events that arrive both ways are only sent once.
+	subscription := p.getEventBroker().Subscribe(userID)
+	defer subscription.Close()
+
+	missed, err := p.getOrderService().FindOrderEventsForUser(ctx, userID, afterID, actor)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(p.getHeartbeatInterval())
+	defer heartbeat.Stop()
+
+	// Events are not always announced in ID order, so live events are only
+	// checked against the missed events that were sent.
+	replayed := make(map[int64]bool, len(missed))
+	for _, event := range missed {
+		replayed[event.ID] = true
+	}
+	send := func(w io.Writer, event *models.OutboxEvent) {
+		sse.Encode(w, sse.Event{
+			Id:    strconv.FormatInt(event.ID, 10),
+			Event: string(event.Type),
+			Data:  event,
+		})
+	}
+
+	opened := false
+	c.Stream(func(w io.Writer) bool {
+		if !opened {
+			opened = true
+			io.WriteString(w, ": stream opened\n\n")
+			for _, event := range missed {
+				send(w, event)
+			}
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case event, ok := <-subscription.Events():
+			if !ok {
+				// The stream fell too far behind. The client reconnects and
+				// resumes from the last event it was sent.
+				return false
+			}
+			if !replayed[event.ID] {
+				send(w, event)
+			}
+		case <-heartbeat.C:
+			io.WriteString(w, ": heartbeat\n\n")
+		}
+
+		return true
+	})
+}
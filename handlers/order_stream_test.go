@@ -0,0 +1,153 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/events"
+	"github.com/SebastianCoetzee/blog-order-service-example/handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_services"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StreamOrdersForUser", func() {
+	var (
+		ctrl             *gomock.Controller
+		mockOrderService *mock_services.MockOrderService
+		broker           *events.Broker
+		server           *httptest.Server
+		cancel           context.CancelFunc
+		res              *http.Response
+		lines            *bufio.Reader
+		actor            = &models.Actor{ID: "user:5", Scopes: []string{}}
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockOrderService = mock_services.NewMockOrderService(ctrl)
+		broker = events.NewBroker()
+
+		p := &handlers.Provider{}
+		p.SetOrderService(mockOrderService)
+		p.SetEventBroker(broker)
+		p.SetHeartbeatInterval(50 * time.Millisecond)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/users/:id/orders/stream", func(c *gin.Context) { p.StreamOrdersForUser(c) })
+		server = httptest.NewServer(router)
+	})
+
+	open := func(lastEventID string) {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/users/5/orders/stream", nil)
+		Expect(err).To(BeNil())
+		req = req.WithContext(ctx)
+		req.Header.Set("X-Actor-ID", actor.ID)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+
+		res, err = http.DefaultClient.Do(req)
+		Expect(err).To(BeNil())
+		lines = bufio.NewReader(res.Body)
+	}
+
+	// next returns the next event or comment block of the stream.
+	next := func() string {
+		block := ""
+		for {
+			line, err := lines.ReadString('\n')
+			Expect(err).To(BeNil())
+			if line == "\n" {
+				return block
+			}
+			block += line
+		}
+	}
+
+	Describe("when the actor may not see the user's orders", func() {
+		It("returns the error without opening a stream", func() {
+			mockOrderService.EXPECT().
+				FindOrderEventsForUser(gomock.Any(), gomock.Eq(5), gomock.Eq(int64(0)), gomock.Eq(actor)).
+				Return(nil, &services.Error{Kind: services.ErrorKindForbidden, Code: "forbidden"})
+
+			open("")
+			Expect(res.StatusCode).To(Equal(http.StatusForbidden))
+			Expect(broker.Subscribers(5)).To(Equal(0))
+		})
+	})
+
+	Describe("when a client resumes the stream", func() {
+		BeforeEach(func() {
+			mockOrderService.EXPECT().
+				FindOrderEventsForUser(gomock.Any(), gomock.Eq(5), gomock.Eq(int64(6)), gomock.Eq(actor)).
+				Return(models.OutboxEvents{
+					{ID: 7, Type: models.EventTypeOrderStatusChanged, OrderID: 3, UserID: 5, Payload: []byte(`{"status":"accepted"}`)},
+				}, error(nil))
+
+			open("6")
+		})
+
+		It("sends the missed events, then live ones, then heartbeats", func() {
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+			Expect(res.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+			Expect(next()).To(Equal(": stream opened\n"))
+			Expect(next()).To(HavePrefix("id:7\nevent:OrderStatusChanged\ndata:{"))
+
+			// The missed event is also relayed live, and only sent once.
+			broker.Publish(context.Background(), &models.OutboxEvent{ID: 7, Type: models.EventTypeOrderStatusChanged, UserID: 5})
+			broker.Publish(context.Background(), &models.OutboxEvent{ID: 8, Type: models.EventTypeOrderRefunded, UserID: 5, Payload: []byte(`{}`)})
+			broker.Publish(context.Background(), &models.OutboxEvent{ID: 9, Type: models.EventTypeOrderRefunded, UserID: 6, Payload: []byte(`{}`)})
+			Expect(next()).To(HavePrefix("id:8\nevent:OrderRefunded\n"))
+
+			Expect(next()).To(Equal(": heartbeat\n"))
+		})
+
+		It("unsubscribes when the client disconnects", func() {
+			Expect(next()).To(Equal(": stream opened\n"))
+			Expect(broker.Subscribers(5)).To(Equal(1))
+
+			cancel()
+			Eventually(func() int { return broker.Subscribers(5) }).Should(Equal(0))
+		})
+	})
+
+	AfterEach(func() {
+		if cancel != nil {
+			cancel()
+		}
+		res.Body.Close()
+		server.Close()
+		ctrl.Finish()
+	})
+})
+
+var _ = Describe("StreamOrdersForUser with a malformed Last-Event-ID", func() {
+	It("rejects the request", func() {
+		p := &handlers.Provider{}
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/users/:id/orders/stream", func(c *gin.Context) { p.StreamOrdersForUser(c) })
+
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/users/5/orders/stream", nil)
+		req.Header.Set("X-Actor-ID", "user:5")
+		req.Header.Set("Last-Event-ID", "abc")
+		router.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(strings.Contains(recorder.Body.String(), "invalid_last_event_id")).To(BeTrue())
+	})
+})
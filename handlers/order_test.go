@@ -1,8 +1,11 @@
 package handlers_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 
@@ -40,6 +43,7 @@ var _ = Describe("FindOrdersForUser", func() {
 	Describe("with an invalid ID", func() {
 		BeforeEach(func() {
 			mockContext := mock_handlers.NewMockContext(ctrl)
+			mockContext.EXPECT().Value(gomock.Eq(0)).Return(nil)
 			mockContext.EXPECT().Param(gomock.Eq("id")).Return("invalid_id")
 			mockContext.EXPECT().Status(gomock.Eq(400))
 			c = mockContext
@@ -54,12 +58,13 @@ var _ = Describe("FindOrdersForUser", func() {
 		Describe("when an error is returned from the OrderService", func() {
 			BeforeEach(func() {
 				mockContext := mock_handlers.NewMockContext(ctrl)
+				mockContext.EXPECT().Value(gomock.Eq(0)).Return(nil)
 				mockContext.EXPECT().Param(gomock.Eq("id")).Return("5")
 				mockContext.EXPECT().Status(gomock.Eq(500))
 				c = mockContext
 
 				mockOrderService := mock_services.NewMockOrderService(ctrl)
-				mockOrderService.EXPECT().FindAllOrdersByUserID(gomock.Eq(5)).Return(nil, errors.New("some error"))
+				mockOrderService.EXPECT().FindAllOrdersByUserID(gomock.Any(), gomock.Eq(5)).Return(nil, errors.New("some error"))
 				orderService = mockOrderService
 			})
 
@@ -80,12 +85,15 @@ var _ = Describe("FindOrdersForUser", func() {
 				})
 
 				mockContext := mock_handlers.NewMockContext(ctrl)
+				mockContext.EXPECT().Value(gomock.Eq(0)).Return(nil)
 				mockContext.EXPECT().Param(gomock.Eq("id")).Return("5")
+				mockContext.EXPECT().GetHeader(gomock.Eq("If-None-Match")).Return("")
+				mockContext.EXPECT().Header(gomock.Eq("ETag"), gomock.Any())
 				mockContext.EXPECT().JSON(gomock.Eq(200), gomock.Eq(orders))
 				c = mockContext
 
 				mockOrderService := mock_services.NewMockOrderService(ctrl)
-				mockOrderService.EXPECT().FindAllOrdersByUserID(gomock.Eq(5)).Return(orders, error(nil))
+				mockOrderService.EXPECT().FindAllOrdersByUserID(gomock.Any(), gomock.Eq(5)).Return(orders, error(nil))
 				orderService = mockOrderService
 			})
 
@@ -99,3 +107,318 @@ var _ = Describe("FindOrdersForUser", func() {
 		ctrl.Finish()
 	})
 })
+
+var _ = Describe("CancelOrder", func() {
+	var (
+		mockContext      *mock_handlers.MockContext
+		mockOrderService *mock_services.MockOrderService
+		p                *handlers.Provider
+		ctrl             *gomock.Controller
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockContext = mock_handlers.NewMockContext(ctrl)
+		mockContext.EXPECT().Value(gomock.Eq(0)).Return(nil)
+		mockOrderService = mock_services.NewMockOrderService(ctrl)
+
+		p = &handlers.Provider{}
+		p.SetOrderService(mockOrderService)
+	})
+
+	// bindReason makes ShouldBindJSON decode a body with the given reason.
+	bindReason := func(reason string) {
+		mockContext.EXPECT().ShouldBindJSON(gomock.Any()).DoAndReturn(func(obj interface{}) error {
+			return json.Unmarshal([]byte(`{"reason":"`+reason+`"}`), obj)
+		})
+	}
+
+	Describe("without an authenticated actor", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().Param(gomock.Eq("id")).Return("3")
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("")
+			mockContext.EXPECT().Status(gomock.Eq(401))
+		})
+
+		It("should return a 401", func() {
+			p.CancelOrder(mockContext)
+		})
+	})
+
+	Describe("with an authenticated actor", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().Param(gomock.Eq("id")).Return("3")
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("user:5")
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-Scopes")).Return("")
+		})
+
+		Describe("without an If-Match header", func() {
+			BeforeEach(func() {
+				mockContext.EXPECT().GetHeader(gomock.Eq("If-Match")).Return("")
+				mockContext.EXPECT().JSON(gomock.Eq(428), gomock.Any())
+			})
+
+			It("should return a 428", func() {
+				p.CancelOrder(mockContext)
+			})
+		})
+
+		Describe("when the cancellation window has passed", func() {
+			var body interface{}
+
+			BeforeEach(func() {
+				mockContext.EXPECT().GetHeader(gomock.Eq("If-Match")).Return(`"v1"`)
+				bindReason("changed_mind")
+				placedAt := time.Date(2019, 4, 10, 12, 0, 0, 0, time.UTC)
+				mockOrderService.EXPECT().
+					CancelOrder(gomock.Any(), gomock.Eq(3), gomock.Eq(1), gomock.Eq(models.CancellationReasonChangedMind), gomock.Eq(&models.Actor{ID: "user:5", Scopes: []string{}})).
+					Return(nil, &services.Error{
+						Kind:    services.ErrorKindConflict,
+						Code:    "cancellation_window_expired",
+						Message: "order 3 can no longer be cancelled",
+						Details: map[string]interface{}{"cancellable_until": placedAt.Add(15 * time.Minute)},
+					})
+				mockContext.EXPECT().JSON(gomock.Eq(409), gomock.Any()).Do(func(code int, obj interface{}) {
+					body = obj
+				})
+			})
+
+			It("should return a 409 with a structured error", func() {
+				p.CancelOrder(mockContext)
+
+				encoded, err := json.Marshal(body)
+				Expect(err).To(BeNil())
+				Expect(encoded).To(MatchJSON(`{
+					"error": {
+						"code": "cancellation_window_expired",
+						"message": "order 3 can no longer be cancelled",
+						"details": {"cancellable_until": "2019-04-10T12:15:00Z"}
+					}
+				}`))
+			})
+		})
+
+		Describe("when the order is cancelled", func() {
+			var order *models.Order
+
+			BeforeEach(func() {
+				order = &models.Order{ID: 3, Status: models.OrderStatusCancelled, Version: 2}
+				mockContext.EXPECT().GetHeader(gomock.Eq("If-Match")).Return(`"v1"`)
+				bindReason("changed_mind")
+				mockOrderService.EXPECT().
+					CancelOrder(gomock.Any(), gomock.Eq(3), gomock.Eq(1), gomock.Eq(models.CancellationReasonChangedMind), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, id, version int, reason models.CancellationReason, actor *models.Actor) (*models.Order, error) {
+						return order, nil
+					})
+				mockContext.EXPECT().Header(gomock.Eq("ETag"), gomock.Eq(`"v2"`))
+				mockContext.EXPECT().JSON(gomock.Eq(200), gomock.Eq(order))
+			})
+
+			It("should return a 200 with the cancelled order", func() {
+				p.CancelOrder(mockContext)
+			})
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
+
+var _ = Describe("FindOrder", func() {
+	var (
+		mockContext      *mock_handlers.MockContext
+		mockOrderService *mock_services.MockOrderService
+		p                *handlers.Provider
+		ctrl             *gomock.Controller
+		order            *models.Order
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockContext = mock_handlers.NewMockContext(ctrl)
+		mockContext.EXPECT().Value(gomock.Eq(0)).Return(nil)
+		mockContext.EXPECT().Param(gomock.Eq("id")).Return("3")
+		mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("user:5")
+		mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-Scopes")).Return("")
+		mockOrderService = mock_services.NewMockOrderService(ctrl)
+
+		order = &models.Order{ID: 3, UserID: 5, Version: 4}
+		mockOrderService.EXPECT().FindOrderByID(gomock.Any(), gomock.Eq(3), gomock.Any()).Return(order, error(nil))
+
+		p = &handlers.Provider{}
+		p.SetOrderService(mockOrderService)
+	})
+
+	Describe("when the client has the current version", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().GetHeader(gomock.Eq("If-None-Match")).Return(`"v3", "v4"`)
+			mockContext.EXPECT().Header(gomock.Eq("ETag"), gomock.Eq(`"v4"`))
+			mockContext.EXPECT().Status(gomock.Eq(304))
+		})
+
+		It("should return a 304", func() {
+			p.FindOrder(mockContext)
+		})
+	})
+
+	Describe("when the client has an older version", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().GetHeader(gomock.Eq("If-None-Match")).Return(`"v3"`)
+			mockContext.EXPECT().Header(gomock.Eq("ETag"), gomock.Eq(`"v4"`))
+			mockContext.EXPECT().JSON(gomock.Eq(200), gomock.Eq(order))
+		})
+
+		It("should return a 200 with the order and its ETag", func() {
+			p.FindOrder(mockContext)
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
+
+var _ = Describe("DeleteOrder", func() {
+	var (
+		mockContext      *mock_handlers.MockContext
+		mockOrderService *mock_services.MockOrderService
+		p                *handlers.Provider
+		ctrl             *gomock.Controller
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockContext = mock_handlers.NewMockContext(ctrl)
+		mockContext.EXPECT().Value(gomock.Eq(0)).Return(nil)
+		mockContext.EXPECT().Param(gomock.Eq("id")).Return("3")
+		mockOrderService = mock_services.NewMockOrderService(ctrl)
+
+		p = &handlers.Provider{}
+		p.SetOrderService(mockOrderService)
+	})
+
+	Describe("when the actor is not a support agent", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("user:5")
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-Scopes")).Return("")
+			mockContext.EXPECT().Status(gomock.Eq(403))
+		})
+
+		It("should return a 403", func() {
+			p.DeleteOrder(mockContext)
+		})
+	})
+
+	Describe("when the actor is a support agent", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("agent:1")
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-Scopes")).Return("orders:admin")
+			mockContext.EXPECT().GetHeader(gomock.Eq("If-Match")).Return(`"v2"`)
+		})
+
+		Describe("and the order is deleted", func() {
+			BeforeEach(func() {
+				mockOrderService.EXPECT().
+					DeleteOrder(gomock.Any(), gomock.Eq(3), gomock.Eq(2), gomock.Eq(&models.Actor{ID: "agent:1", Scopes: []string{"orders:admin"}})).
+					Return(error(nil))
+				mockContext.EXPECT().Status(gomock.Eq(204))
+			})
+
+			It("should return a 204", func() {
+				p.DeleteOrder(mockContext)
+			})
+		})
+
+		Describe("and the order does not exist", func() {
+			BeforeEach(func() {
+				mockOrderService.EXPECT().
+					DeleteOrder(gomock.Any(), gomock.Eq(3), gomock.Eq(2), gomock.Any()).
+					Return(&services.Error{Kind: services.ErrorKindNotFound, Code: "order_not_found", Message: "order with ID 3 not found"})
+				mockContext.EXPECT().JSON(gomock.Eq(404), gomock.Any())
+			})
+
+			It("should return a 404", func() {
+				p.DeleteOrder(mockContext)
+			})
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
+
+var _ = Describe("FindOrderAudit", func() {
+	var (
+		mockContext      *mock_handlers.MockContext
+		mockOrderService *mock_services.MockOrderService
+		p                *handlers.Provider
+		ctrl             *gomock.Controller
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockContext = mock_handlers.NewMockContext(ctrl)
+		mockContext.EXPECT().Value(gomock.Eq(0)).Return(nil)
+		mockContext.EXPECT().Param(gomock.Eq("id")).Return("3")
+		mockOrderService = mock_services.NewMockOrderService(ctrl)
+
+		p = &handlers.Provider{}
+		p.SetOrderService(mockOrderService)
+	})
+
+	Describe("when the actor is not a support agent", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("user:5")
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-Scopes")).Return("")
+			mockContext.EXPECT().Status(gomock.Eq(403))
+		})
+
+		It("should return a 403", func() {
+			p.FindOrderAudit(mockContext)
+		})
+	})
+
+	Describe("when the actor is a support agent", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("agent:1")
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-Scopes")).Return("orders:admin")
+		})
+
+		Describe("and the order has been changed", func() {
+			var entries models.OrderAuditEntries
+
+			BeforeEach(func() {
+				entries = models.OrderAuditEntries{
+					{ID: 1, OrderID: 3, ActorID: "user:5", Action: models.OrderAuditActionCancel},
+				}
+				mockOrderService.EXPECT().
+					FindOrderAudit(gomock.Any(), gomock.Eq(3), gomock.Eq(&models.Actor{ID: "agent:1", Scopes: []string{"orders:admin"}})).
+					Return(entries, nil)
+				mockContext.EXPECT().JSON(gomock.Eq(200), gomock.Eq(entries))
+			})
+
+			It("should return the audit log", func() {
+				p.FindOrderAudit(mockContext)
+			})
+		})
+
+		Describe("and the order does not exist", func() {
+			BeforeEach(func() {
+				mockOrderService.EXPECT().
+					FindOrderAudit(gomock.Any(), gomock.Eq(3), gomock.Any()).
+					Return(nil, &services.Error{Kind: services.ErrorKindNotFound, Code: "order_not_found", Message: "order with ID 3 not found"})
+				mockContext.EXPECT().JSON(gomock.Eq(404), gomock.Any())
+			})
+
+			It("should return a 404", func() {
+				p.FindOrderAudit(mockContext)
+			})
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
@@ -1,13 +1,21 @@
 package handlers
 
 import (
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/events"
 	"github.com/SebastianCoetzee/blog-order-service-example/services"
 )
 
 // Provider is the endpoint provider that holds the dependencies for the
 // endpoints.
 type Provider struct {
-	orderService services.OrderService
+	orderService       services.OrderService
+	idempotencyService services.IdempotencyService
+	webhookService     services.WebhookService
+	userDataService    services.UserDataService
+	eventBroker        *events.Broker
+	heartbeatInterval  time.Duration
 }
 
 // SetOrderService sets the OrderService dependency on the Provider.
@@ -23,3 +31,74 @@ func (p *Provider) getOrderService() services.OrderService {
 	p.orderService = services.NewOrderService()
 	return p.orderService
 }
+
+// SetIdempotencyService sets the IdempotencyService dependency on the
+// Provider.
+func (p *Provider) SetIdempotencyService(s services.IdempotencyService) {
+	p.idempotencyService = s
+}
+
+func (p *Provider) getIdempotencyService() services.IdempotencyService {
+	if p.idempotencyService != nil {
+		return p.idempotencyService
+	}
+
+	p.idempotencyService = services.NewIdempotencyService()
+	return p.idempotencyService
+}
+
+// SetWebhookService sets the WebhookService dependency on the Provider.
+func (p *Provider) SetWebhookService(s services.WebhookService) {
+	p.webhookService = s
+}
+
+func (p *Provider) getWebhookService() services.WebhookService {
+	if p.webhookService != nil {
+		return p.webhookService
+	}
+
+	p.webhookService = services.NewWebhookService()
+	return p.webhookService
+}
+
+// SetUserDataService sets the UserDataService dependency on the Provider.
+func (p *Provider) SetUserDataService(s services.UserDataService) {
+	p.userDataService = s
+}
+
+func (p *Provider) getUserDataService() services.UserDataService {
+	if p.userDataService != nil {
+		return p.userDataService
+	}
+
+	p.userDataService = services.NewUserDataService()
+	return p.userDataService
+}
+
+// SetEventBroker sets the broker that order streams subscribe to.
+func (p *Provider) SetEventBroker(b *events.Broker) {
+	p.eventBroker = b
+}
+
+func (p *Provider) getEventBroker() *events.Broker {
+	if p.eventBroker != nil {
+		return p.eventBroker
+	}
+
+	p.eventBroker = events.DefaultBroker()
+	return p.eventBroker
+}
+
+// SetHeartbeatInterval overrides how often a heartbeat is sent on idle
+// streams.
+func (p *Provider) SetHeartbeatInterval(d time.Duration) {
+	p.heartbeatInterval = d
+}
+
+func (p *Provider) getHeartbeatInterval() time.Duration {
+	if p.heartbeatInterval > 0 {
+		return p.heartbeatInterval
+	}
+
+	return defaultHeartbeatInterval
+}
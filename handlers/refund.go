@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// RefundOrder refunds all or part of an order.
+func RefundOrder(c *gin.Context) {
+	p := &Provider{}
+	p.RefundOrder(c)
+}
+
+// RefundOrder is the provider method that records a refund against an order.
+// It is only available to callers with the orders:admin scope, and the
+// If-Match header must hold the order's current ETag.
+func (p *Provider) RefundOrder(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.RefundOrder")
+	defer span.End()
+
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	actor := requestActor(c)
+	if actor == nil {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+	if !actor.HasScope(models.ScopeOrdersAdmin) {
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	req := services.RefundRequest{}
+	if err = c.ShouldBindJSON(&req); err != nil {
+		renderBadRequest(c, "invalid_body", "the request body must be a JSON object with an amount and a reason")
+		return
+	}
+
+	order, err := p.getOrderService().RefundOrder(ctx, orderID, version, req, actor)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	c.Header(etagHeader, orderETag(order))
+	c.JSON(http.StatusCreated, order)
+}
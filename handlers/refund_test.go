@@ -0,0 +1,80 @@
+package handlers_test
+
+import (
+	"encoding/json"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_services"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	. "github.com/onsi/ginkgo"
+)
+
+var _ = Describe("RefundOrder", func() {
+	var (
+		mockContext      *mock_handlers.MockContext
+		mockOrderService *mock_services.MockOrderService
+		p                *handlers.Provider
+		ctrl             *gomock.Controller
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockContext = mock_handlers.NewMockContext(ctrl)
+		mockContext.EXPECT().Value(gomock.Eq(0)).Return(nil)
+		mockContext.EXPECT().Param(gomock.Eq("id")).Return("3")
+		mockOrderService = mock_services.NewMockOrderService(ctrl)
+
+		p = &handlers.Provider{}
+		p.SetOrderService(mockOrderService)
+	})
+
+	Describe("when the actor is not a support agent", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("user:5")
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-Scopes")).Return("")
+			mockContext.EXPECT().Status(gomock.Eq(403))
+		})
+
+		It("should return a 403", func() {
+			p.RefundOrder(mockContext)
+		})
+	})
+
+	Describe("when the actor is a support agent", func() {
+		var order *models.Order
+
+		BeforeEach(func() {
+			order = &models.Order{ID: 3, Total: models.NewMoney(2500, models.GBP), Version: 3}
+
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("agent:1")
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-Scopes")).Return("orders:admin")
+			mockContext.EXPECT().GetHeader(gomock.Eq("If-Match")).Return(`"v2"`)
+			mockContext.EXPECT().ShouldBindJSON(gomock.Any()).DoAndReturn(func(obj interface{}) error {
+				return json.Unmarshal([]byte(`{"amount":{"minor_units":1000,"currency":"GBP"},"reason":"Missing item"}`), obj)
+			})
+			mockOrderService.EXPECT().
+				RefundOrder(
+					gomock.Any(),
+					gomock.Eq(3),
+					gomock.Eq(2),
+					gomock.Eq(services.RefundRequest{Amount: models.NewMoney(1000, models.GBP), Reason: "Missing item"}),
+					gomock.Eq(&models.Actor{ID: "agent:1", Scopes: []string{"orders:admin"}}),
+				).
+				Return(order, error(nil))
+			mockContext.EXPECT().Header(gomock.Eq("ETag"), gomock.Eq(`"v3"`))
+			mockContext.EXPECT().JSON(gomock.Eq(201), gomock.Eq(order))
+		})
+
+		It("should return a 201 with the refunded order", func() {
+			p.RefundOrder(mockContext)
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
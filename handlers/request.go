@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+)
+
+// The headers that the API gateway uses to identify the caller of a request.
+const (
+	actorIDHeader     = "X-Actor-ID"
+	actorScopesHeader = "X-Actor-Scopes"
+)
+
+// requestContext returns the context.Context of the HTTP request behind c.
+// gin.Context exposes the underlying *http.Request through Value(0).
+func requestContext(c Context) context.Context {
+	if req, ok := c.Value(0).(*http.Request); ok {
+		return req.Context()
+	}
+
+	return context.Background()
+}
+
+// requestActor returns the caller of the request, or nil when the request is
+// not authenticated.
+func requestActor(c Context) *models.Actor {
+	id := c.GetHeader(actorIDHeader)
+	if id == "" {
+		return nil
+	}
+
+	return &models.Actor{
+		ID:     id,
+		Scopes: strings.Fields(c.GetHeader(actorScopesHeader)),
+	}
+}
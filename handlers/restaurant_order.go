@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// FindOrdersForRestaurant gets a page of the orders placed with a restaurant.
+func FindOrdersForRestaurant(c *gin.Context) {
+	p := &Provider{}
+	p.FindOrdersForRestaurant(c)
+}
+
+// FindOrdersForRestaurant is the provider method that gets a page of the
+// orders placed with a restaurant. The orders can be filtered with the from
+// and to (RFC 3339 times), status (comma-separated) and limit query
+// parameters, and the next page is requested with the cursor query parameter.
+func (p *Provider) FindOrdersForRestaurant(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.FindOrdersForRestaurant")
+	defer span.End()
+
+	restaurantID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	actor := requestActor(c)
+	if actor == nil {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	filter, code, message := restaurantOrderFilter(c)
+	if code != "" {
+		renderBadRequest(c, code, message)
+		return
+	}
+	filter.RestaurantID = restaurantID
+
+	page, err := p.getOrderService().FindOrdersForRestaurant(ctx, filter, actor)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	orders := make(models.Orders, 0, len(page.Orders))
+	for _, order := range page.Orders {
+		orders = append(orders, order.Order)
+	}
+
+	etag := ordersETag(orders)
+	if notModified(c, etag) {
+		return
+	}
+
+	c.Header(etagHeader, etag)
+	c.JSON(http.StatusOK, page)
+}
+
+// restaurantOrderFilter reads the filter from the query parameters. It
+// returns an error code and message when a parameter is invalid.
+func restaurantOrderFilter(c Context) (models.RestaurantOrderFilter, string, string) {
+	filter := models.RestaurantOrderFilter{}
+
+	var err error
+	if filter.From, err = queryTime(c, "from"); err != nil {
+		return filter, "invalid_from", "the from query parameter must be an RFC 3339 time"
+	}
+	if filter.To, err = queryTime(c, "to"); err != nil {
+		return filter, "invalid_to", "the to query parameter must be an RFC 3339 time"
+	}
+
+	if value := c.Query("status"); value != "" {
+		for _, s := range strings.Split(value, ",") {
+			status, err := models.ParseOrderStatus(strings.TrimSpace(s))
+			if err != nil {
+				return filter, "invalid_status", err.Error()
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return filter, "invalid_limit", "the limit query parameter must be a positive integer"
+		}
+		filter.Limit = limit
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := models.ParseOrderCursor(value)
+		if err != nil {
+			return filter, "invalid_cursor", "the cursor query parameter must be a next_cursor from a previous page"
+		}
+		filter.After = cursor
+	}
+
+	return filter, "", ""
+}
+
+// queryTime parses an optional RFC 3339 time query parameter.
+func queryTime(c Context, param string) (*time.Time, error) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
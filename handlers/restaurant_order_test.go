@@ -0,0 +1,100 @@
+package handlers_test
+
+import (
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_services"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	. "github.com/onsi/ginkgo"
+)
+
+var _ = Describe("FindOrdersForRestaurant", func() {
+	var (
+		mockContext      *mock_handlers.MockContext
+		mockOrderService *mock_services.MockOrderService
+		p                *handlers.Provider
+		ctrl             *gomock.Controller
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockContext = mock_handlers.NewMockContext(ctrl)
+		mockContext.EXPECT().Value(gomock.Eq(0)).Return(nil)
+		mockContext.EXPECT().Param(gomock.Eq("id")).Return("8")
+		mockOrderService = mock_services.NewMockOrderService(ctrl)
+
+		p = &handlers.Provider{}
+		p.SetOrderService(mockOrderService)
+	})
+
+	Describe("when the caller is not authenticated", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("")
+			mockContext.EXPECT().Status(gomock.Eq(401))
+		})
+
+		It("should return a 401", func() {
+			p.FindOrdersForRestaurant(mockContext)
+		})
+	})
+
+	Describe("when the caller is the restaurant", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("restaurant:8")
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-Scopes")).Return("")
+			mockContext.EXPECT().Query(gomock.Eq("from")).Return("2019-04-01T00:00:00Z")
+			mockContext.EXPECT().Query(gomock.Eq("to")).Return("")
+		})
+
+		Describe("and a status is not valid", func() {
+			BeforeEach(func() {
+				mockContext.EXPECT().Query(gomock.Eq("status")).Return("placed,eaten")
+				mockContext.EXPECT().JSON(gomock.Eq(400), gomock.Any())
+			})
+
+			It("should return a 400", func() {
+				p.FindOrdersForRestaurant(mockContext)
+			})
+		})
+
+		Describe("and the filter is valid", func() {
+			var page *models.RestaurantOrderPage
+
+			BeforeEach(func() {
+				from := time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)
+				page = &models.RestaurantOrderPage{Orders: []*models.RestaurantOrder{}}
+
+				mockContext.EXPECT().Query(gomock.Eq("status")).Return("placed, accepted")
+				mockContext.EXPECT().Query(gomock.Eq("limit")).Return("10")
+				mockContext.EXPECT().Query(gomock.Eq("cursor")).Return("")
+				mockOrderService.EXPECT().
+					FindOrdersForRestaurant(
+						gomock.Any(),
+						gomock.Eq(models.RestaurantOrderFilter{
+							RestaurantID: 8,
+							From:         &from,
+							Statuses:     []models.OrderStatus{models.OrderStatusPlaced, models.OrderStatusAccepted},
+							Limit:        10,
+						}),
+						gomock.Eq(&models.Actor{ID: "restaurant:8", Scopes: []string{}}),
+					).
+					Return(page, error(nil))
+				mockContext.EXPECT().GetHeader(gomock.Eq("If-None-Match")).Return("")
+				mockContext.EXPECT().Header(gomock.Eq("ETag"), gomock.Any())
+				mockContext.EXPECT().JSON(gomock.Eq(200), gomock.Eq(page))
+			})
+
+			It("should return a 200 with the page of orders", func() {
+				p.FindOrdersForRestaurant(mockContext)
+			})
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
@@ -0,0 +1,31 @@
+package handlers
+
+import "github.com/gin-gonic/gin"
+
+// Routes registers the middleware and endpoints of the service on app.
+func Routes(app *gin.Engine) {
+	app.Use(TraceRequests)
+	app.Use(Idempotency)
+	app.GET("/users/:id/orders", FindOrdersForUser)
+	app.GET("/users/:id/orders/stats", FindOrderStatsForUser)
+	app.GET("/users/:id/orders/stream", StreamOrdersForUser)
+	app.GET("/users/:id/spend", SpendForUser)
+	app.GET("/users/:id/data-export", ExportUserData)
+	app.POST("/users/:id/erasure", EraseUserData)
+	app.GET("/users/:id/erasure", VerifyUserErasure)
+	app.GET("/restaurants/:id/orders", FindOrdersForRestaurant)
+	app.GET("/orders/:id", FindOrder)
+	app.POST("/orders/:id/cancel", CancelOrder)
+	app.POST("/orders/:id/refunds", RefundOrder)
+	app.DELETE("/orders/:id", DeleteOrder)
+	app.GET("/orders/:id/audit", FindOrderAudit)
+	app.POST("/restaurants/:id/webhooks", CreateWebhookSubscription)
+	app.GET("/restaurants/:id/webhooks", FindWebhookSubscriptionsForRestaurant)
+	app.GET("/webhooks/:id", FindWebhookSubscription)
+	app.PATCH("/webhooks/:id", UpdateWebhookSubscription)
+	app.DELETE("/webhooks/:id", DeleteWebhookSubscription)
+	app.GET("/webhooks/:id/deliveries", FindWebhookDeliveries)
+	app.POST("/webhooks/:id/deliveries/:delivery_id/replay", ReplayWebhookDelivery)
+	app.GET("/openapi.json", OpenAPI)
+	app.GET("/docs", APIDocs)
+}
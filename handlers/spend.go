@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// SpendForUser gets the total a user has spent in a single currency.
+func SpendForUser(c *gin.Context) {
+	p := &Provider{}
+	p.SpendForUser(c)
+}
+
+// SpendForUser is the provider method that gets the total a user has spent,
+// converted to the currency given in the currency query parameter. Only the
+// user and admins may see it.
+func (p *Provider) SpendForUser(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.SpendForUser")
+	defer span.End()
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	actor := requestActor(c)
+	if actor == nil {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	currency, err := models.ParseCurrency(c.Query("currency"))
+	if err != nil {
+		renderBadRequest(c, "unknown_currency", "the currency query parameter must be a supported ISO 4217 code")
+		return
+	}
+
+	summary, err := p.getOrderService().SpendForUser(ctx, userID, currency, actor)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,104 @@
+package handlers_test
+
+import (
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_services"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	. "github.com/onsi/ginkgo"
+)
+
+var _ = Describe("SpendForUser", func() {
+	var (
+		mockContext      *mock_handlers.MockContext
+		mockOrderService *mock_services.MockOrderService
+		p                *handlers.Provider
+		ctrl             *gomock.Controller
+
+		actor = &models.Actor{ID: "user:5", Scopes: []string{}}
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockContext = mock_handlers.NewMockContext(ctrl)
+		mockContext.EXPECT().Value(gomock.Eq(0)).Return(nil)
+		mockContext.EXPECT().Param(gomock.Eq("id")).Return("5")
+		mockOrderService = mock_services.NewMockOrderService(ctrl)
+
+		p = &handlers.Provider{}
+		p.SetOrderService(mockOrderService)
+	})
+
+	Describe("without an authenticated actor", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("")
+			mockContext.EXPECT().Status(gomock.Eq(401))
+		})
+
+		It("should return a 401", func() {
+			p.SpendForUser(mockContext)
+		})
+	})
+
+	Describe("with an authenticated actor", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("user:5")
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-Scopes")).Return("")
+		})
+
+		Describe("when the currency is not supported", func() {
+			BeforeEach(func() {
+				mockContext.EXPECT().Query(gomock.Eq("currency")).Return("XYZ")
+				mockContext.EXPECT().JSON(gomock.Eq(400), gomock.Any())
+			})
+
+			It("should return a 400", func() {
+				p.SpendForUser(mockContext)
+			})
+		})
+
+		Describe("when the currency is supported", func() {
+			var summary *models.SpendSummary
+
+			BeforeEach(func() {
+				summary = &models.SpendSummary{
+					UserID:     5,
+					Currency:   models.EUR,
+					Total:      models.NewMoney(4200, models.EUR),
+					OrderCount: 2,
+				}
+
+				mockContext.EXPECT().Query(gomock.Eq("currency")).Return("eur")
+				mockOrderService.EXPECT().
+					SpendForUser(gomock.Any(), gomock.Eq(5), gomock.Eq(models.EUR), gomock.Eq(actor)).
+					Return(summary, error(nil))
+				mockContext.EXPECT().JSON(gomock.Eq(200), gomock.Eq(summary))
+			})
+
+			It("should return a 200 with the spend summary", func() {
+				p.SpendForUser(mockContext)
+			})
+		})
+
+		Describe("when the actor may not see the user's spend", func() {
+			BeforeEach(func() {
+				mockContext.EXPECT().Query(gomock.Eq("currency")).Return("eur")
+				mockOrderService.EXPECT().
+					SpendForUser(gomock.Any(), gomock.Eq(5), gomock.Eq(models.EUR), gomock.Eq(actor)).
+					Return(nil, &services.Error{Kind: services.ErrorKindForbidden, Code: "forbidden"})
+				mockContext.EXPECT().JSON(gomock.Eq(403), gomock.Any())
+			})
+
+			It("should return a 403", func() {
+				p.SpendForUser(mockContext)
+			})
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
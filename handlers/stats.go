@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// FindOrderStatsForUser gets the order statistics for a user.
+func FindOrderStatsForUser(c *gin.Context) {
+	p := &Provider{}
+	p.FindOrderStatsForUser(c)
+}
+
+// FindOrderStatsForUser is the provider method that gets the order statistics
+// for a user. Orders are counted per month unless the period query parameter
+// is "week". Only the user and admins may see them.
+func (p *Provider) FindOrderStatsForUser(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.FindOrderStatsForUser")
+	defer span.End()
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	actor := requestActor(c)
+	if actor == nil {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	period, err := models.ParseStatsPeriod(c.DefaultQuery("period", string(models.StatsPeriodMonth)))
+	if err != nil {
+		renderBadRequest(c, "invalid_period", "the period query parameter must be week or month")
+		return
+	}
+
+	stats, err := p.getOrderService().StatsForUser(ctx, userID, period, actor)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,99 @@
+package handlers_test
+
+import (
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_services"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	. "github.com/onsi/ginkgo"
+)
+
+var _ = Describe("FindOrderStatsForUser", func() {
+	var (
+		mockContext      *mock_handlers.MockContext
+		mockOrderService *mock_services.MockOrderService
+		p                *handlers.Provider
+		ctrl             *gomock.Controller
+
+		actor = &models.Actor{ID: "user:5", Scopes: []string{}}
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockContext = mock_handlers.NewMockContext(ctrl)
+		mockContext.EXPECT().Value(gomock.Eq(0)).Return(nil)
+		mockContext.EXPECT().Param(gomock.Eq("id")).Return("5")
+		mockOrderService = mock_services.NewMockOrderService(ctrl)
+
+		p = &handlers.Provider{}
+		p.SetOrderService(mockOrderService)
+	})
+
+	Describe("without an authenticated actor", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("")
+			mockContext.EXPECT().Status(gomock.Eq(401))
+		})
+
+		It("should return a 401", func() {
+			p.FindOrderStatsForUser(mockContext)
+		})
+	})
+
+	Describe("with an authenticated actor", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("user:5")
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-Scopes")).Return("")
+		})
+
+		Describe("when the period is not supported", func() {
+			BeforeEach(func() {
+				mockContext.EXPECT().DefaultQuery(gomock.Eq("period"), gomock.Eq("month")).Return("year")
+				mockContext.EXPECT().JSON(gomock.Eq(400), gomock.Any())
+			})
+
+			It("should return a 400", func() {
+				p.FindOrderStatsForUser(mockContext)
+			})
+		})
+
+		Describe("when the period is supported", func() {
+			var stats *models.OrderStats
+
+			BeforeEach(func() {
+				stats = &models.OrderStats{UserID: 5, OrderCount: 2, Period: models.StatsPeriodWeek}
+
+				mockContext.EXPECT().DefaultQuery(gomock.Eq("period"), gomock.Eq("month")).Return("week")
+				mockOrderService.EXPECT().
+					StatsForUser(gomock.Any(), gomock.Eq(5), gomock.Eq(models.StatsPeriodWeek), gomock.Eq(actor)).
+					Return(stats, error(nil))
+				mockContext.EXPECT().JSON(gomock.Eq(200), gomock.Eq(stats))
+			})
+
+			It("should return a 200 with the stats", func() {
+				p.FindOrderStatsForUser(mockContext)
+			})
+		})
+
+		Describe("when the actor may not see the user's stats", func() {
+			BeforeEach(func() {
+				mockContext.EXPECT().DefaultQuery(gomock.Eq("period"), gomock.Eq("month")).Return("month")
+				mockOrderService.EXPECT().
+					StatsForUser(gomock.Any(), gomock.Eq(5), gomock.Eq(models.StatsPeriodMonth), gomock.Eq(actor)).
+					Return(nil, &services.Error{Kind: services.ErrorKindForbidden, Code: "forbidden"})
+				mockContext.EXPECT().JSON(gomock.Eq(403), gomock.Any())
+			})
+
+			It("should return a 403", func() {
+				p.FindOrderStatsForUser(mockContext)
+			})
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// TraceRequests is middleware that continues the trace of an incoming request
+// from its traceparent header, or starts a new trace, and records a span that
+// covers the whole request. The request is identified by its X-Request-ID
+// header, or by its trace ID when it has none, and the ID is echoed in the
+// response.
+func TraceRequests(c *gin.Context) {
+	ctx := tracing.Extract(c.Request.Context(), c.Request.Header)
+	ctx, span := tracing.StartSpan(ctx, "HTTP "+c.Request.Method)
+	defer span.End()
+
+	span.SetAttribute("http.method", c.Request.Method)
+	span.SetAttribute("http.path", c.Request.URL.Path)
+
+	requestID := c.GetHeader(tracing.RequestIDHeader)
+	if requestID == "" {
+		requestID = span.Context().TraceID.String()
+	}
+	span.SetAttribute("http.request_id", requestID)
+	c.Header(tracing.RequestIDHeader, requestID)
+	ctx = tracing.ContextWithRequestID(ctx, requestID)
+
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+
+	span.SetAttribute("http.status_code", c.Writer.Status())
+}
@@ -0,0 +1,71 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TraceRequests", func() {
+	var (
+		exporter *tracing.InMemoryExporter
+		app      *gin.Engine
+		handled  tracing.SpanContext
+		reqID    string
+	)
+
+	BeforeEach(func() {
+		exporter = tracing.NewInMemoryExporter()
+		tracing.SetExporter(exporter)
+
+		gin.SetMode(gin.TestMode)
+		app = gin.New()
+		app.Use(handlers.TraceRequests)
+		app.GET("/ping", func(c *gin.Context) {
+			handled = tracing.SpanFromContext(c.Request.Context()).Context()
+			reqID = tracing.RequestIDFromContext(c.Request.Context())
+			c.Status(http.StatusNoContent)
+		})
+	})
+
+	AfterEach(func() {
+		tracing.SetExporter(tracing.NopExporter{})
+	})
+
+	It("continues the trace from the incoming traceparent header", func() {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		app.ServeHTTP(httptest.NewRecorder(), req)
+
+		span := exporter.SpanNamed("HTTP GET")
+		Expect(span).NotTo(BeNil())
+		Expect(span.TraceID).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+		Expect(span.ParentID).To(Equal("00f067aa0ba902b7"))
+		Expect(span.SpanID).To(Equal(handled.SpanID.String()))
+		Expect(span.Attributes).To(HaveKeyWithValue("http.status_code", http.StatusNoContent))
+	})
+	It("passes the X-Request-ID header on to the request context and the response", func() {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set(tracing.RequestIDHeader, "req-123")
+		res := httptest.NewRecorder()
+		app.ServeHTTP(res, req)
+
+		Expect(reqID).To(Equal("req-123"))
+		Expect(res.Header().Get(tracing.RequestIDHeader)).To(Equal("req-123"))
+	})
+
+	It("identifies requests without an X-Request-ID header by their trace ID", func() {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		res := httptest.NewRecorder()
+		app.ServeHTTP(res, req)
+
+		Expect(reqID).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+		Expect(res.Header().Get(tracing.RequestIDHeader)).To(Equal(reqID))
+	})
+})
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// ExportUserData downloads everything stored about a user.
+func ExportUserData(c *gin.Context) {
+	p := &Provider{}
+	p.ExportUserData(c)
+}
+
+// ExportUserData is the provider method that answers a data subject access
+// request with a ZIP archive of everything stored about a user. It is only
+// available to callers with the orders:admin scope.
+func (p *Provider) ExportUserData(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.ExportUserData")
+	defer span.End()
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	actor := requestActor(c)
+	if actor == nil {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+	if !actor.HasScope(models.ScopeOrdersAdmin) {
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	export, err := p.getUserDataService().ExportUserData(ctx, userID)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	archive := &bytes.Buffer{}
+	if err = services.WriteUserDataArchive(archive, export); err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="user-%d-data.zip"`, userID))
+	c.Data(http.StatusOK, "application/zip", archive.Bytes())
+}
+
+// EraseUserData erases the personal data of a user's orders.
+func EraseUserData(c *gin.Context) {
+	p := &Provider{}
+	p.EraseUserData(c)
+}
+
+// EraseUserData is the provider method that anonymises a user's orders at the
+// user's request. It responds with the recorded erasure: 201 when the data
+// was erased by this request and 200 when it had already been erased. It is
+// only available to callers with the orders:admin scope.
+func (p *Provider) EraseUserData(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.EraseUserData")
+	defer span.End()
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	actor := requestActor(c)
+	if actor == nil {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+	if !actor.HasScope(models.ScopeOrdersAdmin) {
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	erasure, created, err := p.getUserDataService().EraseUserData(ctx, userID, actor)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, erasure)
+}
+
+// VerifyUserErasure checks that nothing stored identifies a user any more.
+func VerifyUserErasure(c *gin.Context) {
+	p := &Provider{}
+	p.VerifyUserErasure(c)
+}
+
+// VerifyUserErasure is the provider method that reports how many rows of
+// each table still identify a user, and the user's latest erasure. It is only
+// available to callers with the orders:admin scope.
+func (p *Provider) VerifyUserErasure(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.VerifyUserErasure")
+	defer span.End()
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	actor := requestActor(c)
+	if actor == nil {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+	if !actor.HasScope(models.ScopeOrdersAdmin) {
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	verification, err := p.getUserDataService().VerifyErasure(ctx, userID)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
@@ -0,0 +1,106 @@
+package handlers_test
+
+import (
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_services"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	. "github.com/onsi/ginkgo"
+)
+
+var _ = Describe("ExportUserData", func() {
+	var (
+		mockContext         *mock_handlers.MockContext
+		mockUserDataService *mock_services.MockUserDataService
+		p                   *handlers.Provider
+		ctrl                *gomock.Controller
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockContext = mock_handlers.NewMockContext(ctrl)
+		mockContext.EXPECT().Value(gomock.Eq(0)).Return(nil)
+		mockContext.EXPECT().Param(gomock.Eq("id")).Return("5")
+		mockUserDataService = mock_services.NewMockUserDataService(ctrl)
+
+		p = &handlers.Provider{}
+		p.SetUserDataService(mockUserDataService)
+	})
+
+	Describe("when the actor is not a support agent", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("user:5")
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-Scopes")).Return("")
+			mockContext.EXPECT().Status(gomock.Eq(403))
+		})
+
+		It("should return a 403", func() {
+			p.ExportUserData(mockContext)
+		})
+	})
+
+	Describe("when the actor is a support agent", func() {
+		BeforeEach(func() {
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("agent:1")
+			mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-Scopes")).Return("orders:admin")
+			mockUserDataService.EXPECT().ExportUserData(gomock.Any(), gomock.Eq(5)).
+				Return(&models.UserDataExport{UserID: 5, GeneratedAt: time.Date(2019, 5, 12, 12, 0, 0, 0, time.UTC)}, error(nil))
+			mockContext.EXPECT().Header(gomock.Eq("Content-Disposition"), gomock.Eq(`attachment; filename="user-5-data.zip"`))
+			mockContext.EXPECT().Data(gomock.Eq(200), gomock.Eq("application/zip"), gomock.Any())
+		})
+
+		It("should return a 200 with the archive", func() {
+			p.ExportUserData(mockContext)
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
+
+var _ = Describe("EraseUserData", func() {
+	var (
+		mockContext         *mock_handlers.MockContext
+		mockUserDataService *mock_services.MockUserDataService
+		p                   *handlers.Provider
+		ctrl                *gomock.Controller
+		erasure             *models.UserErasure
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockContext = mock_handlers.NewMockContext(ctrl)
+		mockContext.EXPECT().Value(gomock.Eq(0)).Return(nil)
+		mockContext.EXPECT().Param(gomock.Eq("id")).Return("5")
+		mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-ID")).Return("agent:1")
+		mockContext.EXPECT().GetHeader(gomock.Eq("X-Actor-Scopes")).Return("orders:admin")
+		mockUserDataService = mock_services.NewMockUserDataService(ctrl)
+		erasure = &models.UserErasure{ID: 1, UserID: 5, ActorID: "agent:1", OrdersErased: 2}
+
+		p = &handlers.Provider{}
+		p.SetUserDataService(mockUserDataService)
+	})
+
+	It("should return a 201 when the data is erased", func() {
+		mockUserDataService.EXPECT().EraseUserData(gomock.Any(), gomock.Eq(5), gomock.Any()).Return(erasure, true, error(nil))
+		mockContext.EXPECT().JSON(gomock.Eq(201), gomock.Eq(erasure))
+
+		p.EraseUserData(mockContext)
+	})
+
+	It("should return a 200 when the data was already erased", func() {
+		mockUserDataService.EXPECT().EraseUserData(gomock.Any(), gomock.Eq(5), gomock.Any()).Return(erasure, false, error(nil))
+		mockContext.EXPECT().JSON(gomock.Eq(200), gomock.Eq(erasure))
+
+		p.EraseUserData(mockContext)
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// webhookSubscriptionWithSecret is the response to creating a subscription.
+// It is the only response that includes the secret, which may have been
+// generated.
+type webhookSubscriptionWithSecret struct {
+	*models.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// CreateWebhookSubscription subscribes a restaurant's URL to its order events.
+func CreateWebhookSubscription(c *gin.Context) {
+	p := &Provider{}
+	p.CreateWebhookSubscription(c)
+}
+
+// CreateWebhookSubscription is the provider method that subscribes a
+// restaurant's URL to the events about its orders. The body holds the url,
+// the optional event_types filter and an optional secret; a secret is
+// generated when none is given.
+func (p *Provider) CreateWebhookSubscription(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.CreateWebhookSubscription")
+	defer span.End()
+
+	restaurantID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	actor := requestActor(c)
+	if actor == nil {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	req := services.WebhookSubscriptionRequest{}
+	if err = c.ShouldBindJSON(&req); err != nil {
+		renderBadRequest(c, "invalid_body", "the request body must be a JSON object with a url")
+		return
+	}
+
+	subscription, err := p.getWebhookService().CreateSubscription(ctx, restaurantID, req, actor)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhookSubscriptionWithSecret{
+		WebhookSubscription: subscription,
+		Secret:              subscription.Secret,
+	})
+}
+
+// FindWebhookSubscriptionsForRestaurant gets a restaurant's subscriptions.
+func FindWebhookSubscriptionsForRestaurant(c *gin.Context) {
+	p := &Provider{}
+	p.FindWebhookSubscriptionsForRestaurant(c)
+}
+
+// FindWebhookSubscriptionsForRestaurant is the provider method that gets a
+// restaurant's webhook subscriptions.
+func (p *Provider) FindWebhookSubscriptionsForRestaurant(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.FindWebhookSubscriptionsForRestaurant")
+	defer span.End()
+
+	restaurantID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	actor := requestActor(c)
+	if actor == nil {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	subscriptions, err := p.getWebhookService().FindSubscriptionsForRestaurant(ctx, restaurantID, actor)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// FindWebhookSubscription gets a single webhook subscription.
+func FindWebhookSubscription(c *gin.Context) {
+	p := &Provider{}
+	p.FindWebhookSubscription(c)
+}
+
+// FindWebhookSubscription is the provider method that gets a single webhook
+// subscription.
+func (p *Provider) FindWebhookSubscription(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.FindWebhookSubscription")
+	defer span.End()
+
+	id, actor, ok := webhookSubscriptionRequest(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := p.getWebhookService().FindSubscriptionByID(ctx, id, actor)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// UpdateWebhookSubscription changes a webhook subscription.
+func UpdateWebhookSubscription(c *gin.Context) {
+	p := &Provider{}
+	p.UpdateWebhookSubscription(c)
+}
+
+// UpdateWebhookSubscription is the provider method that changes the url,
+// event_types or secret of a webhook subscription. Fields that are left out
+// of the body are not changed.
+func (p *Provider) UpdateWebhookSubscription(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.UpdateWebhookSubscription")
+	defer span.End()
+
+	id, actor, ok := webhookSubscriptionRequest(c)
+	if !ok {
+		return
+	}
+
+	req := services.WebhookSubscriptionRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderBadRequest(c, "invalid_body", "the request body must be a JSON object")
+		return
+	}
+
+	subscription, err := p.getWebhookService().UpdateSubscription(ctx, id, req, actor)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteWebhookSubscription deletes a webhook subscription.
+func DeleteWebhookSubscription(c *gin.Context) {
+	p := &Provider{}
+	p.DeleteWebhookSubscription(c)
+}
+
+// DeleteWebhookSubscription is the provider method that deletes a webhook
+// subscription and its delivery log.
+func (p *Provider) DeleteWebhookSubscription(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.DeleteWebhookSubscription")
+	defer span.End()
+
+	id, actor, ok := webhookSubscriptionRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := p.getWebhookService().DeleteSubscription(ctx, id, actor); err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// FindWebhookDeliveries gets a webhook subscription's delivery log.
+func FindWebhookDeliveries(c *gin.Context) {
+	p := &Provider{}
+	p.FindWebhookDeliveries(c)
+}
+
+// FindWebhookDeliveries is the provider method that gets the latest entries
+// of a webhook subscription's delivery log, newest first.
+func (p *Provider) FindWebhookDeliveries(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.FindWebhookDeliveries")
+	defer span.End()
+
+	id, actor, ok := webhookSubscriptionRequest(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := p.getWebhookService().FindDeliveries(ctx, id, actor)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// ReplayWebhookDelivery sends an earlier webhook delivery again.
+func ReplayWebhookDelivery(c *gin.Context) {
+	p := &Provider{}
+	p.ReplayWebhookDelivery(c)
+}
+
+// ReplayWebhookDelivery is the provider method that schedules an earlier
+// webhook delivery to be sent again. It responds with the new entry in the
+// delivery log, which is sent in the background.
+func (p *Provider) ReplayWebhookDelivery(c Context) {
+	ctx, span := tracing.StartSpan(requestContext(c), "Provider.ReplayWebhookDelivery")
+	defer span.End()
+
+	id, actor, ok := webhookSubscriptionRequest(c)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	replay, err := p.getWebhookService().ReplayDelivery(ctx, id, deliveryID, actor)
+	if err != nil {
+		span.SetError(err)
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, replay)
+}
+
+// webhookSubscriptionRequest reads the subscription ID and the actor of a
+// request about a single subscription. When it returns false, the response
+// has already been written.
+func webhookSubscriptionRequest(c Context) (int, *models.Actor, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return 0, nil, false
+	}
+
+	actor := requestActor(c)
+	if actor == nil {
+		c.Status(http.StatusUnauthorized)
+		return 0, nil, false
+	}
+
+	return id, actor, true
+}
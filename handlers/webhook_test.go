@@ -0,0 +1,102 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_services"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhook subscriptions", func() {
+	var (
+		ctrl               *gomock.Controller
+		mockWebhookService *mock_services.MockWebhookService
+		router             *gin.Engine
+		recorder           *httptest.ResponseRecorder
+		actor              = &models.Actor{ID: "restaurant:9", Scopes: []string{}}
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockWebhookService = mock_services.NewMockWebhookService(ctrl)
+
+		p := &handlers.Provider{}
+		p.SetWebhookService(mockWebhookService)
+
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		router.POST("/restaurants/:id/webhooks", func(c *gin.Context) { p.CreateWebhookSubscription(c) })
+		router.DELETE("/webhooks/:id", func(c *gin.Context) { p.DeleteWebhookSubscription(c) })
+		router.POST("/webhooks/:id/deliveries/:delivery_id/replay", func(c *gin.Context) { p.ReplayWebhookDelivery(c) })
+		recorder = httptest.NewRecorder()
+	})
+
+	serve := func(method, path, body string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Actor-ID", actor.ID)
+		router.ServeHTTP(recorder, req)
+	}
+
+	Describe("creating a subscription", func() {
+		It("returns the subscription with its secret", func() {
+			mockWebhookService.EXPECT().
+				CreateSubscription(gomock.Any(), gomock.Eq(9), gomock.Eq(services.WebhookSubscriptionRequest{URL: "https://example.com/hooks"}), gomock.Eq(actor)).
+				Return(&models.WebhookSubscription{ID: 2, RestaurantID: 9, URL: "https://example.com/hooks", EventTypes: []models.EventType{}, Secret: "generated"}, error(nil))
+
+			serve(http.MethodPost, "/restaurants/9/webhooks", `{"url":"https://example.com/hooks"}`)
+
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+			body := map[string]interface{}{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+			Expect(body["id"]).To(Equal(2.0))
+			Expect(body["secret"]).To(Equal("generated"))
+		})
+
+		It("renders validation errors", func() {
+			mockWebhookService.EXPECT().
+				CreateSubscription(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil, &services.Error{Kind: services.ErrorKindInvalid, Code: "invalid_webhook_url"})
+
+			serve(http.MethodPost, "/restaurants/9/webhooks", `{"url":"nope"}`)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("deleting a subscription", func() {
+		It("returns a 204", func() {
+			mockWebhookService.EXPECT().DeleteSubscription(gomock.Any(), gomock.Eq(2), gomock.Eq(actor)).Return(nil)
+
+			serve(http.MethodDelete, "/webhooks/2", "")
+
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		})
+	})
+
+	Describe("replaying a delivery", func() {
+		It("returns a 202 with the new delivery", func() {
+			mockWebhookService.EXPECT().
+				ReplayDelivery(gomock.Any(), gomock.Eq(2), gomock.Eq(int64(11)), gomock.Eq(actor)).
+				Return(&models.WebhookDelivery{ID: 12, SubscriptionID: 2, Body: []byte(`{}`)}, error(nil))
+
+			serve(http.MethodPost, "/webhooks/2/deliveries/11/replay", "")
+
+			Expect(recorder.Code).To(Equal(http.StatusAccepted))
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
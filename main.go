@@ -1,15 +1,73 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"os"
+
 	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/commands"
+	"github.com/SebastianCoetzee/blog-order-service-example/events"
 	"github.com/SebastianCoetzee/blog-order-service-example/handlers"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
+	application.ConfigureTracing()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := startEvents(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
 	app := gin.Default()
-	app.GET("/users/:id/orders", handlers.FindOrdersForUser)
+	handlers.Routes(app)
 	app.Run()
 
 	defer application.CloseDB()
 }
+
+// startEvents starts relaying outbox events to webhook subscriptions, the
+// RestaurantService and the configured event publisher if there is one,
+// dispatching webhooks, and passing the events that any instance announces on
+// to this instance's order streams, in the background.
+func startEvents(ctx context.Context) error {
+	publisher, err := events.PublisherFromEnv()
+	if err != nil {
+		return err
+	}
+
+	publishers := []events.Publisher{events.NewSubscriptionPublisher(), events.NewRestaurantNotifier()}
+	if publisher != nil {
+		publishers = append(publishers, publisher)
+	}
+
+	go events.NewRelay(events.Publishers(publishers...)).Run(ctx)
+	go events.NewWebhookDispatcher().Run(ctx)
+	go repositories.NewOrderEventListener(application.ResolveDB(), events.DefaultBroker().Publish).Run(ctx)
+	return nil
+}
+
+// runCommand runs the named subcommand and returns the process exit code.
+func runCommand(name string, args []string) int {
+	command, ok := commands.Commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+		return 2
+	}
+	defer application.CloseDB()
+
+	if err := command(context.Background(), args); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,2 @@
+DROP TABLE order_status_changes;
+DROP TABLE order_items;
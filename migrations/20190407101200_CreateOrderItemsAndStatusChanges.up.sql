@@ -0,0 +1,20 @@
+CREATE TABLE order_items
+(
+    id serial PRIMARY KEY NOT NULL,
+    order_id integer NOT NULL REFERENCES orders (id),
+    name character varying NOT NULL,
+    quantity integer NOT NULL,
+    unit_price integer NOT NULL
+);
+
+CREATE INDEX order_items_order_id_idx ON order_items (order_id);
+
+CREATE TABLE order_status_changes
+(
+    id serial PRIMARY KEY NOT NULL,
+    order_id integer NOT NULL REFERENCES orders (id),
+    status character varying NOT NULL,
+    changed_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX order_status_changes_order_id_idx ON order_status_changes (order_id, changed_at);
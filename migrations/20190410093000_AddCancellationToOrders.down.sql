@@ -0,0 +1,5 @@
+ALTER TABLE orders
+    DROP COLUMN cancellation_reason,
+    DROP COLUMN cancelled_by,
+    DROP COLUMN cancelled_at,
+    DROP COLUMN status;
@@ -0,0 +1,5 @@
+ALTER TABLE orders
+    ADD COLUMN status character varying NOT NULL DEFAULT 'placed',
+    ADD COLUMN cancelled_at timestamp with time zone,
+    ADD COLUMN cancelled_by character varying,
+    ADD COLUMN cancellation_reason character varying;
@@ -0,0 +1,12 @@
+CREATE TABLE refunds
+(
+    id serial PRIMARY KEY NOT NULL,
+    order_id integer NOT NULL REFERENCES orders (id),
+    amount integer NOT NULL CHECK (amount > 0),
+    currency_code character varying NOT NULL,
+    reason character varying NOT NULL,
+    actor_id character varying NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX refunds_order_id_idx ON refunds (order_id, created_at);
@@ -0,0 +1,4 @@
+ALTER TABLE refunds ALTER COLUMN amount TYPE integer;
+ALTER TABLE orders ALTER COLUMN total TYPE integer;
+ALTER TABLE refunds DROP CONSTRAINT refunds_currency_code_check;
+ALTER TABLE orders DROP CONSTRAINT orders_currency_code_check;
@@ -0,0 +1,7 @@
+UPDATE orders SET currency_code = upper(currency_code);
+UPDATE refunds SET currency_code = upper(currency_code);
+
+ALTER TABLE orders ADD CONSTRAINT orders_currency_code_check CHECK (currency_code ~ '^[A-Z]{3}$');
+ALTER TABLE refunds ADD CONSTRAINT refunds_currency_code_check CHECK (currency_code ~ '^[A-Z]{3}$');
+ALTER TABLE orders ALTER COLUMN total TYPE bigint;
+ALTER TABLE refunds ALTER COLUMN amount TYPE bigint;
@@ -0,0 +1,9 @@
+CREATE TABLE exchange_rates
+(
+    id serial PRIMARY KEY NOT NULL,
+    base_currency character varying NOT NULL,
+    quote_currency character varying NOT NULL,
+    rate numeric NOT NULL CHECK (rate > 0),
+    effective_at timestamp with time zone NOT NULL,
+    UNIQUE (base_currency, quote_currency, effective_at)
+);
@@ -0,0 +1 @@
+DROP INDEX orders_restaurant_id_placed_at_idx;
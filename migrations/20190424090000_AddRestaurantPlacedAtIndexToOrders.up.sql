@@ -0,0 +1 @@
+CREATE INDEX orders_restaurant_id_placed_at_idx ON orders (restaurant_id, placed_at);
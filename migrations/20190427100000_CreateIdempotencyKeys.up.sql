@@ -0,0 +1,17 @@
+CREATE TABLE idempotency_keys
+(
+    id serial PRIMARY KEY NOT NULL,
+    scope character varying NOT NULL,
+    key character varying NOT NULL,
+    fingerprint character varying NOT NULL,
+    locked_until timestamp with time zone NOT NULL,
+    response_status integer,
+    response_content_type character varying,
+    response_body bytea,
+    created_at timestamp with time zone NOT NULL,
+    completed_at timestamp with time zone,
+    expires_at timestamp with time zone NOT NULL,
+    UNIQUE (scope, key)
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
@@ -0,0 +1 @@
+ALTER TABLE orders ADD COLUMN version integer NOT NULL DEFAULT 1;
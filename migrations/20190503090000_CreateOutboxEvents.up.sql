@@ -0,0 +1,16 @@
+CREATE TABLE outbox_events
+(
+    id bigserial PRIMARY KEY NOT NULL,
+    type character varying NOT NULL,
+    schema_version integer NOT NULL,
+    order_id integer NOT NULL REFERENCES orders (id),
+    payload jsonb NOT NULL,
+    occurred_at timestamp with time zone NOT NULL,
+    status character varying NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts integer NOT NULL DEFAULT 0,
+    last_error text,
+    next_attempt_at timestamp with time zone NOT NULL,
+    delivered_at timestamp with time zone
+);
+
+CREATE INDEX outbox_events_pending_idx ON outbox_events (next_attempt_at, id) WHERE status = 'pending';
@@ -0,0 +1,33 @@
+CREATE TABLE webhook_subscriptions
+(
+    id serial PRIMARY KEY NOT NULL,
+    restaurant_id integer NOT NULL,
+    url character varying NOT NULL,
+    event_types character varying[] NOT NULL DEFAULT '{}',
+    secret character varying NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX webhook_subscriptions_restaurant_id_idx ON webhook_subscriptions (restaurant_id);
+
+CREATE TABLE webhook_deliveries
+(
+    id bigserial PRIMARY KEY NOT NULL,
+    subscription_id integer NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event_id bigint NOT NULL REFERENCES outbox_events (id),
+    event_type character varying NOT NULL,
+    body jsonb NOT NULL,
+    status character varying NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts integer NOT NULL DEFAULT 0,
+    response_status integer,
+    last_error text,
+    created_at timestamp with time zone NOT NULL,
+    next_attempt_at timestamp with time zone NOT NULL,
+    last_attempt_at timestamp with time zone,
+    delivered_at timestamp with time zone,
+    replay_of bigint REFERENCES webhook_deliveries (id) ON DELETE SET NULL
+);
+
+CREATE INDEX webhook_deliveries_subscription_id_idx ON webhook_deliveries (subscription_id, id);
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at, id) WHERE status = 'pending';
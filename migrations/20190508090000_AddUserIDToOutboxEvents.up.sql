@@ -0,0 +1,7 @@
+ALTER TABLE outbox_events ADD COLUMN user_id integer;
+
+UPDATE outbox_events SET user_id = orders.user_id FROM orders WHERE orders.id = outbox_events.order_id;
+
+ALTER TABLE outbox_events ALTER COLUMN user_id SET NOT NULL;
+
+CREATE INDEX outbox_events_user_id_idx ON outbox_events (user_id, id);
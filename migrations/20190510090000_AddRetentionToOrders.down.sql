@@ -0,0 +1,5 @@
+DROP INDEX orders_placed_at_idx;
+
+ALTER TABLE orders
+    DROP COLUMN deleted_at,
+    DROP COLUMN anonymised_at;
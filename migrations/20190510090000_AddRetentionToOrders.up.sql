@@ -0,0 +1,5 @@
+ALTER TABLE orders
+    ADD COLUMN deleted_at timestamp with time zone,
+    ADD COLUMN anonymised_at timestamp with time zone;
+
+CREATE INDEX orders_placed_at_idx ON orders (placed_at);
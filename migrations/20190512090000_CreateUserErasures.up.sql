@@ -0,0 +1,10 @@
+CREATE TABLE user_erasures
+(
+    id serial PRIMARY KEY NOT NULL,
+    user_id integer NOT NULL,
+    actor_id character varying NOT NULL,
+    orders_erased integer NOT NULL,
+    erased_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX user_erasures_user_id_idx ON user_erasures (user_id, erased_at);
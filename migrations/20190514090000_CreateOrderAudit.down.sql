@@ -0,0 +1,2 @@
+DROP TABLE order_audit;
+DROP FUNCTION order_audit_immutable();
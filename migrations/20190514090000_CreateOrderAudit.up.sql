@@ -0,0 +1,33 @@
+CREATE TABLE order_audit
+(
+    id bigserial PRIMARY KEY NOT NULL,
+    order_id integer NOT NULL,
+    actor_id character varying NOT NULL,
+    action character varying NOT NULL,
+    before jsonb NOT NULL,
+    after jsonb NOT NULL,
+    request_id character varying NOT NULL DEFAULT '',
+    created_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX order_audit_order_id_idx ON order_audit (order_id, id);
+
+-- The audit log is append-only. Rows may only be changed or removed by the
+-- retention job and erasure requests, which set order_audit.redact for the
+-- duration of their transaction.
+CREATE FUNCTION order_audit_immutable() RETURNS trigger AS $$
+BEGIN
+    IF current_setting('order_audit.redact', true) = 'on' THEN
+        IF TG_OP = 'DELETE' THEN
+            RETURN OLD;
+        END IF;
+        RETURN NEW;
+    END IF;
+
+    RAISE EXCEPTION 'order_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER order_audit_immutable
+    BEFORE UPDATE OR DELETE ON order_audit
+    FOR EACH ROW EXECUTE PROCEDURE order_audit_immutable();
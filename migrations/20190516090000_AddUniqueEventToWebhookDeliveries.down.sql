@@ -0,0 +1 @@
+DROP INDEX webhook_deliveries_subscription_id_event_id_idx;
@@ -0,0 +1,23 @@
+-- An event may have been queued more than once for a subscription when the
+-- relay retried it. Keep the first delivery of each event, and point replays of
+-- the others at it before they are removed.
+WITH duplicates AS (
+    SELECT id, min(id) OVER (PARTITION BY subscription_id, event_id) AS first_id
+    FROM webhook_deliveries
+    WHERE replay_of IS NULL
+)
+UPDATE webhook_deliveries
+SET replay_of = duplicates.first_id
+FROM duplicates
+WHERE webhook_deliveries.replay_of = duplicates.id AND duplicates.id <> duplicates.first_id;
+
+WITH duplicates AS (
+    SELECT id, min(id) OVER (PARTITION BY subscription_id, event_id) AS first_id
+    FROM webhook_deliveries
+    WHERE replay_of IS NULL
+)
+DELETE FROM webhook_deliveries
+USING duplicates
+WHERE webhook_deliveries.id = duplicates.id AND duplicates.id <> duplicates.first_id;
+
+CREATE UNIQUE INDEX webhook_deliveries_subscription_id_event_id_idx ON webhook_deliveries (subscription_id, event_id) WHERE replay_of IS NULL;
@@ -5,10 +5,10 @@
 package mock_repositories
 
 import (
-	reflect "reflect"
-
+	context "context"
 	models "github.com/SebastianCoetzee/blog-order-service-example/models"
 	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
 )
 
 // MockOrderRepository is a mock of OrderRepository interface
@@ -35,14 +35,117 @@ func (m *MockOrderRepository) EXPECT() *MockOrderRepositoryMockRecorder {
 }
 
 // FindAllOrdersByUserID mocks base method
-func (m *MockOrderRepository) FindAllOrdersByUserID(arg0 int) (models.Orders, error) {
-	ret := m.ctrl.Call(m, "FindAllOrdersByUserID", arg0)
+func (m *MockOrderRepository) FindAllOrdersByUserID(arg0 context.Context, arg1 int) (models.Orders, error) {
+	ret := m.ctrl.Call(m, "FindAllOrdersByUserID", arg0, arg1)
 	ret0, _ := ret[0].(models.Orders)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindAllOrdersByUserID indicates an expected call of FindAllOrdersByUserID
-func (mr *MockOrderRepositoryMockRecorder) FindAllOrdersByUserID(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllOrdersByUserID", reflect.TypeOf((*MockOrderRepository)(nil).FindAllOrdersByUserID), arg0)
+func (mr *MockOrderRepositoryMockRecorder) FindAllOrdersByUserID(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllOrdersByUserID", reflect.TypeOf((*MockOrderRepository)(nil).FindAllOrdersByUserID), arg0, arg1)
+}
+
+// FindAllOrdersByUserIDWithDeleted mocks base method
+func (m *MockOrderRepository) FindAllOrdersByUserIDWithDeleted(arg0 context.Context, arg1 int) (models.Orders, error) {
+	ret := m.ctrl.Call(m, "FindAllOrdersByUserIDWithDeleted", arg0, arg1)
+	ret0, _ := ret[0].(models.Orders)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllOrdersByUserIDWithDeleted indicates an expected call of FindAllOrdersByUserIDWithDeleted
+func (mr *MockOrderRepositoryMockRecorder) FindAllOrdersByUserIDWithDeleted(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllOrdersByUserIDWithDeleted", reflect.TypeOf((*MockOrderRepository)(nil).FindAllOrdersByUserIDWithDeleted), arg0, arg1)
+}
+
+// FindOrdersByRestaurant mocks base method
+func (m *MockOrderRepository) FindOrdersByRestaurant(arg0 context.Context, arg1 models.RestaurantOrderFilter) (models.Orders, error) {
+	ret := m.ctrl.Call(m, "FindOrdersByRestaurant", arg0, arg1)
+	ret0, _ := ret[0].(models.Orders)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrdersByRestaurant indicates an expected call of FindOrdersByRestaurant
+func (mr *MockOrderRepositoryMockRecorder) FindOrdersByRestaurant(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrdersByRestaurant", reflect.TypeOf((*MockOrderRepository)(nil).FindOrdersByRestaurant), arg0, arg1)
+}
+
+// FindOrderByID mocks base method
+func (m *MockOrderRepository) FindOrderByID(arg0 context.Context, arg1 int) (*models.Order, error) {
+	ret := m.ctrl.Call(m, "FindOrderByID", arg0, arg1)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrderByID indicates an expected call of FindOrderByID
+func (mr *MockOrderRepositoryMockRecorder) FindOrderByID(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrderByID", reflect.TypeOf((*MockOrderRepository)(nil).FindOrderByID), arg0, arg1)
+}
+
+// FindOrderByIDForUpdate mocks base method
+func (m *MockOrderRepository) FindOrderByIDForUpdate(arg0 context.Context, arg1 int) (*models.Order, error) {
+	ret := m.ctrl.Call(m, "FindOrderByIDForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrderByIDForUpdate indicates an expected call of FindOrderByIDForUpdate
+func (mr *MockOrderRepositoryMockRecorder) FindOrderByIDForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrderByIDForUpdate", reflect.TypeOf((*MockOrderRepository)(nil).FindOrderByIDForUpdate), arg0, arg1)
+}
+
+// UpdateOrder mocks base method
+func (m *MockOrderRepository) UpdateOrder(arg0 context.Context, arg1 *models.Order) error {
+	ret := m.ctrl.Call(m, "UpdateOrder", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrder indicates an expected call of UpdateOrder
+func (mr *MockOrderRepositoryMockRecorder) UpdateOrder(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrder", reflect.TypeOf((*MockOrderRepository)(nil).UpdateOrder), arg0, arg1)
+}
+
+// SpendByCurrencyForUser mocks base method
+func (m *MockOrderRepository) SpendByCurrencyForUser(arg0 context.Context, arg1 int) ([]*models.CurrencySpend, error) {
+	ret := m.ctrl.Call(m, "SpendByCurrencyForUser", arg0, arg1)
+	ret0, _ := ret[0].([]*models.CurrencySpend)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SpendByCurrencyForUser indicates an expected call of SpendByCurrencyForUser
+func (mr *MockOrderRepositoryMockRecorder) SpendByCurrencyForUser(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SpendByCurrencyForUser", reflect.TypeOf((*MockOrderRepository)(nil).SpendByCurrencyForUser), arg0, arg1)
+}
+
+// RestaurantStatsForUser mocks base method
+func (m *MockOrderRepository) RestaurantStatsForUser(arg0 context.Context, arg1 int) ([]*models.RestaurantStats, error) {
+	ret := m.ctrl.Call(m, "RestaurantStatsForUser", arg0, arg1)
+	ret0, _ := ret[0].([]*models.RestaurantStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestaurantStatsForUser indicates an expected call of RestaurantStatsForUser
+func (mr *MockOrderRepositoryMockRecorder) RestaurantStatsForUser(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestaurantStatsForUser", reflect.TypeOf((*MockOrderRepository)(nil).RestaurantStatsForUser), arg0, arg1)
+}
+
+// OrderCountsForUser mocks base method
+func (m *MockOrderRepository) OrderCountsForUser(arg0 context.Context, arg1 int, arg2 models.StatsPeriod) ([]*models.PeriodCount, error) {
+	ret := m.ctrl.Call(m, "OrderCountsForUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.PeriodCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OrderCountsForUser indicates an expected call of OrderCountsForUser
+func (mr *MockOrderRepositoryMockRecorder) OrderCountsForUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrderCountsForUser", reflect.TypeOf((*MockOrderRepository)(nil).OrderCountsForUser), arg0, arg1, arg2)
 }
@@ -5,7 +5,9 @@
 package mock_services
 
 import (
+	context "context"
 	models "github.com/SebastianCoetzee/blog-order-service-example/models"
+	services "github.com/SebastianCoetzee/blog-order-service-example/services"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
 )
@@ -34,14 +36,130 @@ func (m *MockOrderService) EXPECT() *MockOrderServiceMockRecorder {
 }
 
 // FindAllOrdersByUserID mocks base method
-func (m *MockOrderService) FindAllOrdersByUserID(arg0 int) (models.Orders, error) {
-	ret := m.ctrl.Call(m, "FindAllOrdersByUserID", arg0)
+func (m *MockOrderService) FindAllOrdersByUserID(arg0 context.Context, arg1 int) (models.Orders, error) {
+	ret := m.ctrl.Call(m, "FindAllOrdersByUserID", arg0, arg1)
 	ret0, _ := ret[0].(models.Orders)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindAllOrdersByUserID indicates an expected call of FindAllOrdersByUserID
-func (mr *MockOrderServiceMockRecorder) FindAllOrdersByUserID(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllOrdersByUserID", reflect.TypeOf((*MockOrderService)(nil).FindAllOrdersByUserID), arg0)
+func (mr *MockOrderServiceMockRecorder) FindAllOrdersByUserID(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllOrdersByUserID", reflect.TypeOf((*MockOrderService)(nil).FindAllOrdersByUserID), arg0, arg1)
+}
+
+// FindOrdersForRestaurant mocks base method
+func (m *MockOrderService) FindOrdersForRestaurant(arg0 context.Context, arg1 models.RestaurantOrderFilter, arg2 *models.Actor) (*models.RestaurantOrderPage, error) {
+	ret := m.ctrl.Call(m, "FindOrdersForRestaurant", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.RestaurantOrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrdersForRestaurant indicates an expected call of FindOrdersForRestaurant
+func (mr *MockOrderServiceMockRecorder) FindOrdersForRestaurant(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrdersForRestaurant", reflect.TypeOf((*MockOrderService)(nil).FindOrdersForRestaurant), arg0, arg1, arg2)
+}
+
+// FindOrderByID mocks base method
+func (m *MockOrderService) FindOrderByID(arg0 context.Context, arg1 int, arg2 *models.Actor) (*models.Order, error) {
+	ret := m.ctrl.Call(m, "FindOrderByID", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrderByID indicates an expected call of FindOrderByID
+func (mr *MockOrderServiceMockRecorder) FindOrderByID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrderByID", reflect.TypeOf((*MockOrderService)(nil).FindOrderByID), arg0, arg1, arg2)
+}
+
+// CancelOrder mocks base method
+func (m *MockOrderService) CancelOrder(arg0 context.Context, arg1 int, arg2 int, arg3 models.CancellationReason, arg4 *models.Actor) (*models.Order, error) {
+	ret := m.ctrl.Call(m, "CancelOrder", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelOrder indicates an expected call of CancelOrder
+func (mr *MockOrderServiceMockRecorder) CancelOrder(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockOrderService)(nil).CancelOrder), arg0, arg1, arg2, arg3, arg4)
+}
+
+// RefundOrder mocks base method
+func (m *MockOrderService) RefundOrder(arg0 context.Context, arg1 int, arg2 int, arg3 services.RefundRequest, arg4 *models.Actor) (*models.Order, error) {
+	ret := m.ctrl.Call(m, "RefundOrder", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefundOrder indicates an expected call of RefundOrder
+func (mr *MockOrderServiceMockRecorder) RefundOrder(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundOrder", reflect.TypeOf((*MockOrderService)(nil).RefundOrder), arg0, arg1, arg2, arg3, arg4)
+}
+
+// DeleteOrder mocks base method
+func (m *MockOrderService) DeleteOrder(arg0 context.Context, arg1 int, arg2 int, arg3 *models.Actor) error {
+	ret := m.ctrl.Call(m, "DeleteOrder", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrder indicates an expected call of DeleteOrder
+func (mr *MockOrderServiceMockRecorder) DeleteOrder(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrder", reflect.TypeOf((*MockOrderService)(nil).DeleteOrder), arg0, arg1, arg2, arg3)
+}
+
+// FindOrderAudit mocks base method
+func (m *MockOrderService) FindOrderAudit(arg0 context.Context, arg1 int, arg2 *models.Actor) (models.OrderAuditEntries, error) {
+	ret := m.ctrl.Call(m, "FindOrderAudit", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.OrderAuditEntries)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrderAudit indicates an expected call of FindOrderAudit
+func (mr *MockOrderServiceMockRecorder) FindOrderAudit(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrderAudit", reflect.TypeOf((*MockOrderService)(nil).FindOrderAudit), arg0, arg1, arg2)
+}
+
+// SpendForUser mocks base method
+func (m *MockOrderService) SpendForUser(arg0 context.Context, arg1 int, arg2 models.Currency, arg3 *models.Actor) (*models.SpendSummary, error) {
+	ret := m.ctrl.Call(m, "SpendForUser", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.SpendSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SpendForUser indicates an expected call of SpendForUser
+func (mr *MockOrderServiceMockRecorder) SpendForUser(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SpendForUser", reflect.TypeOf((*MockOrderService)(nil).SpendForUser), arg0, arg1, arg2, arg3)
+}
+
+// StatsForUser mocks base method
+func (m *MockOrderService) StatsForUser(arg0 context.Context, arg1 int, arg2 models.StatsPeriod, arg3 *models.Actor) (*models.OrderStats, error) {
+	ret := m.ctrl.Call(m, "StatsForUser", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.OrderStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StatsForUser indicates an expected call of StatsForUser
+func (mr *MockOrderServiceMockRecorder) StatsForUser(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatsForUser", reflect.TypeOf((*MockOrderService)(nil).StatsForUser), arg0, arg1, arg2, arg3)
+}
+
+// FindOrderEventsForUser mocks base method
+func (m *MockOrderService) FindOrderEventsForUser(arg0 context.Context, arg1 int, arg2 int64, arg3 *models.Actor) (models.OutboxEvents, error) {
+	ret := m.ctrl.Call(m, "FindOrderEventsForUser", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(models.OutboxEvents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrderEventsForUser indicates an expected call of FindOrderEventsForUser
+func (mr *MockOrderServiceMockRecorder) FindOrderEventsForUser(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrderEventsForUser", reflect.TypeOf((*MockOrderService)(nil).FindOrderEventsForUser), arg0, arg1, arg2, arg3)
 }
@@ -0,0 +1,57 @@
+package models
+
+import "strconv"
+
+// Scopes that grant access beyond an actor's own orders.
+const (
+	// ScopeOrdersAdmin allows support agents to act on any order.
+	ScopeOrdersAdmin = "orders:admin"
+	// ScopeOrdersCustomerDetails allows restaurants to see who placed their
+	// orders.
+	ScopeOrdersCustomerDetails = "orders:customer_details"
+)
+
+// Actor is the authenticated caller of a request, as identified by the API
+// gateway. End users have IDs of the form "user:<id>" and restaurant partners
+// have IDs of the form "restaurant:<id>".
+type Actor struct {
+	ID     string
+	Scopes []string
+}
+
+// UserActorID returns the actor ID of the end user with the given user ID.
+func UserActorID(userID int) string {
+	return "user:" + strconv.Itoa(userID)
+}
+
+// RestaurantActorID returns the actor ID of the restaurant partner with the
+// given restaurant ID.
+func RestaurantActorID(restaurantID int) string {
+	return "restaurant:" + strconv.Itoa(restaurantID)
+}
+
+// IsRestaurant reports whether the actor is the restaurant partner with the
+// given restaurant ID.
+func (a *Actor) IsRestaurant(restaurantID int) bool {
+	return a != nil && a.ID == RestaurantActorID(restaurantID)
+}
+
+// IsUser reports whether the actor is the end user with the given user ID.
+func (a *Actor) IsUser(userID int) bool {
+	return a != nil && a.ID == UserActorID(userID)
+}
+
+// HasScope reports whether the actor has been granted scope.
+func (a *Actor) HasScope(scope string) bool {
+	if a == nil {
+		return false
+	}
+
+	for _, s := range a.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,27 @@
+package models
+
+// CancellationReason is the reason code given when an order is cancelled.
+type CancellationReason string
+
+// The reason codes that an order can be cancelled with.
+const (
+	CancellationReasonChangedMind           CancellationReason = "changed_mind"
+	CancellationReasonOrderedByMistake      CancellationReason = "ordered_by_mistake"
+	CancellationReasonTakingTooLong         CancellationReason = "taking_too_long"
+	CancellationReasonRestaurantUnavailable CancellationReason = "restaurant_unavailable"
+	CancellationReasonOther                 CancellationReason = "other"
+)
+
+// IsValid reports whether r is one of the known reason codes.
+func (r CancellationReason) IsValid() bool {
+	switch r {
+	case CancellationReasonChangedMind,
+		CancellationReasonOrderedByMistake,
+		CancellationReasonTakingTooLong,
+		CancellationReasonRestaurantUnavailable,
+		CancellationReasonOther:
+		return true
+	}
+
+	return false
+}
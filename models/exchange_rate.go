@@ -0,0 +1,59 @@
+package models
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ExchangeRate is the number of units of QuoteCurrency that one unit of
+// BaseCurrency buys from EffectiveAt until the next rate for the same pair
+// takes effect. Rate is a decimal string so that no precision is lost.
+type ExchangeRate struct {
+	ID            int       `json:"-"`
+	BaseCurrency  Currency  `json:"base_currency"`
+	QuoteCurrency Currency  `json:"quote_currency"`
+	Rate          string    `json:"rate"`
+	EffectiveAt   time.Time `json:"effective_at"`
+}
+
+// ExchangeRates is a slice of ExchangeRate pointers.
+type ExchangeRates []*ExchangeRate
+
+// Validate checks that the currencies are known and that the rate is a
+// positive decimal number.
+func (r *ExchangeRate) Validate() error {
+	if !r.BaseCurrency.IsValid() {
+		return errors.Wrapf(ErrUnknownCurrency, "base currency %q", r.BaseCurrency)
+	}
+	if !r.QuoteCurrency.IsValid() {
+		return errors.Wrapf(ErrUnknownCurrency, "quote currency %q", r.QuoteCurrency)
+	}
+	if r.BaseCurrency == r.QuoteCurrency {
+		return errors.Errorf("rate from %s to itself", r.BaseCurrency)
+	}
+	if r.EffectiveAt.IsZero() {
+		return errors.Errorf("rate from %s to %s has no effective time", r.BaseCurrency, r.QuoteCurrency)
+	}
+
+	rat, err := r.Rat()
+	if err != nil {
+		return err
+	}
+	if rat.Sign() <= 0 {
+		return errors.Errorf("rate from %s to %s must be positive", r.BaseCurrency, r.QuoteCurrency)
+	}
+
+	return nil
+}
+
+// Rat returns the rate as an exact rational number.
+func (r *ExchangeRate) Rat() (*big.Rat, error) {
+	rat, ok := new(big.Rat).SetString(r.Rate)
+	if !ok {
+		return nil, errors.Errorf("invalid rate %q", r.Rate)
+	}
+
+	return rat, nil
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// IdempotencyKey is a request that was made with an Idempotency-Key header.
+// While the request is being handled the key is locked until LockedUntil;
+// once it has been handled the response is stored so that it can be replayed
+// to repeats of the request until ExpiresAt.
+type IdempotencyKey struct {
+	tableName struct{} `sql:"idempotency_keys"`
+
+	ID int
+	// Scope is the actor ID of the caller, so that different callers cannot
+	// see each other's responses by reusing a key.
+	Scope string
+	Key   string
+	// Fingerprint identifies the request's method, path, query and body.
+	Fingerprint         string
+	LockedUntil         time.Time
+	ResponseStatus      int
+	ResponseContentType string
+	ResponseBody        []byte
+	CreatedAt           time.Time
+	CompletedAt         *time.Time
+	ExpiresAt           time.Time
+}
+
+// IsCompleted reports whether a response has been stored for the key.
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.CompletedAt != nil
+}
@@ -0,0 +1,189 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrCurrencyMismatch is returned when amounts in different currencies are
+// combined.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Money is an amount in the minor unit of a currency, such as pence for GBP.
+type Money struct {
+	MinorUnits int64
+	Currency   Currency
+}
+
+// NewMoney creates an amount of minorUnits in currency.
+func NewMoney(minorUnits int64, currency Currency) Money {
+	return Money{MinorUnits: minorUnits, Currency: currency}
+}
+
+// ZeroMoney returns an amount of nothing in currency.
+func ZeroMoney(currency Currency) Money {
+	return Money{Currency: currency}
+}
+
+// Add returns m + o. Both amounts must be in the same currency.
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.checkCurrency(o); err != nil {
+		return Money{}, err
+	}
+
+	return Money{MinorUnits: m.MinorUnits + o.MinorUnits, Currency: m.Currency}, nil
+}
+
+// Sub returns m - o. Both amounts must be in the same currency.
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.checkCurrency(o); err != nil {
+		return Money{}, err
+	}
+
+	return Money{MinorUnits: m.MinorUnits - o.MinorUnits, Currency: m.Currency}, nil
+}
+
+// Cmp compares m and o and returns -1, 0 or +1. Both amounts must be in the
+// same currency.
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.checkCurrency(o); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case m.MinorUnits < o.MinorUnits:
+		return -1, nil
+	case m.MinorUnits > o.MinorUnits:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// IsPositive reports whether m is greater than zero.
+func (m Money) IsPositive() bool {
+	return m.MinorUnits > 0
+}
+
+// IsZero reports whether m is zero.
+func (m Money) IsZero() bool {
+	return m.MinorUnits == 0
+}
+
+func (m Money) checkCurrency(o Money) error {
+	if m.Currency != o.Currency {
+		return errors.Wrapf(ErrCurrencyMismatch, "%s and %s", m.Currency, o.Currency)
+	}
+
+	return nil
+}
+
+// Decimal formats the amount in major units with the currency's number of
+// decimal places, for example "25.00" for 2500 GBP minor units and "2500" for
+// 2500 JPY.
+func (m Money) Decimal() string {
+	exponent := m.Currency.Exponent()
+
+	units := m.MinorUnits
+	sign := ""
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+
+	digits := strconv.FormatInt(units, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	split := len(digits) - exponent
+	return sign + digits[:split] + "." + digits[split:]
+}
+
+// String formats the amount with its currency, for example "25.00 GBP".
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.Decimal(), m.Currency)
+}
+
+// moneyJSON is the JSON representation of Money.
+type moneyJSON struct {
+	MinorUnits int64    `json:"minor_units"`
+	Currency   Currency `json:"currency"`
+	Decimal    string   `json:"decimal"`
+}
+
+// MarshalJSON renders the amount in minor units together with its decimal
+// representation.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{
+		MinorUnits: m.MinorUnits,
+		Currency:   m.Currency,
+		Decimal:    m.Decimal(),
+	})
+}
+
+// UnmarshalJSON reads an amount from its minor units and currency. The
+// decimal representation is ignored.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	raw := moneyJSON{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	currency, err := ParseCurrency(string(raw.Currency))
+	if err != nil {
+		return err
+	}
+
+	m.MinorUnits = raw.MinorUnits
+	m.Currency = currency
+	return nil
+}
+
+// Convert returns m in currency to, using rate as the number of major units
+// of to that one major unit of m's currency buys. The result is rounded half
+// away from zero to the minor unit of to.
+func (m Money) Convert(rate *big.Rat, to Currency) Money {
+	// minor(to) = minor(from) / 10^exp(from) * rate * 10^exp(to)
+	amount := new(big.Rat).SetInt64(m.MinorUnits)
+	amount.Mul(amount, rate)
+	amount.Mul(amount, new(big.Rat).SetFrac(pow10(to.Exponent()), pow10(m.Currency.Exponent())))
+
+	return Money{MinorUnits: roundHalfAwayFromZero(amount), Currency: to}
+}
+
+// Div returns m divided by n, rounded half away from zero to the minor unit.
+// It is used for averages, so n must be positive.
+func (m Money) Div(n int64) Money {
+	return Money{
+		MinorUnits: roundHalfAwayFromZero(big.NewRat(m.MinorUnits, n)),
+		Currency:   m.Currency,
+	}
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func roundHalfAwayFromZero(r *big.Rat) int64 {
+	num := new(big.Int).Abs(r.Num())
+	denom := r.Denom()
+
+	// (2|num| + denom) / 2denom is |r| rounded half up.
+	rounded := new(big.Int).Mul(num, big.NewInt(2))
+	rounded.Add(rounded, denom)
+	rounded.Quo(rounded, new(big.Int).Mul(denom, big.NewInt(2)))
+
+	if r.Sign() < 0 {
+		rounded.Neg(rounded)
+	}
+	return rounded.Int64()
+}
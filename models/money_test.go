@@ -0,0 +1,108 @@
+package models_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestModels(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Models Suite")
+}
+
+var _ = Describe("Currency", func() {
+	Describe("ParseCurrency", func() {
+		It("normalises codes to upper case", func() {
+			currency, err := models.ParseCurrency(" gbp ")
+			Expect(err).To(BeNil())
+			Expect(currency).To(Equal(models.GBP))
+		})
+
+		It("rejects unknown codes", func() {
+			_, err := models.ParseCurrency("XYZ")
+			Expect(err).To(MatchError(`"XYZ": unknown currency`))
+		})
+	})
+
+	Describe("Exponent", func() {
+		It("knows the minor unit of each currency", func() {
+			Expect(models.GBP.Exponent()).To(Equal(2))
+			Expect(models.JPY.Exponent()).To(Equal(0))
+			Expect(models.Currency("KWD").Exponent()).To(Equal(3))
+		})
+	})
+})
+
+var _ = Describe("Money", func() {
+	Describe("arithmetic", func() {
+		It("adds and subtracts amounts in the same currency", func() {
+			sum, err := models.NewMoney(1000, models.GBP).Add(models.NewMoney(250, models.GBP))
+			Expect(err).To(BeNil())
+			Expect(sum).To(Equal(models.NewMoney(1250, models.GBP)))
+
+			difference, err := sum.Sub(models.NewMoney(1500, models.GBP))
+			Expect(err).To(BeNil())
+			Expect(difference).To(Equal(models.NewMoney(-250, models.GBP)))
+		})
+
+		It("rejects amounts in different currencies", func() {
+			_, err := models.NewMoney(1000, models.GBP).Add(models.NewMoney(1000, models.EUR))
+			Expect(err).To(MatchError("GBP and EUR: currency mismatch"))
+
+			_, err = models.NewMoney(1000, models.GBP).Cmp(models.NewMoney(1000, models.JPY))
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("Decimal", func() {
+		It("formats amounts using the minor unit of the currency", func() {
+			Expect(models.NewMoney(2500, models.GBP).Decimal()).To(Equal("25.00"))
+			Expect(models.NewMoney(5, models.GBP).Decimal()).To(Equal("0.05"))
+			Expect(models.NewMoney(-1999, models.EUR).Decimal()).To(Equal("-19.99"))
+			Expect(models.NewMoney(2500, models.JPY).Decimal()).To(Equal("2500"))
+			Expect(models.NewMoney(1500, "KWD").Decimal()).To(Equal("1.500"))
+		})
+	})
+
+	Describe("JSON", func() {
+		It("renders both the minor units and the decimal amount", func() {
+			encoded, err := json.Marshal(models.NewMoney(2500, models.GBP))
+			Expect(err).To(BeNil())
+			Expect(encoded).To(MatchJSON(`{"minor_units": 2500, "currency": "GBP", "decimal": "25.00"}`))
+		})
+
+		It("reads the minor units and validates the currency", func() {
+			money := models.Money{}
+			Expect(json.Unmarshal([]byte(`{"minor_units": 500, "currency": "eur"}`), &money)).To(Succeed())
+			Expect(money).To(Equal(models.NewMoney(500, models.EUR)))
+
+			Expect(json.Unmarshal([]byte(`{"minor_units": 500, "currency": "ABC"}`), &money)).NotTo(Succeed())
+		})
+	})
+	Describe("Convert", func() {
+		It("converts between currencies with different minor units", func() {
+			Expect(models.NewMoney(1000, models.GBP).Convert(big.NewRat(18925, 100), models.JPY)).
+				To(Equal(models.NewMoney(1893, models.JPY)))
+			Expect(models.NewMoney(1893, models.JPY).Convert(big.NewRat(1, 190), models.GBP)).
+				To(Equal(models.NewMoney(996, models.GBP)))
+		})
+
+		It("rounds half away from zero", func() {
+			Expect(models.NewMoney(1, models.EUR).Convert(big.NewRat(1, 2), models.USD)).
+				To(Equal(models.NewMoney(1, models.USD)))
+			Expect(models.NewMoney(-1, models.EUR).Convert(big.NewRat(1, 2), models.USD)).
+				To(Equal(models.NewMoney(-1, models.USD)))
+		})
+	})
+	Describe("Div", func() {
+		It("rounds averages to the minor unit", func() {
+			Expect(models.NewMoney(1000, models.GBP).Div(3)).To(Equal(models.NewMoney(333, models.GBP)))
+			Expect(models.NewMoney(1001, models.JPY).Div(2)).To(Equal(models.NewMoney(501, models.JPY)))
+		})
+	})
+})
@@ -1,17 +1,85 @@
 package models
 
-import "time"
+import (
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/orm"
+)
 
 // Order is the model representation of an order in the data model.
 type Order struct {
-	ID           int         `json:"id"`
-	UserID       int         `json:"-"`
-	RestaurantID int         `json:"-"`
-	Restaurant   *Restaurant `json:"restaurant" sql:"-"`
-	Total        int         `json:"total"`
-	CurrencyCode string      `json:"currency_code"`
-	PlacedAt     time.Time   `json:"placed_at"`
+	ID                 int                `json:"id"`
+	UserID             int                `json:"-"`
+	RestaurantID       int                `json:"-"`
+	Restaurant         *Restaurant        `json:"restaurant" sql:"-"`
+	Items              OrderItems         `json:"items" sql:"-"`
+	StatusHistory      OrderStatusChanges `json:"status_history" sql:"-"`
+	Refunds            Refunds            `json:"refunds" sql:"-"`
+	Total              Money              `json:"total" sql:"-"`
+	NetTotal           *Money             `json:"net_total,omitempty" sql:"-"`
+	TotalMinorUnits    int64              `json:"-" sql:"total"`
+	CurrencyCode       Currency           `json:"-"`
+	Status             OrderStatus        `json:"status" sql:"default:'placed'"`
+	PlacedAt           time.Time          `json:"placed_at"`
+	CancelledAt        *time.Time         `json:"cancelled_at,omitempty"`
+	CancelledBy        string             `json:"cancelled_by,omitempty"`
+	CancellationReason CancellationReason `json:"cancellation_reason,omitempty"`
+	// Version is incremented every time the order changes. It is used to
+	// detect concurrent changes and as the order's ETag.
+	Version int `json:"version" sql:"default:1"`
+	// DeletedAt is set when an admin deletes the order. Deleted orders are
+	// left out of every query made through the model.
+	DeletedAt time.Time `json:"-" pg:",soft_delete"`
+	// AnonymisedAt is set when the retention job removes the personal data
+	// of the order.
+	AnonymisedAt *time.Time `json:"-"`
 }
 
 // Orders is a slice of Order pointers.
 type Orders []*Order
+
+// IDs returns the IDs of the orders, in order.
+func (o Orders) IDs() []int {
+	ids := make([]int, 0, len(o))
+	for _, order := range o {
+		ids = append(ids, order.ID)
+	}
+	return ids
+}
+
+// SetRefunds attaches the refunds of the order and updates its net total.
+func (o *Order) SetRefunds(refunds Refunds) error {
+	refunded, err := refunds.Total(o.Total.Currency)
+	if err != nil {
+		return err
+	}
+
+	net, err := o.Total.Sub(refunded)
+	if err != nil {
+		return err
+	}
+
+	o.Refunds = refunds
+	o.NetTotal = &net
+	return nil
+}
+
+// AfterSelect builds Total from the total and currency_code columns that it
+// is stored in.
+func (o *Order) AfterSelect(ctx context.Context, db orm.DB) error {
+	o.Total = NewMoney(o.TotalMinorUnits, o.CurrencyCode)
+	return nil
+}
+
+// BeforeInsert copies Total into the columns that it is stored in.
+func (o *Order) BeforeInsert(ctx context.Context, db orm.DB) error {
+	o.TotalMinorUnits = o.Total.MinorUnits
+	o.CurrencyCode = o.Total.Currency
+	return nil
+}
+
+// BeforeUpdate copies Total into the columns that it is stored in.
+func (o *Order) BeforeUpdate(ctx context.Context, db orm.DB) error {
+	return o.BeforeInsert(ctx, db)
+}
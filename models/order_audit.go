@@ -0,0 +1,98 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// OrderAuditAction names a kind of change made to an order.
+type OrderAuditAction string
+
+// The changes to orders that are recorded in the audit log.
+const (
+	OrderAuditActionCancel OrderAuditAction = "cancel"
+	OrderAuditActionRefund OrderAuditAction = "refund"
+	OrderAuditActionDelete OrderAuditAction = "delete"
+)
+
+// OrderAuditEntry records a change made to an order: who made it, in which
+// request, and the fields of the order that it changed. Before and After are
+// JSON objects that hold only the changed fields, as they were before and
+// after the change. Entries are never changed once they are written.
+type OrderAuditEntry struct {
+	tableName struct{} `sql:"order_audit"`
+
+	ID        int64            `json:"id"`
+	OrderID   int              `json:"order_id"`
+	ActorID   string           `json:"actor_id"`
+	Action    OrderAuditAction `json:"action"`
+	Before    json.RawMessage  `json:"before"`
+	After     json.RawMessage  `json:"after"`
+	RequestID string           `json:"request_id,omitempty" sql:",notnull"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// OrderAuditEntries is a slice of OrderAuditEntry pointers.
+type OrderAuditEntries []*OrderAuditEntry
+
+// NewOrderAuditEntry returns the audit entry for a change by actorID that
+// turned the order before into the order after.
+func NewOrderAuditEntry(before, after *Order, action OrderAuditAction, actorID, requestID string, at time.Time) (*OrderAuditEntry, error) {
+	oldFields, newFields := auditedFields(before), auditedFields(after)
+
+	changedBefore := make(map[string]json.RawMessage)
+	changedAfter := make(map[string]json.RawMessage)
+	for name, value := range newFields {
+		if !bytes.Equal(value, oldFields[name]) {
+			changedBefore[name] = oldFields[name]
+			changedAfter[name] = value
+		}
+	}
+
+	encodedBefore, err := json.Marshal(changedBefore)
+	if err != nil {
+		return nil, err
+	}
+	encodedAfter, err := json.Marshal(changedAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderAuditEntry{
+		OrderID:   after.ID,
+		ActorID:   actorID,
+		Action:    action,
+		Before:    encodedBefore,
+		After:     encodedAfter,
+		RequestID: requestID,
+		CreatedAt: at,
+	}, nil
+}
+
+// auditedFields returns the JSON encoding of each field of an order that can
+// change after the order is placed.
+func auditedFields(order *Order) map[string]json.RawMessage {
+	var deletedAt *time.Time
+	if !order.DeletedAt.IsZero() {
+		deletedAt = &order.DeletedAt
+	}
+
+	fields := map[string]interface{}{
+		"status":              order.Status,
+		"cancelled_at":        order.CancelledAt,
+		"cancelled_by":        order.CancelledBy,
+		"cancellation_reason": order.CancellationReason,
+		"net_total":           order.NetTotal,
+		"deleted_at":          deletedAt,
+		"version":             order.Version,
+	}
+
+	encoded := make(map[string]json.RawMessage, len(fields))
+	for name, value := range fields {
+		// None of the fields can fail to encode.
+		encoded[name], _ = json.Marshal(value)
+	}
+
+	return encoded
+}
@@ -0,0 +1,40 @@
+package models_test
+
+import (
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OrderAuditEntry", func() {
+	var now = time.Date(2019, 5, 14, 12, 0, 0, 0, time.UTC)
+
+	Describe("NewOrderAuditEntry", func() {
+		It("records only the fields that changed", func() {
+			before := &models.Order{ID: 3, Status: models.OrderStatusPlaced, Total: models.NewMoney(2500, models.GBP), Version: 1}
+			after := *before
+			after.Status = models.OrderStatusCancelled
+			after.CancelledBy = "agent:1"
+			after.Version = 2
+
+			entry, err := models.NewOrderAuditEntry(before, &after, models.OrderAuditActionCancel, "agent:1", "req-1", now)
+			Expect(err).To(BeNil())
+			Expect(entry.OrderID).To(Equal(3))
+			Expect(entry.ActorID).To(Equal("agent:1"))
+			Expect(entry.RequestID).To(Equal("req-1"))
+			Expect(entry.Before).To(MatchJSON(`{"status": "placed", "cancelled_by": "", "version": 1}`))
+			Expect(entry.After).To(MatchJSON(`{"status": "cancelled", "cancelled_by": "agent:1", "version": 2}`))
+		})
+
+		It("records empty objects when nothing changed", func() {
+			order := &models.Order{ID: 3, Version: 1}
+
+			entry, err := models.NewOrderAuditEntry(order, order, models.OrderAuditActionDelete, "agent:1", "", now)
+			Expect(err).To(BeNil())
+			Expect(entry.Before).To(MatchJSON(`{}`))
+			Expect(entry.After).To(MatchJSON(`{}`))
+		})
+	})
+})
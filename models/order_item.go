@@ -0,0 +1,14 @@
+package models
+
+// OrderItem is a single line item of an order. UnitPrice is in minor units of
+// the order's currency.
+type OrderItem struct {
+	ID        int    `json:"-"`
+	OrderID   int    `json:"-"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice int64  `json:"unit_price"`
+}
+
+// OrderItems is a slice of OrderItem pointers.
+type OrderItems []*OrderItem
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// StatsPeriod is the length of the buckets that orders are counted in.
+type StatsPeriod string
+
+// The periods that order counts can be grouped by.
+const (
+	StatsPeriodWeek  StatsPeriod = "week"
+	StatsPeriodMonth StatsPeriod = "month"
+)
+
+// ParseStatsPeriod validates a stats period.
+func ParseStatsPeriod(period string) (StatsPeriod, error) {
+	switch p := StatsPeriod(period); p {
+	case StatsPeriodWeek, StatsPeriodMonth:
+		return p, nil
+	default:
+		return "", errors.Errorf("unknown stats period %q", period)
+	}
+}
+
+// OrderStats summarises a user's orders. Cancelled orders are not counted and
+// spend is net of refunds.
+type OrderStats struct {
+	UserID          int                `json:"user_id"`
+	OrderCount      int                `json:"order_count"`
+	SpendByCurrency []*CurrencySpend   `json:"spend_by_currency"`
+	TopRestaurants  []*RestaurantStats `json:"top_restaurants"`
+	Period          StatsPeriod        `json:"period"`
+	OrdersPerPeriod []*PeriodCount     `json:"orders_per_period"`
+}
+
+// CurrencySpend is how much was spent in one currency and over how many
+// orders.
+type CurrencySpend struct {
+	Currency          Currency `json:"currency"`
+	OrderCount        int      `json:"order_count"`
+	Total             Money    `json:"total"`
+	AverageOrderValue Money    `json:"average_order_value"`
+}
+
+// RestaurantStats is how often a user ordered from a restaurant and how much
+// they spent there in each currency.
+type RestaurantStats struct {
+	RestaurantID int         `json:"restaurant_id"`
+	Restaurant   *Restaurant `json:"restaurant"`
+	OrderCount   int         `json:"order_count"`
+	Spend        []Money     `json:"spend"`
+}
+
+// SpendIn returns the amount spent at the restaurant in currency.
+func (s *RestaurantStats) SpendIn(currency Currency) Money {
+	for _, spend := range s.Spend {
+		if spend.Currency == currency {
+			return spend
+		}
+	}
+
+	return ZeroMoney(currency)
+}
+
+// PeriodCount is the number of orders placed in the week or month starting at
+// Start, in UTC.
+type PeriodCount struct {
+	Start      time.Time `json:"start"`
+	OrderCount int       `json:"order_count"`
+}
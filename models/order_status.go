@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// OrderStatus is the stage of its lifecycle that an order is in.
+type OrderStatus string
+
+// The statuses that an order can move through.
+const (
+	OrderStatusPlaced     OrderStatus = "placed"
+	OrderStatusAccepted   OrderStatus = "accepted"
+	OrderStatusPreparing  OrderStatus = "preparing"
+	OrderStatusDispatched OrderStatus = "dispatched"
+	OrderStatusDelivered  OrderStatus = "delivered"
+	OrderStatusCancelled  OrderStatus = "cancelled"
+)
+
+// OrderStatusChange records an order moving into a status.
+type OrderStatusChange struct {
+	ID        int         `json:"-"`
+	OrderID   int         `json:"-"`
+	Status    OrderStatus `json:"status"`
+	ChangedAt time.Time   `json:"changed_at"`
+}
+
+// OrderStatusChanges is a slice of OrderStatusChange pointers.
+type OrderStatusChanges []*OrderStatusChange
+
+// IsCancellable reports whether an order in this status may still be
+// cancelled. Once a restaurant starts preparing an order it can no longer be
+// cancelled.
+func (s OrderStatus) IsCancellable() bool {
+	return s == OrderStatusPlaced || s == OrderStatusAccepted
+}
+
+// ParseOrderStatus validates an order status.
+func ParseOrderStatus(status string) (OrderStatus, error) {
+	switch s := OrderStatus(status); s {
+	case OrderStatusPlaced, OrderStatusAccepted, OrderStatusPreparing,
+		OrderStatusDispatched, OrderStatusDelivered, OrderStatusCancelled:
+		return s, nil
+	default:
+		return "", errors.Errorf("unknown order status %q", status)
+	}
+}
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventType names a kind of order domain event.
+type EventType string
+
+// The order domain events that are published to other services.
+const (
+	EventTypeOrderPlaced        EventType = "OrderPlaced"
+	EventTypeOrderStatusChanged EventType = "OrderStatusChanged"
+	EventTypeOrderRefunded      EventType = "OrderRefunded"
+)
+
+// The version of each event type's payload. A version is only incremented for
+// changes that consumers of the previous version cannot handle.
+const (
+	OrderPlacedSchemaVersion        = 1
+	OrderStatusChangedSchemaVersion = 1
+	OrderRefundedSchemaVersion      = 1
+)
+
+// OutboxStatus is the delivery state of an OutboxEvent.
+type OutboxStatus string
+
+// The delivery states of an OutboxEvent.
+const (
+	// OutboxStatusPending events are waiting to be delivered, possibly after
+	// failed attempts.
+	OutboxStatusPending OutboxStatus = "pending"
+	// OutboxStatusDelivered events have been accepted by the publisher.
+	OutboxStatusDelivered OutboxStatus = "delivered"
+	// OutboxStatusDead events failed too many times and are no longer
+	// retried.
+	OutboxStatusDead OutboxStatus = "dead"
+)
+
+// OutboxEvent is a domain event that is stored in the same transaction as the
+// change it describes, and published afterwards. Its JSON encoding is the
+// envelope that publishers deliver.
+type OutboxEvent struct {
+	ID            int64     `json:"id"`
+	Type          EventType `json:"type"`
+	SchemaVersion int       `json:"schema_version"`
+	OrderID       int       `json:"order_id"`
+	// UserID is the user who placed the order. It is kept out of the
+	// envelope, which restaurants receive, and used to stream events to the
+	// user.
+	UserID        int             `json:"-"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Status        OutboxStatus    `json:"-" sql:"default:'pending'"`
+	Attempts      int             `json:"-" sql:",notnull"`
+	LastError     string          `json:"-"`
+	NextAttemptAt time.Time       `json:"-"`
+	DeliveredAt   *time.Time      `json:"-"`
+}
+
+// OutboxEvents is a slice of OutboxEvent pointers.
+type OutboxEvents []*OutboxEvent
+
+// OrderPlacedPayload is the version 1 payload of an OrderPlaced event.
+type OrderPlacedPayload struct {
+	OrderID      int       `json:"order_id"`
+	UserID       int       `json:"user_id"`
+	RestaurantID int       `json:"restaurant_id"`
+	Total        Money     `json:"total"`
+	PlacedAt     time.Time `json:"placed_at"`
+}
+
+// OrderStatusChangedPayload is the version 1 payload of an OrderStatusChanged
+// event.
+type OrderStatusChangedPayload struct {
+	OrderID            int                `json:"order_id"`
+	Status             OrderStatus        `json:"status"`
+	ChangedAt          time.Time          `json:"changed_at"`
+	CancellationReason CancellationReason `json:"cancellation_reason,omitempty"`
+	OrderVersion       int                `json:"order_version"`
+}
+
+// OrderRefundedPayload is the version 1 payload of an OrderRefunded event.
+type OrderRefundedPayload struct {
+	OrderID      int       `json:"order_id"`
+	RefundID     int       `json:"refund_id"`
+	Amount       Money     `json:"amount"`
+	NetTotal     Money     `json:"net_total"`
+	Reason       string    `json:"reason"`
+	RefundedAt   time.Time `json:"refunded_at"`
+	OrderVersion int       `json:"order_version"`
+}
+
+// NewOrderPlacedEvent returns the event for an order having been placed.
+func NewOrderPlacedEvent(order *Order) (*OutboxEvent, error) {
+	return newOutboxEvent(EventTypeOrderPlaced, OrderPlacedSchemaVersion, order, order.PlacedAt, &OrderPlacedPayload{
+		OrderID:      order.ID,
+		UserID:       order.UserID,
+		RestaurantID: order.RestaurantID,
+		Total:        order.Total,
+		PlacedAt:     order.PlacedAt,
+	})
+}
+
+// NewOrderStatusChangedEvent returns the event for an order having moved into
+// change's status.
+func NewOrderStatusChangedEvent(order *Order, change *OrderStatusChange) (*OutboxEvent, error) {
+	payload := &OrderStatusChangedPayload{
+		OrderID:      order.ID,
+		Status:       change.Status,
+		ChangedAt:    change.ChangedAt,
+		OrderVersion: order.Version,
+	}
+	if change.Status == OrderStatusCancelled {
+		payload.CancellationReason = order.CancellationReason
+	}
+
+	return newOutboxEvent(EventTypeOrderStatusChanged, OrderStatusChangedSchemaVersion, order, change.ChangedAt, payload)
+}
+
+// NewOrderRefundedEvent returns the event for refund having been made. The
+// order's net total must include the refund.
+func NewOrderRefundedEvent(order *Order, refund *Refund) (*OutboxEvent, error) {
+	return newOutboxEvent(EventTypeOrderRefunded, OrderRefundedSchemaVersion, order, refund.CreatedAt, &OrderRefundedPayload{
+		OrderID:      order.ID,
+		RefundID:     refund.ID,
+		Amount:       refund.Amount,
+		NetTotal:     *order.NetTotal,
+		Reason:       refund.Reason,
+		RefundedAt:   refund.CreatedAt,
+		OrderVersion: order.Version,
+	})
+}
+
+func newOutboxEvent(eventType EventType, schemaVersion int, order *Order, occurredAt time.Time, payload interface{}) (*OutboxEvent, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxEvent{
+		Type:          eventType,
+		SchemaVersion: schemaVersion,
+		OrderID:       order.ID,
+		UserID:        order.UserID,
+		Payload:       encoded,
+		OccurredAt:    occurredAt,
+		Status:        OutboxStatusPending,
+		NextAttemptAt: occurredAt,
+	}, nil
+}
+
+// MarkDelivered records that the event was published at t.
+func (e *OutboxEvent) MarkDelivered(t time.Time) {
+	e.Status = OutboxStatusDelivered
+	e.DeliveredAt = &t
+	e.LastError = ""
+}
+
+// MarkFailed records a failed attempt to publish the event at t. The event is
+// retried after backoff, or marked dead once it has been attempted
+// maxAttempts times.
+func (e *OutboxEvent) MarkFailed(err error, t time.Time, maxAttempts int, backoff time.Duration) {
+	e.Attempts++
+	e.LastError = err.Error()
+	e.NextAttemptAt = t.Add(backoff)
+	if e.Attempts >= maxAttempts {
+		e.Status = OutboxStatusDead
+	}
+}
@@ -0,0 +1,53 @@
+package models_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OutboxEvent", func() {
+	var now = time.Date(2019, 5, 3, 12, 0, 0, 0, time.UTC)
+
+	Describe("NewOrderPlacedEvent", func() {
+		It("versions the payload and schedules the event for delivery", func() {
+			event, err := models.NewOrderPlacedEvent(&models.Order{
+				ID:           3,
+				UserID:       5,
+				RestaurantID: 9,
+				Total:        models.NewMoney(2500, models.GBP),
+				PlacedAt:     now,
+			})
+			Expect(err).To(BeNil())
+			Expect(event.Type).To(Equal(models.EventTypeOrderPlaced))
+			Expect(event.UserID).To(Equal(5))
+			Expect(event.SchemaVersion).To(Equal(models.OrderPlacedSchemaVersion))
+			Expect(event.Status).To(Equal(models.OutboxStatusPending))
+			Expect(event.NextAttemptAt).To(Equal(now))
+			Expect(event.Payload).To(MatchJSON(`{
+				"order_id": 3,
+				"user_id": 5,
+				"restaurant_id": 9,
+				"total": {"minor_units": 2500, "currency": "GBP", "decimal": "25.00"},
+				"placed_at": "2019-05-03T12:00:00Z"
+			}`))
+		})
+	})
+
+	Describe("MarkFailed", func() {
+		It("retries the event until it runs out of attempts", func() {
+			event := &models.OutboxEvent{Status: models.OutboxStatusPending}
+
+			event.MarkFailed(errors.New("timeout"), now, 2, time.Second)
+			Expect(event.Status).To(Equal(models.OutboxStatusPending))
+			Expect(event.NextAttemptAt).To(Equal(now.Add(time.Second)))
+
+			event.MarkFailed(errors.New("timeout"), now, 2, time.Second)
+			Expect(event.Status).To(Equal(models.OutboxStatusDead))
+			Expect(event.LastError).To(Equal("timeout"))
+		})
+	})
+})
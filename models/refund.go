@@ -0,0 +1,51 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/orm"
+)
+
+// Refund is an amount of an order's total that has been paid back to the
+// user. An order can be refunded in several parts.
+type Refund struct {
+	ID               int       `json:"id"`
+	OrderID          int       `json:"-"`
+	Amount           Money     `json:"amount" sql:"-"`
+	AmountMinorUnits int64     `json:"-" sql:"amount"`
+	CurrencyCode     Currency  `json:"-"`
+	Reason           string    `json:"reason"`
+	ActorID          string    `json:"actor_id"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Refunds is a slice of Refund pointers.
+type Refunds []*Refund
+
+// Total returns the sum of the refunded amounts in currency. It fails if any
+// refund is in a different currency.
+func (r Refunds) Total(currency Currency) (Money, error) {
+	total := ZeroMoney(currency)
+	for _, refund := range r {
+		var err error
+		if total, err = total.Add(refund.Amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// AfterSelect builds Amount from the amount and currency_code columns that it
+// is stored in.
+func (r *Refund) AfterSelect(ctx context.Context, db orm.DB) error {
+	r.Amount = NewMoney(r.AmountMinorUnits, r.CurrencyCode)
+	return nil
+}
+
+// BeforeInsert copies Amount into the columns that it is stored in.
+func (r *Refund) BeforeInsert(ctx context.Context, db orm.DB) error {
+	r.AmountMinorUnits = r.Amount.MinorUnits
+	r.CurrencyCode = r.Amount.Currency
+	return nil
+}
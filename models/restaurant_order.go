@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidCursor is returned for page cursors that were not issued by
+// OrderCursor.Encode.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// RestaurantOrderFilter selects the orders of a restaurant, newest first.
+type RestaurantOrderFilter struct {
+	RestaurantID int
+	// From and To bound PlacedAt to [From, To) when set.
+	From *time.Time
+	To   *time.Time
+	// Statuses limits the orders to those in any of the statuses when set.
+	Statuses []OrderStatus
+	Limit    int
+	// After continues from the last order of the previous page.
+	After *OrderCursor
+}
+
+// OrderCursor is the position of an order in a newest first listing.
+type OrderCursor struct {
+	PlacedAt time.Time
+	ID       int
+}
+
+// CursorAfter returns the cursor that continues a listing after order.
+func CursorAfter(order *Order) *OrderCursor {
+	return &OrderCursor{PlacedAt: order.PlacedAt, ID: order.ID}
+}
+
+// Encode returns the cursor as an opaque string for use in URLs.
+func (c *OrderCursor) Encode() string {
+	raw := c.PlacedAt.UTC().Format(time.RFC3339Nano) + "," + strconv.Itoa(c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseOrderCursor decodes a cursor returned by OrderCursor.Encode.
+func ParseOrderCursor(s string) (*OrderCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), ",", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+
+	placedAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &OrderCursor{PlacedAt: placedAt, ID: id}, nil
+}
+
+// RestaurantOrder is an order as shown to the restaurant it was placed with.
+// Customer identifiers are only included for callers that may see them.
+type RestaurantOrder struct {
+	*Order
+	UserID      *int   `json:"user_id,omitempty"`
+	CancelledBy string `json:"cancelled_by,omitempty"`
+}
+
+// NewRestaurantOrder wraps order for a restaurant. Unless withCustomer is
+// set, the ID of the user who placed the order is left out, as is
+// CancelledBy when the user cancelled the order themselves.
+func NewRestaurantOrder(order *Order, withCustomer bool) *RestaurantOrder {
+	ro := &RestaurantOrder{Order: order}
+	if withCustomer {
+		userID := order.UserID
+		ro.UserID = &userID
+		ro.CancelledBy = order.CancelledBy
+	} else if !strings.HasPrefix(order.CancelledBy, "user:") {
+		ro.CancelledBy = order.CancelledBy
+	}
+
+	return ro
+}
+
+// RestaurantOrderPage is one page of a restaurant's orders. NextCursor is
+// empty on the last page.
+type RestaurantOrderPage struct {
+	Orders     []*RestaurantOrder `json:"orders"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
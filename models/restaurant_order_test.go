@@ -0,0 +1,48 @@
+package models_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RestaurantOrder", func() {
+	var order *models.Order
+
+	BeforeEach(func() {
+		order = &models.Order{ID: 3, UserID: 5, Total: models.NewMoney(1000, models.GBP), CancelledBy: "user:5"}
+	})
+
+	It("leaves out the customer unless asked to include them", func() {
+		encoded, err := json.Marshal(models.NewRestaurantOrder(order, false))
+		Expect(err).To(BeNil())
+		Expect(string(encoded)).NotTo(ContainSubstring("user"))
+
+		encoded, err = json.Marshal(models.NewRestaurantOrder(order, true))
+		Expect(err).To(BeNil())
+		Expect(string(encoded)).To(ContainSubstring(`"user_id":5`))
+		Expect(string(encoded)).To(ContainSubstring(`"cancelled_by":"user:5"`))
+	})
+
+	It("keeps support agents that cancelled the order", func() {
+		order.CancelledBy = "agent:1"
+		Expect(models.NewRestaurantOrder(order, false).CancelledBy).To(Equal("agent:1"))
+	})
+})
+
+var _ = Describe("OrderCursor", func() {
+	It("round trips through its encoding", func() {
+		cursor := &models.OrderCursor{PlacedAt: time.Date(2019, 4, 20, 12, 0, 0, 1000, time.UTC), ID: 42}
+		parsed, err := models.ParseOrderCursor(cursor.Encode())
+		Expect(err).To(BeNil())
+		Expect(parsed).To(Equal(cursor))
+	})
+
+	It("rejects cursors that it did not issue", func() {
+		_, err := models.ParseOrderCursor("not-a-cursor")
+		Expect(err).To(Equal(models.ErrInvalidCursor))
+	})
+})
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// AnonymousUserID replaces the user ID of orders, and of their events, whose
+// personal data has been removed.
+const AnonymousUserID = 0
+
+// RetentionAction is what the retention job does with orders that are older
+// than the retention period.
+type RetentionAction string
+
+// The actions that a retention policy can take.
+const (
+	// RetentionActionAnonymise keeps the orders for reporting but removes the
+	// user that placed them and free-text that may identify the user.
+	RetentionActionAnonymise RetentionAction = "anonymise"
+	// RetentionActionDelete removes the orders and everything recorded about
+	// them.
+	RetentionActionDelete RetentionAction = "delete"
+)
+
+// IsValid reports whether a is one of the known retention actions.
+func (a RetentionAction) IsValid() bool {
+	switch a {
+	case RetentionActionAnonymise, RetentionActionDelete:
+		return true
+	}
+
+	return false
+}
+
+// RetentionPolicy describes how long orders are kept and what happens to them
+// afterwards.
+type RetentionPolicy struct {
+	// Years is how many years after it was placed an order is kept for.
+	Years  int
+	Action RetentionAction
+	// BatchSize is the number of orders handled in each transaction.
+	BatchSize int
+}
+
+// Cutoff returns the time before which orders placed are past the retention
+// period at now.
+func (p RetentionPolicy) Cutoff(now time.Time) time.Time {
+	return now.AddDate(-p.Years, 0, 0)
+}
+
+// RetentionReport is the result of a run of the retention job. In a dry run
+// Orders is the number of orders that would have been handled and no batches
+// are run.
+type RetentionReport struct {
+	Action  RetentionAction `json:"action"`
+	Cutoff  time.Time       `json:"cutoff"`
+	DryRun  bool            `json:"dry_run"`
+	Orders  int             `json:"orders"`
+	Batches int             `json:"batches"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// SpendSummary is the total that a user has spent across their orders,
+// expressed in a single currency.
+type SpendSummary struct {
+	UserID     int      `json:"user_id"`
+	Currency   Currency `json:"currency"`
+	Total      Money    `json:"total"`
+	OrderCount int      `json:"order_count"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// UserDataExport is everything that the service stores about a user, gathered
+// to answer a data subject access request. The orders include deleted ones
+// and carry their restaurant, items, status history and refunds.
+type UserDataExport struct {
+	UserID            int
+	GeneratedAt       time.Time
+	Orders            Orders
+	WebhookDeliveries WebhookDeliveries
+}
+
+// UserErasure records that the personal data of a user's orders was erased
+// at the user's request. It is kept as evidence that the request was handled.
+type UserErasure struct {
+	ID           int       `json:"id"`
+	UserID       int       `json:"user_id"`
+	ActorID      string    `json:"actor_id"`
+	OrdersErased int       `json:"orders_erased" sql:",notnull"`
+	ErasedAt     time.Time `json:"erased_at"`
+}
+
+// ErasureVerification is the result of checking that nothing stored
+// identifies a user any more. Remaining holds the number of rows of each
+// table that still do.
+type ErasureVerification struct {
+	UserID      int            `json:"user_id"`
+	Erased      bool           `json:"erased"`
+	Remaining   map[string]int `json:"remaining"`
+	LastErasure *UserErasure   `json:"last_erasure,omitempty"`
+}
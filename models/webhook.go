@@ -0,0 +1,148 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WebhookSubscription asks for the events about a restaurant's orders to be
+// posted to URL. Deliveries are signed with Secret so that the restaurant can
+// check where they came from.
+type WebhookSubscription struct {
+	ID           int    `json:"id"`
+	RestaurantID int    `json:"restaurant_id"`
+	URL          string `json:"url"`
+	// EventTypes limits the events that are delivered. When it is empty,
+	// every event is delivered.
+	EventTypes []EventType `json:"event_types" sql:",array"`
+	Secret     string      `json:"-"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// WebhookSubscriptions is a slice of WebhookSubscription pointers.
+type WebhookSubscriptions []*WebhookSubscription
+
+// Wants reports whether events of type t should be delivered to the
+// subscription.
+func (s *WebhookSubscription) Wants(t EventType) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+
+	for _, wanted := range s.EventTypes {
+		if wanted == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseEventType returns the EventType named by s.
+func ParseEventType(s string) (EventType, error) {
+	switch t := EventType(s); t {
+	case EventTypeOrderPlaced, EventTypeOrderStatusChanged, EventTypeOrderRefunded:
+		return t, nil
+	}
+
+	return "", errors.Errorf("%q: unknown event type", s)
+}
+
+// WebhookDeliveryStatus is the state of a WebhookDelivery.
+type WebhookDeliveryStatus string
+
+// The states of a WebhookDelivery.
+const (
+	// WebhookDeliveryStatusPending deliveries are waiting to be attempted,
+	// possibly after failed attempts.
+	WebhookDeliveryStatusPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliveryStatusSucceeded deliveries got a 2xx response.
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryStatusFailed deliveries failed too many times and are no
+	// longer retried. They can still be replayed.
+	WebhookDeliveryStatusFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is an entry in the delivery log: one event to be posted to
+// one subscription, along with the outcome of the latest attempt.
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	SubscriptionID int                   `json:"subscription_id"`
+	EventID        int64                 `json:"event_id"`
+	EventType      EventType             `json:"event_type"`
+	Body           json.RawMessage       `json:"body"`
+	Status         WebhookDeliveryStatus `json:"status" sql:"default:'pending'"`
+	Attempts       int                   `json:"attempts" sql:",notnull"`
+	ResponseStatus int                   `json:"response_status,omitempty"`
+	LastError      string                `json:"last_error,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time            `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	// ReplayOf is the ID of the delivery that this one replays.
+	ReplayOf *int64 `json:"replay_of,omitempty"`
+}
+
+// WebhookDeliveries is a slice of WebhookDelivery pointers.
+type WebhookDeliveries []*WebhookDelivery
+
+// NewWebhookDelivery returns a pending delivery of event to subscription,
+// due at t.
+func NewWebhookDelivery(subscription *WebhookSubscription, event *OutboxEvent, t time.Time) (*WebhookDelivery, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookDelivery{
+		SubscriptionID: subscription.ID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Body:           body,
+		Status:         WebhookDeliveryStatusPending,
+		CreatedAt:      t,
+		NextAttemptAt:  t,
+	}, nil
+}
+
+// Replay returns a new pending delivery of the same body, due at t.
+func (d *WebhookDelivery) Replay(t time.Time) *WebhookDelivery {
+	id := d.ID
+	return &WebhookDelivery{
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Body:           d.Body,
+		Status:         WebhookDeliveryStatusPending,
+		CreatedAt:      t,
+		NextAttemptAt:  t,
+		ReplayOf:       &id,
+	}
+}
+
+// MarkSucceeded records that the delivery got the 2xx responseStatus at t.
+func (d *WebhookDelivery) MarkSucceeded(responseStatus int, t time.Time) {
+	d.Attempts++
+	d.Status = WebhookDeliveryStatusSucceeded
+	d.ResponseStatus = responseStatus
+	d.LastError = ""
+	d.LastAttemptAt = &t
+	d.DeliveredAt = &t
+}
+
+// MarkFailed records a failed attempt at t. responseStatus is 0 when no
+// response was received. The delivery is retried after backoff, or marked
+// failed once it has been attempted maxAttempts times.
+func (d *WebhookDelivery) MarkFailed(responseStatus int, err error, t time.Time, maxAttempts int, backoff time.Duration) {
+	d.Attempts++
+	d.ResponseStatus = responseStatus
+	d.LastError = err.Error()
+	d.LastAttemptAt = &t
+	d.NextAttemptAt = t.Add(backoff)
+	if d.Attempts >= maxAttempts {
+		d.Status = WebhookDeliveryStatusFailed
+	}
+}
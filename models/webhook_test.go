@@ -0,0 +1,52 @@
+package models_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WebhookSubscription", func() {
+	Describe("Wants", func() {
+		It("wants every event without a filter", func() {
+			subscription := &models.WebhookSubscription{}
+			Expect(subscription.Wants(models.EventTypeOrderPlaced)).To(BeTrue())
+		})
+
+		It("wants only the filtered events otherwise", func() {
+			subscription := &models.WebhookSubscription{EventTypes: []models.EventType{models.EventTypeOrderRefunded}}
+			Expect(subscription.Wants(models.EventTypeOrderRefunded)).To(BeTrue())
+			Expect(subscription.Wants(models.EventTypeOrderPlaced)).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("WebhookDelivery", func() {
+	var now = time.Date(2019, 5, 6, 12, 0, 0, 0, time.UTC)
+
+	It("is marked failed once it runs out of attempts", func() {
+		delivery := &models.WebhookDelivery{Status: models.WebhookDeliveryStatusPending}
+
+		delivery.MarkFailed(500, errors.New("webhook responded with status 500"), now, 2, time.Second)
+		Expect(delivery.Status).To(Equal(models.WebhookDeliveryStatusPending))
+		Expect(delivery.NextAttemptAt).To(Equal(now.Add(time.Second)))
+
+		delivery.MarkFailed(0, errors.New("timeout"), now, 2, time.Second)
+		Expect(delivery.Status).To(Equal(models.WebhookDeliveryStatusFailed))
+		Expect(delivery.LastError).To(Equal("timeout"))
+	})
+
+	It("replays as a new pending delivery of the same body", func() {
+		delivery := &models.WebhookDelivery{ID: 11, SubscriptionID: 2, EventID: 7, Body: []byte(`{}`), Status: models.WebhookDeliveryStatusFailed, Attempts: 10}
+
+		replay := delivery.Replay(now)
+		Expect(replay.ID).To(BeZero())
+		Expect(*replay.ReplayOf).To(Equal(int64(11)))
+		Expect(replay.Status).To(Equal(models.WebhookDeliveryStatusPending))
+		Expect(replay.Attempts).To(BeZero())
+		Expect(replay.NextAttemptAt).To(Equal(now))
+	})
+})
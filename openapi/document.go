@@ -0,0 +1,163 @@
+// Package openapi builds OpenAPI 3 documents that describe HTTP APIs. Schemas
+// are generated from Go types by reading their JSON tags, so that they stay in
+// step with what the API encodes.
+package openapi
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Version is the version of the OpenAPI specification that documents follow.
+const Version = "3.0.3"
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+
+	// schemaNames holds the name of the component schema of each type that
+	// has one.
+	schemaNames map[reflect.Type]string
+}
+
+// Info describes the API.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds the operations on a path, by lowercase HTTP method.
+type PathItem map[string]*Operation
+
+// Operation is a single HTTP method on a path.
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path, query or header parameter of an operation, or a
+// reference to one in the components.
+type Parameter struct {
+	Ref         string  `json:"$ref,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	In          string  `json:"in,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody is the body of a request.
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+// Response is a response of an operation, or a reference to one in the
+// components.
+type Response struct {
+	Ref         string                `json:"$ref,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Header is a response header.
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType is the schema of a body in one content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas, parameters and responses that operations
+// refer to.
+type Components struct {
+	Schemas    map[string]*Schema    `json:"schemas"`
+	Parameters map[string]*Parameter `json:"parameters,omitempty"`
+	Responses  map[string]*Response  `json:"responses,omitempty"`
+}
+
+// NewDocument returns an empty document for version of the API titled title.
+func NewDocument(title, description, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Description: description, Version: version},
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas:    make(map[string]*Schema),
+			Parameters: make(map[string]*Parameter),
+			Responses:  make(map[string]*Response),
+		},
+		schemaNames: make(map[reflect.Type]string),
+	}
+}
+
+// AddOperation adds the operation on method and path. Paths are in the form
+// that Gin uses, with :name for path parameters, and are converted to the
+// OpenAPI form with {name}.
+func (d *Document) AddOperation(method, path string, op *Operation) {
+	path = Path(path)
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+
+	(*item)[strings.ToLower(method)] = op
+}
+
+// Operation returns the operation on method and path, in the form that Gin
+// uses, or nil when there is none.
+func (d *Document) Operation(method, path string) *Operation {
+	item, ok := d.Paths[Path(path)]
+	if !ok {
+		return nil
+	}
+
+	return (*item)[strings.ToLower(method)]
+}
+
+// AddParameter adds a parameter to the components and returns a reference to
+// it.
+func (d *Document) AddParameter(name string, parameter *Parameter) *Parameter {
+	d.Components.Parameters[name] = parameter
+	return &Parameter{Ref: "#/components/parameters/" + name}
+}
+
+// AddResponse adds a response to the components and returns a reference to
+// it.
+func (d *Document) AddResponse(name string, response *Response) *Response {
+	d.Components.Responses[name] = response
+	return &Response{Ref: "#/components/responses/" + name}
+}
+
+// Path converts a Gin path to the OpenAPI form, so that /orders/:id becomes
+// /orders/{id}.
+func Path(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// JSONContent returns the content of a JSON body with schema.
+func JSONContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
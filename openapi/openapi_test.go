@@ -0,0 +1,122 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/openapi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestOpenAPI(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "OpenAPI Suite")
+}
+
+type base struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type widget struct {
+	base
+	Name       string          `json:"name,omitempty"`
+	Colour     string          `json:"colour,omitempty"`
+	Secret     string          `json:"-"`
+	MadeAt     time.Time       `json:"made_at"`
+	SoldAt     *time.Time      `json:"sold_at"`
+	Parts      []*part         `json:"parts"`
+	Extra      json.RawMessage `json:"extra"`
+	Owner      *part           `json:"owner"`
+	Sizes      map[string]int  `json:"sizes"`
+	unexported string
+}
+
+type part struct {
+	Weight float64 `json:"weight"`
+}
+
+var _ = Describe("Path", func() {
+	It("converts Gin path parameters", func() {
+		Expect(openapi.Path("/webhooks/:id/deliveries/:delivery_id/replay")).To(Equal("/webhooks/{id}/deliveries/{delivery_id}/replay"))
+		Expect(openapi.Path("/files/*path")).To(Equal("/files/{path}"))
+		Expect(openapi.Path("/openapi.json")).To(Equal("/openapi.json"))
+	})
+})
+
+var _ = Describe("Document", func() {
+	var doc *openapi.Document
+
+	BeforeEach(func() {
+		doc = openapi.NewDocument("Widgets", "", "1.0.0")
+	})
+
+	It("finds operations by their Gin paths", func() {
+		op := &openapi.Operation{OperationID: "findWidget"}
+		doc.AddOperation("GET", "/widgets/:id", op)
+
+		Expect(doc.Paths).To(HaveKey("/widgets/{id}"))
+		Expect(doc.Operation("GET", "/widgets/:id")).To(BeIdenticalTo(op))
+		Expect(doc.Operation("DELETE", "/widgets/:id")).To(BeNil())
+		Expect(doc.Operation("GET", "/parts/:id")).To(BeNil())
+	})
+
+	Describe("SchemaFor", func() {
+		var schema *openapi.Schema
+
+		BeforeEach(func() {
+			Expect(doc.SchemaFor(widget{})).To(Equal(openapi.Ref("widget")))
+			schema = doc.Components.Schemas["widget"]
+		})
+
+		It("flattens embedded structs, letting outer fields win", func() {
+			Expect(schema.Properties).To(HaveKey("id"))
+			Expect(schema.Required).To(ContainElement("id"))
+			Expect(schema.Properties).To(HaveKey("name"))
+			Expect(schema.Required).NotTo(ContainElement("name"))
+		})
+		It("leaves out ignored and unexported fields", func() {
+			Expect(schema.Properties).NotTo(HaveKey("Secret"))
+			Expect(schema.Properties).NotTo(HaveKey("-"))
+			Expect(schema.Properties).NotTo(HaveKey("unexported"))
+		})
+		It("requires the fields without omitempty", func() {
+			Expect(schema.Required).To(ContainElement("made_at"))
+			Expect(schema.Required).NotTo(ContainElement("colour"))
+		})
+		It("describes times, raw JSON and maps", func() {
+			Expect(schema.Properties["made_at"]).To(Equal(&openapi.Schema{Type: "string", Format: "date-time"}))
+			Expect(schema.Properties["extra"]).To(Equal(&openapi.Schema{}))
+			Expect(schema.Properties["sizes"]).To(Equal(&openapi.Schema{
+				Type:                 "object",
+				AdditionalProperties: &openapi.Schema{Type: "integer", Format: "int64"},
+			}))
+		})
+		It("makes pointer fields nullable", func() {
+			Expect(schema.Properties["sold_at"].Nullable).To(BeTrue())
+			Expect(schema.Properties["owner"]).To(Equal(&openapi.Schema{
+				Nullable: true,
+				AllOf:    []*openapi.Schema{openapi.Ref("part")},
+			}))
+		})
+		It("refers to named structs in the components", func() {
+			Expect(schema.Properties["parts"]).To(Equal(&openapi.Schema{Type: "array", Items: openapi.Ref("part")}))
+			Expect(doc.Components.Schemas["part"].Properties["weight"]).To(Equal(&openapi.Schema{Type: "number"}))
+		})
+	})
+
+	It("uses the schemas given to DefineSchema", func() {
+		doc.DefineSchema("Colour", colour(""), &openapi.Schema{Type: "string", Enum: []string{"red", "blue"}})
+
+		doc.SchemaFor(paint{})
+		Expect(doc.Components.Schemas["paint"].Properties["colour"]).To(Equal(openapi.Ref("Colour")))
+	})
+})
+
+type colour string
+
+type paint struct {
+	Colour colour `json:"colour"`
+}
@@ -0,0 +1,186 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema describes a JSON value.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Ref returns a reference to the component schema called name.
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	marshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// DefineSchema adds a component schema called name for the type of v and
+// returns a reference to it. Values of the type are described by schema, or
+// by a schema generated from the type when schema is nil. Types that encode
+// themselves, or whose values are limited to an enum, need a schema given.
+func (d *Document) DefineSchema(name string, v interface{}, schema *Schema) *Schema {
+	t := reflect.TypeOf(v)
+	d.schemaNames[t] = name
+	if schema == nil {
+		schema = d.objectSchema(t)
+	}
+	d.Components.Schemas[name] = schema
+
+	return Ref(name)
+}
+
+// SchemaFor returns the schema of the JSON encoding of v. Named struct types
+// are added to the component schemas under their Go names, unless they were
+// given another name with DefineSchema, and referred to.
+func (d *Document) SchemaFor(v interface{}) *Schema {
+	return d.schemaFor(reflect.TypeOf(v))
+}
+
+func (d *Document) schemaFor(t reflect.Type) *Schema {
+	if name, ok := d.schemaNames[t]; ok {
+		return Ref(name)
+	}
+
+	switch {
+	case t.Kind() == reflect.Ptr:
+		return d.schemaFor(t.Elem())
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		// Raw JSON can hold any value.
+		return &Schema{}
+	case t.Implements(marshalerType):
+		// The encoding of the type is unknown without a defined schema.
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: d.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.objectSchema(t)
+		}
+		// Name the schema before generating it so that types that refer to
+		// themselves refer to the component.
+		d.schemaNames[t] = t.Name()
+		d.Components.Schemas[t.Name()] = d.objectSchema(t)
+		return Ref(t.Name())
+	}
+
+	return &Schema{}
+}
+
+// objectSchema describes a struct by the fields that encoding/json encodes.
+// Fields without omitempty are required, and pointer fields may be null.
+func (d *Document) objectSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	d.addFields(schema, t, make(map[string]bool))
+
+	return schema
+}
+
+// addFields adds the fields of struct type t to schema, followed by the
+// fields of the structs it embeds. declared holds the fields that were
+// already added, which take precedence the way they do in encoding/json.
+func (d *Document) addFields(schema *Schema, t reflect.Type, declared map[string]bool) {
+	embedded := []reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty := jsonField(field)
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if field.PkgPath != "" || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if declared[name] {
+			continue
+		}
+		declared[name] = true
+
+		fieldSchema := d.schemaFor(field.Type)
+		if field.Type.Kind() == reflect.Ptr {
+			fieldSchema = nullable(fieldSchema)
+		}
+		schema.Properties[name] = fieldSchema
+		if !omitEmpty {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	for _, et := range embedded {
+		d.addFields(schema, et, declared)
+	}
+}
+
+// jsonField returns the name given to a field by its json tag and whether
+// it is left out when empty.
+func jsonField(field reflect.StructField) (string, bool) {
+	parts := strings.Split(field.Tag.Get("json"), ",")
+	omitEmpty := false
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	return parts[0], omitEmpty
+}
+
+// nullable returns a schema that also allows null. References cannot be
+// changed, so they are wrapped.
+func nullable(schema *Schema) *Schema {
+	if schema.Ref != "" {
+		return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+	}
+
+	schema.Nullable = true
+	return schema
+}
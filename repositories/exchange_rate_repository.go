@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+)
+
+// ExchangeRateRepository is the interface that an exchange rate repository
+// should conform to.
+type ExchangeRateRepository interface {
+	FindRatesBetween(ctx context.Context, currencies []models.Currency, target models.Currency) (models.ExchangeRates, error)
+	UpsertRates(ctx context.Context, rates models.ExchangeRates) error
+}
+
+// NewExchangeRateRepository returns a new implementation of an exchange rate
+// repository.
+func NewExchangeRateRepository(db orm.DB) *exchangeRateRepository {
+	return &exchangeRateRepository{
+		db: db,
+	}
+}
+
+// exchangeRateRepository is an implementation of an ExchangeRateRepository.
+type exchangeRateRepository struct {
+	db orm.DB
+}
+
+func (r *exchangeRateRepository) SetDB(db orm.DB) {
+	r.db = db
+}
+
+func (r *exchangeRateRepository) getDB() orm.DB {
+	if r.db != nil {
+		return r.db
+	}
+
+	r.db = application.ResolveDB()
+	return r.db
+}
+
+// FindRatesBetween returns every rate between one of currencies and target,
+// in either direction, oldest first.
+func (r *exchangeRateRepository) FindRatesBetween(ctx context.Context, currencies []models.Currency, target models.Currency) (models.ExchangeRates, error) {
+	rates := models.ExchangeRates{}
+	if len(currencies) == 0 {
+		return rates, nil
+	}
+
+	err := conn(ctx, r.getDB()).ModelContext(ctx, &rates).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			return q.Where("base_currency IN (?)", pg.In(currencies)).Where("quote_currency = ?", target), nil
+		}).
+		WhereOrGroup(func(q *orm.Query) (*orm.Query, error) {
+			return q.Where("base_currency = ?", target).Where("quote_currency IN (?)", pg.In(currencies)), nil
+		}).
+		Order("effective_at ASC").
+		Select()
+	return rates, err
+}
+
+// UpsertRates stores rates, replacing any existing rate for the same pair and
+// effective time.
+func (r *exchangeRateRepository) UpsertRates(ctx context.Context, rates models.ExchangeRates) error {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	_, err := conn(ctx, r.getDB()).ModelContext(ctx, &rates).
+		OnConflict("(base_currency, quote_currency, effective_at) DO UPDATE").
+		Set("rate = EXCLUDED.rate").
+		Insert()
+	return err
+}
@@ -0,0 +1,64 @@
+package repositories_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/go-pg/pg"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExchangeRateRepository", func() {
+	var (
+		tx               *pg.Tx
+		exchangeRateRepo repositories.ExchangeRateRepository
+		rates            models.ExchangeRates
+		err              error
+
+		effectiveAt = time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		tx, err = application.ResolveDB().Begin()
+		Expect(err).To(BeNil())
+		exchangeRateRepo = repositories.NewExchangeRateRepository(tx)
+
+		err = exchangeRateRepo.UpsertRates(context.Background(), models.ExchangeRates{
+			{BaseCurrency: models.GBP, QuoteCurrency: models.EUR, Rate: "1.1", EffectiveAt: effectiveAt},
+			{BaseCurrency: models.EUR, QuoteCurrency: models.USD, Rate: "1.12", EffectiveAt: effectiveAt},
+			{BaseCurrency: models.GBP, QuoteCurrency: models.JPY, Rate: "145", EffectiveAt: effectiveAt},
+		})
+		Expect(err).To(BeNil())
+	})
+
+	Describe("FindRatesBetween", func() {
+		It("finds rates to the target in either direction", func() {
+			rates, err = exchangeRateRepo.FindRatesBetween(context.Background(), []models.Currency{models.GBP, models.USD}, models.EUR)
+			Expect(err).To(BeNil())
+			Expect(len(rates)).To(Equal(2))
+		})
+	})
+
+	Describe("UpsertRates", func() {
+		It("replaces the rate for the same pair and effective time", func() {
+			err = exchangeRateRepo.UpsertRates(context.Background(), models.ExchangeRates{
+				{BaseCurrency: models.GBP, QuoteCurrency: models.EUR, Rate: "1.2", EffectiveAt: effectiveAt},
+			})
+			Expect(err).To(BeNil())
+
+			rates, err = exchangeRateRepo.FindRatesBetween(context.Background(), []models.Currency{models.GBP}, models.EUR)
+			Expect(err).To(BeNil())
+			Expect(len(rates)).To(Equal(1))
+			Expect(rates[0].Rate).To(Equal("1.2"))
+		})
+	})
+
+	AfterEach(func() {
+		err = tx.Rollback()
+		Expect(err).To(BeNil())
+	})
+})
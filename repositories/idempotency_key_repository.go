@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/go-pg/pg/orm"
+)
+
+// IdempotencyKeyRepository is the interface that an idempotency key
+// repository should conform to.
+type IdempotencyKeyRepository interface {
+	ClaimKey(ctx context.Context, key *models.IdempotencyKey, now time.Time) (bool, error)
+	FindKey(ctx context.Context, scope, key string) (*models.IdempotencyKey, error)
+	CompleteKey(ctx context.Context, key *models.IdempotencyKey) error
+	ReleaseKey(ctx context.Context, key *models.IdempotencyKey) error
+	DeleteExpiredKeys(ctx context.Context, now time.Time) (int, error)
+}
+
+// NewIdempotencyKeyRepository returns a new implementation of an idempotency
+// key repository.
+func NewIdempotencyKeyRepository(db orm.DB) *idempotencyKeyRepository {
+	return &idempotencyKeyRepository{
+		db: db,
+	}
+}
+
+// idempotencyKeyRepository is an implementation of an
+// IdempotencyKeyRepository.
+type idempotencyKeyRepository struct {
+	db orm.DB
+}
+
+func (r *idempotencyKeyRepository) SetDB(db orm.DB) {
+	r.db = db
+}
+
+func (r *idempotencyKeyRepository) getDB() orm.DB {
+	if r.db != nil {
+		return r.db
+	}
+
+	r.db = application.ResolveDB()
+	return r.db
+}
+
+// ClaimKey stores key and reports whether it was claimed. A key that already
+// exists is only taken over once it has expired, or when the request that
+// claimed it never completed and its lock has run out. Postgres serialises
+// concurrent claims of the same key, so only one of them succeeds.
+func (r *idempotencyKeyRepository) ClaimKey(ctx context.Context, key *models.IdempotencyKey, now time.Time) (bool, error) {
+	res, err := conn(ctx, r.getDB()).ModelContext(ctx, key).
+		OnConflict("(scope, key) DO UPDATE").
+		Set("fingerprint = EXCLUDED.fingerprint").
+		Set("locked_until = EXCLUDED.locked_until").
+		Set("response_status = NULL, response_content_type = NULL, response_body = NULL").
+		Set("created_at = EXCLUDED.created_at").
+		Set("completed_at = NULL").
+		Set("expires_at = EXCLUDED.expires_at").
+		Where("?TableAlias.expires_at <= ?", now).
+		WhereOr("?TableAlias.completed_at IS NULL AND ?TableAlias.locked_until <= ?", now).
+		Insert()
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() > 0, nil
+}
+
+// FindKey returns ErrNotFound when the key has not been claimed.
+func (r *idempotencyKeyRepository) FindKey(ctx context.Context, scope, key string) (*models.IdempotencyKey, error) {
+	found := &models.IdempotencyKey{}
+	err := conn(ctx, r.getDB()).ModelContext(ctx, found).
+		Where("scope = ?", scope).
+		Where("key = ?", key).
+		Select()
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return found, nil
+}
+
+// CompleteKey stores the response of the request that claimed key.
+func (r *idempotencyKeyRepository) CompleteKey(ctx context.Context, key *models.IdempotencyKey) error {
+	_, err := conn(ctx, r.getDB()).ModelContext(ctx, key).
+		Column("response_status", "response_content_type", "response_body", "completed_at").
+		WherePK().
+		Update()
+	return err
+}
+
+// ReleaseKey deletes a key that was claimed by a request that failed, so that
+// the request can be retried with the same key.
+func (r *idempotencyKeyRepository) ReleaseKey(ctx context.Context, key *models.IdempotencyKey) error {
+	_, err := conn(ctx, r.getDB()).ModelContext(ctx, key).
+		WherePK().
+		Where("completed_at IS NULL").
+		Delete()
+	return err
+}
+
+// DeleteExpiredKeys deletes every key that expired before now and returns how
+// many were deleted.
+func (r *idempotencyKeyRepository) DeleteExpiredKeys(ctx context.Context, now time.Time) (int, error) {
+	res, err := conn(ctx, r.getDB()).ModelContext(ctx, (*models.IdempotencyKey)(nil)).
+		Where("expires_at <= ?", now).
+		Delete()
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected(), nil
+}
@@ -0,0 +1,103 @@
+package repositories_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/go-pg/pg"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IdempotencyKeyRepository", func() {
+	var (
+		tx                 *pg.Tx
+		idempotencyKeyRepo repositories.IdempotencyKeyRepository
+		claimed            bool
+		err                error
+
+		now = time.Now()
+	)
+
+	newClaim := func(fingerprint string, at time.Time) *models.IdempotencyKey {
+		return &models.IdempotencyKey{
+			Scope:       "user:5",
+			Key:         "abc",
+			Fingerprint: fingerprint,
+			LockedUntil: at.Add(time.Minute),
+			CreatedAt:   at,
+			ExpiresAt:   at.Add(time.Hour),
+		}
+	}
+
+	BeforeEach(func() {
+		tx, err = application.ResolveDB().Begin()
+		Expect(err).To(BeNil())
+		idempotencyKeyRepo = repositories.NewIdempotencyKeyRepository(tx)
+
+		claimed, err = idempotencyKeyRepo.ClaimKey(context.Background(), newClaim("f1", now), now)
+		Expect(err).To(BeNil())
+		Expect(claimed).To(BeTrue())
+	})
+
+	Describe("ClaimKey", func() {
+		It("does not claim a key that is locked", func() {
+			claimed, err = idempotencyKeyRepo.ClaimKey(context.Background(), newClaim("f1", now), now)
+			Expect(err).To(BeNil())
+			Expect(claimed).To(BeFalse())
+		})
+
+		It("takes over a key whose lock has run out", func() {
+			later := now.Add(2 * time.Minute)
+			claimed, err = idempotencyKeyRepo.ClaimKey(context.Background(), newClaim("f2", later), later)
+			Expect(err).To(BeNil())
+			Expect(claimed).To(BeTrue())
+
+			found, err := idempotencyKeyRepo.FindKey(context.Background(), "user:5", "abc")
+			Expect(err).To(BeNil())
+			Expect(found.Fingerprint).To(Equal("f2"))
+		})
+	})
+
+	Describe("CompleteKey", func() {
+		It("stores the response and keeps the key claimed until it expires", func() {
+			key, err := idempotencyKeyRepo.FindKey(context.Background(), "user:5", "abc")
+			Expect(err).To(BeNil())
+
+			key.ResponseStatus = 201
+			key.ResponseContentType = "application/json"
+			key.ResponseBody = []byte(`{"id":1}`)
+			key.CompletedAt = &now
+			Expect(idempotencyKeyRepo.CompleteKey(context.Background(), key)).To(Succeed())
+
+			later := now.Add(2 * time.Minute)
+			claimed, err = idempotencyKeyRepo.ClaimKey(context.Background(), newClaim("f1", later), later)
+			Expect(err).To(BeNil())
+			Expect(claimed).To(BeFalse())
+
+			found, err := idempotencyKeyRepo.FindKey(context.Background(), "user:5", "abc")
+			Expect(err).To(BeNil())
+			Expect(found.IsCompleted()).To(BeTrue())
+			Expect(found.ResponseBody).To(Equal([]byte(`{"id":1}`)))
+		})
+	})
+
+	Describe("DeleteExpiredKeys", func() {
+		It("deletes keys that have expired", func() {
+			deleted, err := idempotencyKeyRepo.DeleteExpiredKeys(context.Background(), now.Add(2*time.Hour))
+			Expect(err).To(BeNil())
+			Expect(deleted).To(Equal(1))
+
+			_, err = idempotencyKeyRepo.FindKey(context.Background(), "user:5", "abc")
+			Expect(err).To(Equal(repositories.ErrNotFound))
+		})
+	})
+
+	AfterEach(func() {
+		err = tx.Rollback()
+		Expect(err).To(BeNil())
+	})
+})
@@ -0,0 +1,353 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/pkg/errors"
+)
+
+// NewInMemoryOrderRepository returns an OrderRepository that keeps orders in
+// memory. It is only meant for tests: the rest of the data, such as items,
+// refunds and the outbox, is still stored in the database.
+func NewInMemoryOrderRepository() *InMemoryOrderRepository {
+	return &InMemoryOrderRepository{
+		orders: make(map[int]*models.Order),
+	}
+}
+
+// InMemoryOrderRepository is an OrderRepository that keeps orders in memory.
+// It is safe for concurrent use and lists orders in the same order as the
+// Postgres implementation, breaking ties in placed_at by descending ID.
+//
+// It does not take part in transactions: changes are visible as soon as they
+// are made and are not rolled back, and FindOrderByIDForUpdate does not lock
+// the order. UpdateOrder still detects concurrent changes by version.
+type InMemoryOrderRepository struct {
+	mu               sync.RWMutex
+	orders           map[int]*models.Order
+	lastID           int
+	refundRepository RefundRepository
+}
+
+// SetRefundRepository sets the repository that the refunds subtracted from
+// spend are read from. Without one, orders are treated as never refunded.
+func (r *InMemoryOrderRepository) SetRefundRepository(refunds RefundRepository) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refundRepository = refunds
+}
+
+// AddOrder stores a copy of order, the way inserting it into the database
+// would. An order without an ID is given the next one, and the defaults of
+// the orders table are applied.
+func (r *InMemoryOrderRepository) AddOrder(order *models.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if order.ID == 0 {
+		order.ID = r.lastID + 1
+	}
+	if _, ok := r.orders[order.ID]; ok {
+		return errors.Errorf("order %d already exists", order.ID)
+	}
+	if order.ID > r.lastID {
+		r.lastID = order.ID
+	}
+	if order.Status == "" {
+		order.Status = models.OrderStatusPlaced
+	}
+	if order.Version == 0 {
+		order.Version = 1
+	}
+
+	r.orders[order.ID] = storedOrder(order)
+	return nil
+}
+
+// Reset forgets every order.
+func (r *InMemoryOrderRepository) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.orders = make(map[int]*models.Order)
+	r.lastID = 0
+}
+
+func (r *InMemoryOrderRepository) FindAllOrdersByUserID(ctx context.Context, userID int) (models.Orders, error) {
+	return r.find(func(o *models.Order) bool {
+		return o.UserID == userID && o.DeletedAt.IsZero()
+	}), nil
+}
+
+// FindAllOrdersByUserIDWithDeleted is like FindAllOrdersByUserID but includes
+// the user's deleted orders.
+func (r *InMemoryOrderRepository) FindAllOrdersByUserIDWithDeleted(ctx context.Context, userID int) (models.Orders, error) {
+	return r.find(func(o *models.Order) bool {
+		return o.UserID == userID
+	}), nil
+}
+
+// FindOrdersByRestaurant returns up to filter.Limit orders of a restaurant,
+// newest first.
+func (r *InMemoryOrderRepository) FindOrdersByRestaurant(ctx context.Context, filter models.RestaurantOrderFilter) (models.Orders, error) {
+	statuses := make(map[models.OrderStatus]bool, len(filter.Statuses))
+	for _, status := range filter.Statuses {
+		statuses[status] = true
+	}
+
+	orders := r.find(func(o *models.Order) bool {
+		switch {
+		case o.RestaurantID != filter.RestaurantID || !o.DeletedAt.IsZero():
+			return false
+		case filter.From != nil && o.PlacedAt.Before(*filter.From):
+			return false
+		case filter.To != nil && !o.PlacedAt.Before(*filter.To):
+			return false
+		case len(statuses) > 0 && !statuses[o.Status]:
+			return false
+		case filter.After != nil && !placedBefore(o, filter.After.PlacedAt, filter.After.ID):
+			return false
+		}
+		return true
+	})
+
+	if filter.Limit > 0 && len(orders) > filter.Limit {
+		orders = orders[:filter.Limit]
+	}
+	return orders, nil
+}
+
+// FindOrderByID returns ErrNotFound when there is no order with the ID.
+func (r *InMemoryOrderRepository) FindOrderByID(ctx context.Context, id int) (*models.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	order, ok := r.orders[id]
+	if !ok || !order.DeletedAt.IsZero() {
+		return nil, ErrNotFound
+	}
+
+	return storedOrder(order), nil
+}
+
+// FindOrderByIDForUpdate is the same as FindOrderByID, as orders in memory
+// cannot be locked.
+func (r *InMemoryOrderRepository) FindOrderByIDForUpdate(ctx context.Context, id int) (*models.Order, error) {
+	return r.FindOrderByID(ctx, id)
+}
+
+// UpdateOrder saves order and increments its version. It returns
+// ErrVersionConflict when the stored order's version is no longer the one it
+// was read with, or the order has been deleted.
+func (r *InMemoryOrderRepository) UpdateOrder(ctx context.Context, order *models.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.orders[order.ID]
+	if !ok || !stored.DeletedAt.IsZero() || stored.Version != order.Version {
+		return ErrVersionConflict
+	}
+
+	order.Version++
+	r.orders[order.ID] = storedOrder(order)
+	return nil
+}
+
+// SpendByCurrencyForUser returns the number of orders and the net amount spent
+// in each currency, most orders first.
+func (r *InMemoryOrderRepository) SpendByCurrencyForUser(ctx context.Context, userID int) ([]*models.CurrencySpend, error) {
+	orders, err := r.spentOrders(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	spend := []*models.CurrencySpend{}
+	spendByCurrency := make(map[models.Currency]*models.CurrencySpend)
+	for _, order := range orders {
+		cs, ok := spendByCurrency[order.Total.Currency]
+		if !ok {
+			cs = &models.CurrencySpend{Currency: order.Total.Currency, Total: models.ZeroMoney(order.Total.Currency)}
+			spendByCurrency[cs.Currency] = cs
+			spend = append(spend, cs)
+		}
+
+		cs.OrderCount++
+		cs.Total.MinorUnits += order.NetTotal.MinorUnits
+	}
+
+	sort.Slice(spend, func(i, j int) bool {
+		if spend[i].OrderCount != spend[j].OrderCount {
+			return spend[i].OrderCount > spend[j].OrderCount
+		}
+		return spend[i].Currency < spend[j].Currency
+	})
+	return spend, nil
+}
+
+// RestaurantStatsForUser returns the number of orders and the net amount spent
+// in each currency at every restaurant that the user ordered from, ordered by
+// restaurant ID.
+func (r *InMemoryOrderRepository) RestaurantStatsForUser(ctx context.Context, userID int) ([]*models.RestaurantStats, error) {
+	orders, err := r.spentOrders(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(orders, func(i, j int) bool {
+		a, b := orders[i], orders[j]
+		if a.RestaurantID != b.RestaurantID {
+			return a.RestaurantID < b.RestaurantID
+		}
+		return a.Total.Currency < b.Total.Currency
+	})
+
+	stats := []*models.RestaurantStats{}
+	var last *models.RestaurantStats
+	for _, order := range orders {
+		if last == nil || last.RestaurantID != order.RestaurantID {
+			last = &models.RestaurantStats{RestaurantID: order.RestaurantID, Spend: []models.Money{}}
+			stats = append(stats, last)
+		}
+
+		last.OrderCount++
+		if n := len(last.Spend); n > 0 && last.Spend[n-1].Currency == order.Total.Currency {
+			last.Spend[n-1].MinorUnits += order.NetTotal.MinorUnits
+		} else {
+			last.Spend = append(last.Spend, *order.NetTotal)
+		}
+	}
+
+	return stats, nil
+}
+
+// OrderCountsForUser returns the number of orders placed in each week or month
+// that the user placed any orders in, oldest first. Weeks start on Monday and
+// periods are in UTC.
+func (r *InMemoryOrderRepository) OrderCountsForUser(ctx context.Context, userID int, period models.StatsPeriod) ([]*models.PeriodCount, error) {
+	orders, err := r.spentOrders(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := []*models.PeriodCount{}
+	countsByStart := make(map[time.Time]*models.PeriodCount)
+	for _, order := range orders {
+		start := periodStart(order.PlacedAt, period)
+		pc, ok := countsByStart[start]
+		if !ok {
+			pc = &models.PeriodCount{Start: start}
+			countsByStart[start] = pc
+			counts = append(counts, pc)
+		}
+
+		pc.OrderCount++
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		return counts[i].Start.Before(counts[j].Start)
+	})
+	return counts, nil
+}
+
+// find returns copies of the orders that match, newest first.
+func (r *InMemoryOrderRepository) find(match func(*models.Order) bool) models.Orders {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	orders := models.Orders{}
+	for _, order := range r.orders {
+		if match(order) {
+			orders = append(orders, storedOrder(order))
+		}
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return placedBefore(orders[j], orders[i].PlacedAt, orders[i].ID)
+	})
+	return orders
+}
+
+// spentOrders returns the user's orders that were not cancelled or deleted,
+// with their net totals set.
+func (r *InMemoryOrderRepository) spentOrders(ctx context.Context, userID int) (models.Orders, error) {
+	orders := r.find(func(o *models.Order) bool {
+		return o.UserID == userID && o.Status != models.OrderStatusCancelled && o.DeletedAt.IsZero()
+	})
+
+	r.mu.RLock()
+	refunds := r.refundRepository
+	r.mu.RUnlock()
+
+	refunded := make(map[int]int64)
+	if refunds != nil && len(orders) > 0 {
+		found, err := refunds.FindRefundsByOrderIDs(ctx, orders.IDs())
+		if err != nil {
+			return nil, err
+		}
+		for _, refund := range found {
+			refunded[refund.OrderID] += refund.Amount.MinorUnits
+		}
+	}
+
+	for _, order := range orders {
+		net := models.NewMoney(order.Total.MinorUnits-refunded[order.ID], order.Total.Currency)
+		order.NetTotal = &net
+	}
+
+	return orders, nil
+}
+
+// placedBefore reports whether order comes after the position (placedAt, id)
+// in a newest first listing.
+func placedBefore(order *models.Order, placedAt time.Time, id int) bool {
+	if !order.PlacedAt.Equal(placedAt) {
+		return order.PlacedAt.Before(placedAt)
+	}
+	return order.ID < id
+}
+
+// periodStart returns the start of the week or month that t falls in, in UTC.
+func periodStart(t time.Time, period models.StatsPeriod) time.Time {
+	t = t.UTC()
+	year, month, day := t.Date()
+	if period == models.StatsPeriodMonth {
+		return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(year, month, day-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+}
+
+// storedOrder returns a copy of order with only the fields that are stored in
+// the orders table, so that callers never share an order with the repository.
+func storedOrder(order *models.Order) *models.Order {
+	stored := &models.Order{
+		ID:                 order.ID,
+		UserID:             order.UserID,
+		RestaurantID:       order.RestaurantID,
+		Total:              order.Total,
+		TotalMinorUnits:    order.Total.MinorUnits,
+		CurrencyCode:       order.Total.Currency,
+		Status:             order.Status,
+		PlacedAt:           order.PlacedAt,
+		CancelledBy:        order.CancelledBy,
+		CancellationReason: order.CancellationReason,
+		Version:            order.Version,
+		DeletedAt:          order.DeletedAt,
+	}
+	if order.CancelledAt != nil {
+		cancelledAt := *order.CancelledAt
+		stored.CancelledAt = &cancelledAt
+	}
+	if order.AnonymisedAt != nil {
+		anonymisedAt := *order.AnonymisedAt
+		stored.AnonymisedAt = &anonymisedAt
+	}
+
+	return stored
+}
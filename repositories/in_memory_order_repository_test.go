@@ -0,0 +1,137 @@
+package repositories_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories/repositorytest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InMemoryOrderRepository", func() {
+	var (
+		orderRepo *repositories.InMemoryOrderRepository
+		orders    models.Orders
+
+		now = time.Date(2019, 5, 15, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		orderRepo = repositories.NewInMemoryOrderRepository()
+		orders = models.Orders{
+			{UserID: 5, RestaurantID: 8, Total: models.NewMoney(1000, models.GBP), PlacedAt: now.Add(-48 * time.Hour)},
+			{UserID: 5, RestaurantID: 9, Total: models.NewMoney(2000, models.GBP), PlacedAt: now},
+			{UserID: 6, RestaurantID: 8, Total: models.NewMoney(3000, models.EUR), PlacedAt: now, Status: models.OrderStatusDelivered, Version: 4},
+		}
+		for _, order := range orders {
+			Expect(orderRepo.AddOrder(order)).To(Succeed())
+		}
+	})
+
+	Describe("AddOrder", func() {
+		It("assigns IDs and applies the defaults of the orders table", func() {
+			Expect(orders.IDs()).To(Equal([]int{1, 2, 3}))
+			Expect(orders[0].Status).To(Equal(models.OrderStatusPlaced))
+			Expect(orders[0].Version).To(Equal(1))
+			Expect(orders[2].Status).To(Equal(models.OrderStatusDelivered))
+			Expect(orders[2].Version).To(Equal(4))
+		})
+
+		It("refuses an ID that is taken", func() {
+			Expect(orderRepo.AddOrder(&models.Order{ID: 2})).NotTo(Succeed())
+		})
+	})
+
+	Describe("FindOrderByID", func() {
+		It("returns a copy of the order", func() {
+			found, err := orderRepo.FindOrderByID(context.Background(), 2)
+			Expect(err).To(BeNil())
+			found.Status = models.OrderStatusDelivered
+
+			again, err := orderRepo.FindOrderByID(context.Background(), 2)
+			Expect(err).To(BeNil())
+			Expect(again.Status).To(Equal(models.OrderStatusPlaced))
+		})
+	})
+
+	Describe("UpdateOrder", func() {
+		It("lets only one of many concurrent updates of a version through", func() {
+			var (
+				wg        sync.WaitGroup
+				mu        sync.Mutex
+				conflicts int
+			)
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+
+					order := &models.Order{ID: 2, UserID: 5, RestaurantID: 9, Total: models.NewMoney(2000, models.GBP), PlacedAt: now, Version: 1}
+					if orderRepo.UpdateOrder(context.Background(), order) == repositories.ErrVersionConflict {
+						mu.Lock()
+						conflicts++
+						mu.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+
+			Expect(conflicts).To(Equal(9))
+		})
+	})
+})
+
+// refundList is a RefundRepository that keeps refunds in a slice, for the
+// in-memory contract specs.
+type refundList struct {
+	refunds models.Refunds
+}
+
+func (l *refundList) FindRefundsByOrderIDs(ctx context.Context, orderIDs []int) (models.Refunds, error) {
+	found := models.Refunds{}
+	for _, refund := range l.refunds {
+		for _, id := range orderIDs {
+			if refund.OrderID == id {
+				found = append(found, refund)
+			}
+		}
+	}
+	return found, nil
+}
+
+func (l *refundList) CreateRefund(ctx context.Context, refund *models.Refund) error {
+	refund.ID = len(l.refunds) + 1
+	l.refunds = append(l.refunds, refund)
+	return nil
+}
+
+// inMemoryOrderRepositoryHarness gives each contract spec a new in-memory
+// repository.
+type inMemoryOrderRepositoryHarness struct {
+	orderRepo *repositories.InMemoryOrderRepository
+	refunds   *refundList
+}
+
+func (h *inMemoryOrderRepositoryHarness) Setup() repositories.OrderRepository {
+	h.refunds = &refundList{}
+	h.orderRepo = repositories.NewInMemoryOrderRepository()
+	h.orderRepo.SetRefundRepository(h.refunds)
+	return h.orderRepo
+}
+
+func (h *inMemoryOrderRepositoryHarness) AddOrder(order *models.Order) error {
+	return h.orderRepo.AddOrder(order)
+}
+
+func (h *inMemoryOrderRepositoryHarness) AddRefund(refund *models.Refund) error {
+	return h.refunds.CreateRefund(context.Background(), refund)
+}
+
+func (h *inMemoryOrderRepositoryHarness) Teardown() {}
+
+var _ = repositorytest.DescribeOrderRepository("In-memory", &inMemoryOrderRepositoryHarness{})
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/go-pg/pg/orm"
+)
+
+// OrderAuditRepository is the interface that a repository of the audit log of
+// changes to orders should conform to.
+type OrderAuditRepository interface {
+	AddEntry(ctx context.Context, entry *models.OrderAuditEntry) error
+	FindEntriesByOrderID(ctx context.Context, orderID int) (models.OrderAuditEntries, error)
+}
+
+// NewOrderAuditRepository returns a new implementation of an order audit
+// repository.
+func NewOrderAuditRepository(db orm.DB) *orderAuditRepository {
+	return &orderAuditRepository{
+		db: db,
+	}
+}
+
+// orderAuditRepository is an implementation of an OrderAuditRepository.
+type orderAuditRepository struct {
+	db orm.DB
+}
+
+func (r *orderAuditRepository) SetDB(db orm.DB) {
+	r.db = db
+}
+
+func (r *orderAuditRepository) getDB() orm.DB {
+	if r.db != nil {
+		return r.db
+	}
+
+	r.db = application.ResolveDB()
+	return r.db
+}
+
+// AddEntry appends an entry to the audit log. It should be called in the
+// transaction that makes the change, so that the change cannot be committed
+// without its entry.
+func (r *orderAuditRepository) AddEntry(ctx context.Context, entry *models.OrderAuditEntry) error {
+	_, err := conn(ctx, r.getDB()).ModelContext(ctx, entry).Insert()
+	return err
+}
+
+// FindEntriesByOrderID returns the audit log of an order, oldest first.
+func (r *orderAuditRepository) FindEntriesByOrderID(ctx context.Context, orderID int) (models.OrderAuditEntries, error) {
+	entries := models.OrderAuditEntries{}
+	err := conn(ctx, r.getDB()).ModelContext(ctx, &entries).
+		Where("order_id = ?", orderID).
+		Order("id").
+		Select()
+	return entries, err
+}
@@ -0,0 +1,62 @@
+package repositories_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/go-pg/pg"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OrderAuditRepository", func() {
+	var (
+		tx        *pg.Tx
+		auditRepo repositories.OrderAuditRepository
+		order     *models.Order
+		err       error
+
+		now = time.Date(2019, 5, 14, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		tx, err = application.ResolveDB().Begin()
+		Expect(err).To(BeNil())
+		auditRepo = repositories.NewOrderAuditRepository(tx)
+
+		order = &models.Order{Total: models.NewMoney(1000, models.GBP), UserID: 5, RestaurantID: 8, PlacedAt: now, Version: 1}
+		Expect(tx.Insert(order)).To(Succeed())
+
+		for i, action := range []models.OrderAuditAction{models.OrderAuditActionCancel, models.OrderAuditActionDelete} {
+			after := *order
+			after.Version = order.Version + 1
+			entry, err := models.NewOrderAuditEntry(order, &after, action, "agent:1", "req-1", now.Add(time.Duration(i)*time.Minute))
+			Expect(err).To(BeNil())
+			Expect(auditRepo.AddEntry(context.Background(), entry)).To(Succeed())
+			order = &after
+		}
+	})
+
+	It("finds the audit log of an order, oldest first", func() {
+		entries, err := auditRepo.FindEntriesByOrderID(context.Background(), order.ID)
+		Expect(err).To(BeNil())
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].Action).To(Equal(models.OrderAuditActionCancel))
+		Expect(entries[0].Before).To(MatchJSON(`{"version": 1}`))
+		Expect(entries[0].After).To(MatchJSON(`{"version": 2}`))
+		Expect(entries[1].Action).To(Equal(models.OrderAuditActionDelete))
+		Expect(entries[1].RequestID).To(Equal("req-1"))
+	})
+
+	It("refuses to change entries", func() {
+		_, err := tx.Exec(`UPDATE order_audit SET actor_id = 'agent:2' WHERE order_id = ?`, order.ID)
+		Expect(err).NotTo(BeNil())
+	})
+
+	AfterEach(func() {
+		Expect(tx.Rollback()).To(Succeed())
+	})
+})
@@ -0,0 +1,245 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+)
+
+// OrderEventsChannel is the Postgres notification channel that order events
+// are announced on.
+const OrderEventsChannel = "order_events"
+
+// Listener defaults.
+const (
+	defaultReceiveTimeout = time.Second
+	defaultReconnectDelay = time.Second
+	// catchUpLimit bounds the events that are read after reconnecting.
+	catchUpLimit = 1000
+)
+
+// orderEventNotification is the payload of a notification on
+// OrderEventsChannel. It is kept small, since notifications are limited to
+// 8000 bytes, and listeners read the event itself from the outbox.
+type orderEventNotification struct {
+	EventID int64 `json:"event_id"`
+	UserID  int   `json:"user_id"`
+}
+
+// OrderEventNotifier is the interface that an order event notifier should
+// conform to.
+type OrderEventNotifier interface {
+	NotifyOrderEvents(ctx context.Context, events models.OutboxEvents) error
+}
+
+// NewOrderEventNotifier returns a notifier that announces order events on
+// OrderEventsChannel.
+func NewOrderEventNotifier(db orm.DB) *orderEventNotifier {
+	return &orderEventNotifier{
+		db: db,
+	}
+}
+
+// orderEventNotifier is an implementation of an OrderEventNotifier.
+type orderEventNotifier struct {
+	db orm.DB
+}
+
+func (n *orderEventNotifier) getDB() orm.DB {
+	if n.db != nil {
+		return n.db
+	}
+
+	n.db = application.ResolveDB()
+	return n.db
+}
+
+// NotifyOrderEvents announces events, which must already have been added to
+// the outbox. When it is called with the context of a transaction, Postgres
+// only sends the notifications once the transaction commits.
+func (n *orderEventNotifier) NotifyOrderEvents(ctx context.Context, events models.OutboxEvents) error {
+	for _, event := range events {
+		payload, err := json.Marshal(orderEventNotification{EventID: event.ID, UserID: event.UserID})
+		if err != nil {
+			return err
+		}
+
+		_, err = conn(ctx, n.getDB()).ExecContext(ctx, "SELECT pg_notify(?, ?)", OrderEventsChannel, string(payload))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OrderEventHandler is called with each order event that a listener is
+// notified of.
+type OrderEventHandler func(ctx context.Context, event *models.OutboxEvent) error
+
+// NewOrderEventListener returns a listener that passes the order events
+// announced on OrderEventsChannel, by any instance of the service, to handle.
+func NewOrderEventListener(db *pg.DB, handle OrderEventHandler) *orderEventListener {
+	return &orderEventListener{
+		db:     db,
+		handle: handle,
+	}
+}
+
+// orderEventListener holds a connection that listens on OrderEventsChannel.
+// When the connection is lost it reconnects, and passes on the events that
+// were added while it was away.
+type orderEventListener struct {
+	db               *pg.DB
+	handle           OrderEventHandler
+	outboxRepository OutboxRepository
+	receiveTimeout   time.Duration
+	reconnectDelay   time.Duration
+	lastID           int64
+}
+
+func (l *orderEventListener) SetOutboxRepository(r OutboxRepository) {
+	l.outboxRepository = r
+}
+
+func (l *orderEventListener) getOutboxRepository() OutboxRepository {
+	if l.outboxRepository != nil {
+		return l.outboxRepository
+	}
+
+	l.outboxRepository = NewOutboxRepository(l.db)
+	return l.outboxRepository
+}
+
+// SetReceiveTimeout overrides how long the listener waits for a notification
+// before checking whether it should stop.
+func (l *orderEventListener) SetReceiveTimeout(d time.Duration) {
+	l.receiveTimeout = d
+}
+
+func (l *orderEventListener) getReceiveTimeout() time.Duration {
+	if l.receiveTimeout > 0 {
+		return l.receiveTimeout
+	}
+
+	return defaultReceiveTimeout
+}
+
+// SetReconnectDelay overrides how long the listener waits before
+// reconnecting.
+func (l *orderEventListener) SetReconnectDelay(d time.Duration) {
+	l.reconnectDelay = d
+}
+
+func (l *orderEventListener) getReconnectDelay() time.Duration {
+	if l.reconnectDelay > 0 {
+		return l.reconnectDelay
+	}
+
+	return defaultReconnectDelay
+}
+
+// Run listens until ctx is done, reconnecting whenever the connection fails.
+func (l *orderEventListener) Run(ctx context.Context) {
+	for {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("listening for order events: %v", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(l.getReconnectDelay()):
+		}
+	}
+}
+
+// listen opens a connection, catches up on the events added since the last
+// one that was handled, and handles notifications until ctx is done or the
+// connection fails.
+func (l *orderEventListener) listen(ctx context.Context) error {
+	ln := l.db.Listen()
+	defer ln.Close()
+
+	if err := ln.Listen(OrderEventsChannel); err != nil {
+		return err
+	}
+
+	if err := l.catchUp(ctx); err != nil {
+		return err
+	}
+
+	for ctx.Err() == nil {
+		_, payload, err := ln.ReceiveTimeout(l.getReceiveTimeout())
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = l.notified(ctx, payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// catchUp handles the events that were added after the last one that was
+// handled. Nothing is caught up on before the first event is handled.
+func (l *orderEventListener) catchUp(ctx context.Context) error {
+	if l.lastID == 0 {
+		return nil
+	}
+
+	events, err := l.getOutboxRepository().FindEventsAfter(ctx, l.lastID, catchUpLimit)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		l.handled(ctx, event)
+	}
+
+	return nil
+}
+
+// notified handles the event announced by payload.
+func (l *orderEventListener) notified(ctx context.Context, payload string) error {
+	notification := orderEventNotification{}
+	if err := json.Unmarshal([]byte(payload), &notification); err != nil {
+		log.Printf("ignoring malformed order event notification %q: %v", payload, err)
+		return nil
+	}
+
+	event, err := l.getOutboxRepository().FindEventByID(ctx, notification.EventID)
+	if err != nil {
+		return err
+	}
+
+	l.handled(ctx, event)
+	return nil
+}
+
+// handled passes event to the handler. Failures are logged rather than
+// retried, since notifications are only a fast path: clients can always
+// resume from the outbox.
+func (l *orderEventListener) handled(ctx context.Context, event *models.OutboxEvent) {
+	if err := l.handle(ctx, event); err != nil {
+		log.Printf("handling order event %d: %v", event.ID, err)
+	}
+
+	if event.ID > l.lastID {
+		l.lastID = event.ID
+	}
+}
@@ -0,0 +1,104 @@
+package repositories_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// These specs commit their records, since notifications are only sent on
+// commit, and delete them afterwards.
+var _ = Describe("OrderEventNotifier and OrderEventListener", func() {
+	var (
+		ctx      context.Context
+		cancel   context.CancelFunc
+		order    *models.Order
+		event    *models.OutboxEvent
+		received chan *models.OutboxEvent
+		notifier repositories.OrderEventNotifier
+		err      error
+	)
+
+	BeforeEach(func() {
+		db := application.ResolveDB()
+		ctx, cancel = context.WithCancel(context.Background())
+
+		order = &models.Order{Total: models.NewMoney(1000, models.GBP), UserID: 5, RestaurantID: 8, PlacedAt: time.Now()}
+		Expect(db.Insert(order)).To(Succeed())
+		event, err = models.NewOrderPlacedEvent(order)
+		Expect(err).To(BeNil())
+		Expect(repositories.NewOutboxRepository(db).AddEvents(ctx, models.OutboxEvents{event})).To(Succeed())
+
+		received = make(chan *models.OutboxEvent, 10)
+		listener := repositories.NewOrderEventListener(db, func(ctx context.Context, e *models.OutboxEvent) error {
+			received <- e
+			return nil
+		})
+		listener.SetReceiveTimeout(50 * time.Millisecond)
+		go listener.Run(ctx)
+
+		notifier = repositories.NewOrderEventNotifier(db)
+	})
+
+	It("passes events announced on the channel to the listener", func() {
+		// The listener connects in the background, so keep announcing the
+		// event until it arrives.
+		Eventually(func() bool {
+			Expect(notifier.NotifyOrderEvents(ctx, models.OutboxEvents{event})).To(Succeed())
+			select {
+			case e := <-received:
+				Expect(e.ID).To(Equal(event.ID))
+				Expect(e.UserID).To(Equal(5))
+				Expect(e.Type).To(Equal(models.EventTypeOrderPlaced))
+				return true
+			case <-time.After(100 * time.Millisecond):
+				return false
+			}
+		}, 5*time.Second).Should(BeTrue())
+	})
+
+	It("only announces events once the transaction commits", func() {
+		Eventually(func() bool {
+			Expect(notifier.NotifyOrderEvents(ctx, models.OutboxEvents{event})).To(Succeed())
+			select {
+			case <-received:
+				return true
+			case <-time.After(100 * time.Millisecond):
+				return false
+			}
+		}, 5*time.Second).Should(BeTrue())
+
+		// Let any announcements that are still on their way arrive.
+		Eventually(func() bool {
+			select {
+			case <-received:
+				return false
+			case <-time.After(200 * time.Millisecond):
+				return true
+			}
+		}, 5*time.Second).Should(BeTrue())
+
+		tx, err := application.ResolveDB().Begin()
+		Expect(err).To(BeNil())
+		Expect(repositories.NewOrderEventNotifier(tx).NotifyOrderEvents(ctx, models.OutboxEvents{event})).To(Succeed())
+		Consistently(received, 200*time.Millisecond).ShouldNot(Receive())
+
+		Expect(tx.Commit()).To(Succeed())
+		Eventually(received, time.Second).Should(Receive())
+	})
+
+	AfterEach(func() {
+		cancel()
+
+		db := application.ResolveDB()
+		_, err = db.Model((*models.OutboxEvent)(nil)).Where("order_id = ?", order.ID).Delete()
+		Expect(err).To(BeNil())
+		_, err = db.Model(order).WherePK().ForceDelete()
+		Expect(err).To(BeNil())
+	})
+})
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+)
+
+// OrderItemRepository is the interface that an order item repository should
+// conform to.
+type OrderItemRepository interface {
+	FindItemsByOrderIDs(ctx context.Context, orderIDs []int) (models.OrderItems, error)
+}
+
+// NewOrderItemRepository returns a new implementation of an order item
+// repository.
+func NewOrderItemRepository(db orm.DB) *orderItemRepository {
+	return &orderItemRepository{
+		db: db,
+	}
+}
+
+// orderItemRepository is an implementation of an OrderItemRepository.
+type orderItemRepository struct {
+	db orm.DB
+}
+
+func (r *orderItemRepository) SetDB(db orm.DB) {
+	r.db = db
+}
+
+func (r *orderItemRepository) getDB() orm.DB {
+	if r.db != nil {
+		return r.db
+	}
+
+	r.db = application.ResolveDB()
+	return r.db
+}
+
+func (r *orderItemRepository) FindItemsByOrderIDs(ctx context.Context, orderIDs []int) (models.OrderItems, error) {
+	items := models.OrderItems{}
+	if len(orderIDs) == 0 {
+		return items, nil
+	}
+
+	err := conn(ctx, r.getDB()).ModelContext(ctx, &items).Where("order_id IN (?)", pg.In(orderIDs)).Order("id ASC").Select()
+	return items, err
+}
@@ -0,0 +1,65 @@
+package repositories_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/testing/factories"
+	"github.com/go-pg/pg"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OrderItemRepository", func() {
+	var (
+		tx       *pg.Tx
+		itemRepo repositories.OrderItemRepository
+		items    models.OrderItems
+		order    *models.Order
+		err      error
+	)
+
+	BeforeEach(func() {
+		tx, err = application.ResolveDB().Begin()
+		Expect(err).To(BeNil())
+		itemRepo = repositories.NewOrderItemRepository(tx)
+
+		order, err = factories.Order().WithUserID(5).WithRestaurantID(9).WithPlacedAt(time.Now()).Insert(tx)
+		Expect(err).To(BeNil())
+	})
+
+	Describe("FindItemsByOrderIDs", func() {
+		Describe("with no order IDs", func() {
+			It("returns an empty slice of items", func() {
+				items, err = itemRepo.FindItemsByOrderIDs(context.Background(), []int{})
+				Expect(err).To(BeNil())
+				Expect(len(items)).To(Equal(0))
+			})
+		})
+
+		Describe("when the order has items", func() {
+			BeforeEach(func() {
+				err = tx.Insert(&models.OrderItem{OrderID: order.ID, Name: "Chicken wrap", Quantity: 1, UnitPrice: 1250})
+				Expect(err).To(BeNil())
+				err = tx.Insert(&models.OrderItem{OrderID: order.ID, Name: "Chips", Quantity: 2, UnitPrice: 625})
+				Expect(err).To(BeNil())
+			})
+
+			It("returns the items of the order in the order they were added", func() {
+				items, err = itemRepo.FindItemsByOrderIDs(context.Background(), []int{order.ID})
+				Expect(err).To(BeNil())
+				Expect(len(items)).To(Equal(2))
+				Expect(items[0].Name).To(Equal("Chicken wrap"))
+				Expect(items[1].Name).To(Equal("Chips"))
+			})
+		})
+	})
+
+	AfterEach(func() {
+		err = tx.Rollback()
+		Expect(err).To(BeNil())
+	})
+})
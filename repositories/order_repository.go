@@ -1,14 +1,26 @@
 package repositories
 
 import (
+	"context"
+	"sort"
+
 	"github.com/SebastianCoetzee/blog-order-service-example/application"
 	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/go-pg/pg"
 	"github.com/go-pg/pg/orm"
 )
 
 // OrderRepository is the interface that an order repository should conform to.
 type OrderRepository interface {
-	FindAllOrdersByUserID(userID int) (models.Orders, error)
+	FindAllOrdersByUserID(ctx context.Context, userID int) (models.Orders, error)
+	FindAllOrdersByUserIDWithDeleted(ctx context.Context, userID int) (models.Orders, error)
+	FindOrdersByRestaurant(ctx context.Context, filter models.RestaurantOrderFilter) (models.Orders, error)
+	FindOrderByID(ctx context.Context, id int) (*models.Order, error)
+	FindOrderByIDForUpdate(ctx context.Context, id int) (*models.Order, error)
+	UpdateOrder(ctx context.Context, order *models.Order) error
+	SpendByCurrencyForUser(ctx context.Context, userID int) ([]*models.CurrencySpend, error)
+	RestaurantStatsForUser(ctx context.Context, userID int) ([]*models.RestaurantStats, error)
+	OrderCountsForUser(ctx context.Context, userID int, period models.StatsPeriod) ([]*models.PeriodCount, error)
 }
 
 // NewOrderRepository returns a new implementation of an order repository.
@@ -36,8 +48,182 @@ func (r *orderRepository) getDB() orm.DB {
 	return r.db
 }
 
-func (r *orderRepository) FindAllOrdersByUserID(userID int) (models.Orders, error) {
+func (r *orderRepository) FindAllOrdersByUserID(ctx context.Context, userID int) (models.Orders, error) {
+	orders := models.Orders{}
+	err := conn(ctx, r.getDB()).ModelContext(ctx, &orders).Where("user_id = ?", userID).Order("placed_at DESC").Select()
+	return orders, err
+}
+
+// FindAllOrdersByUserIDWithDeleted is like FindAllOrdersByUserID but includes
+// the user's deleted orders, which are still stored until the retention job
+// removes them.
+func (r *orderRepository) FindAllOrdersByUserIDWithDeleted(ctx context.Context, userID int) (models.Orders, error) {
+	orders, err := r.FindAllOrdersByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := models.Orders{}
+	err = conn(ctx, r.getDB()).ModelContext(ctx, &deleted).Where("user_id = ?", userID).Deleted().Select()
+	if err != nil {
+		return nil, err
+	}
+
+	orders = append(orders, deleted...)
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].PlacedAt.After(orders[j].PlacedAt)
+	})
+	return orders, nil
+}
+
+// FindOrdersByRestaurant returns up to filter.Limit orders of a restaurant,
+// newest first. Orders placed at the same time are ordered by descending ID so
+// that cursors are stable.
+func (r *orderRepository) FindOrdersByRestaurant(ctx context.Context, filter models.RestaurantOrderFilter) (models.Orders, error) {
 	orders := models.Orders{}
-	err := r.getDB().Model(&orders).Where("user_id = ?", userID).Order("placed_at DESC").Select()
+	q := conn(ctx, r.getDB()).ModelContext(ctx, &orders).Where("restaurant_id = ?", filter.RestaurantID)
+	if filter.From != nil {
+		q = q.Where("placed_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		q = q.Where("placed_at < ?", *filter.To)
+	}
+	if len(filter.Statuses) > 0 {
+		q = q.Where("status IN (?)", pg.In(filter.Statuses))
+	}
+	if filter.After != nil {
+		q = q.Where("(placed_at, id) < (?, ?)", filter.After.PlacedAt, filter.After.ID)
+	}
+
+	err := q.Order("placed_at DESC", "id DESC").Limit(filter.Limit).Select()
 	return orders, err
 }
+
+// FindOrderByID returns ErrNotFound when there is no order with the ID.
+func (r *orderRepository) FindOrderByID(ctx context.Context, id int) (*models.Order, error) {
+	order := &models.Order{}
+	err := conn(ctx, r.getDB()).ModelContext(ctx, order).Where("id = ?", id).Select()
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return order, nil
+}
+
+// FindOrderByIDForUpdate is like FindOrderByID but locks the row until the
+// surrounding transaction ends.
+func (r *orderRepository) FindOrderByIDForUpdate(ctx context.Context, id int) (*models.Order, error) {
+	order := &models.Order{}
+	err := conn(ctx, r.getDB()).ModelContext(ctx, order).Where("id = ?", id).For("UPDATE").Select()
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return order, nil
+}
+
+// UpdateOrder saves order and increments its version. It returns
+// ErrVersionConflict when the order's version in the database is no longer
+// the one it was read with.
+func (r *orderRepository) UpdateOrder(ctx context.Context, order *models.Order) error {
+	readVersion := order.Version
+	order.Version++
+
+	res, err := conn(ctx, r.getDB()).ModelContext(ctx, order).WherePK().Where("version = ?", readVersion).Update()
+	if err == nil && res.RowsAffected() == 0 {
+		err = ErrVersionConflict
+	}
+	if err != nil {
+		order.Version = readVersion
+		return err
+	}
+
+	return nil
+}
+
+// spentOrders selects the ID, restaurant, currency, net total and placement
+// time of every order of a user that was not cancelled or deleted. Raw
+// queries are not filtered by the soft delete column, so it is checked here.
+const spentOrders = `
+	SELECT o.id, o.restaurant_id, o.currency_code, o.placed_at,
+		o.total - COALESCE((SELECT sum(r.amount) FROM refunds r WHERE r.order_id = o.id), 0) AS net_total
+	FROM orders o
+	WHERE o.user_id = ? AND o.status != 'cancelled' AND o.deleted_at IS NULL`
+
+// currencySpendRow is a row of SpendByCurrencyForUser and
+// RestaurantStatsForUser.
+type currencySpendRow struct {
+	RestaurantID int
+	CurrencyCode models.Currency
+	OrderCount   int
+	Total        int64
+}
+
+// SpendByCurrencyForUser returns the number of orders and the net amount spent
+// in each currency, most orders first.
+func (r *orderRepository) SpendByCurrencyForUser(ctx context.Context, userID int) ([]*models.CurrencySpend, error) {
+	rows := []currencySpendRow{}
+	_, err := conn(ctx, r.getDB()).QueryContext(ctx, &rows, `
+		SELECT currency_code, count(*) AS order_count, sum(net_total) AS total
+		FROM (`+spentOrders+`) spent
+		GROUP BY currency_code
+		ORDER BY order_count DESC, currency_code`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	spend := make([]*models.CurrencySpend, 0, len(rows))
+	for _, row := range rows {
+		spend = append(spend, &models.CurrencySpend{
+			Currency:   row.CurrencyCode,
+			OrderCount: row.OrderCount,
+			Total:      models.NewMoney(row.Total, row.CurrencyCode),
+		})
+	}
+
+	return spend, nil
+}
+
+// RestaurantStatsForUser returns the number of orders and the net amount spent
+// in each currency at every restaurant that the user ordered from, ordered by
+// restaurant ID.
+func (r *orderRepository) RestaurantStatsForUser(ctx context.Context, userID int) ([]*models.RestaurantStats, error) {
+	rows := []currencySpendRow{}
+	_, err := conn(ctx, r.getDB()).QueryContext(ctx, &rows, `
+		SELECT restaurant_id, currency_code, count(*) AS order_count, sum(net_total) AS total
+		FROM (`+spentOrders+`) spent
+		GROUP BY restaurant_id, currency_code
+		ORDER BY restaurant_id, currency_code`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := []*models.RestaurantStats{}
+	statsByID := make(map[int]*models.RestaurantStats)
+	for _, row := range rows {
+		s, ok := statsByID[row.RestaurantID]
+		if !ok {
+			s = &models.RestaurantStats{RestaurantID: row.RestaurantID, Spend: []models.Money{}}
+			statsByID[row.RestaurantID] = s
+			stats = append(stats, s)
+		}
+
+		s.OrderCount += row.OrderCount
+		s.Spend = append(s.Spend, models.NewMoney(row.Total, row.CurrencyCode))
+	}
+
+	return stats, nil
+}
+
+// OrderCountsForUser returns the number of orders placed in each week or month
+// that the user placed any orders in, oldest first. Weeks start on Monday and
+// periods are in UTC.
+func (r *orderRepository) OrderCountsForUser(ctx context.Context, userID int, period models.StatsPeriod) ([]*models.PeriodCount, error) {
+	counts := []*models.PeriodCount{}
+	_, err := conn(ctx, r.getDB()).QueryContext(ctx, &counts, `
+		SELECT date_trunc(?, placed_at AT TIME ZONE 'UTC') AS start, count(*) AS order_count
+		FROM (`+spentOrders+`) spent
+		GROUP BY 1
+		ORDER BY 1`, string(period), userID)
+	return counts, err
+}
@@ -2,11 +2,11 @@ package repositories_test
 
 import (
 	"testing"
-	"time"
 
 	"github.com/SebastianCoetzee/blog-order-service-example/application"
 	"github.com/SebastianCoetzee/blog-order-service-example/models"
 	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories/repositorytest"
 	"github.com/go-pg/pg"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -17,76 +17,29 @@ func TestOrderRepository(t *testing.T) {
 	RunSpecs(t, "Order Repository Suite")
 }
 
-var _ = Describe("OrderRespository", func() {
-	var (
-		tx        *pg.Tx
-		orderRepo repositories.OrderRepository
-		orders    models.Orders
-		err       error
-
-		userID = 5
-	)
-
-	BeforeEach(func() {
-		tx, err = application.ResolveDB().Begin()
-		Expect(err).To(BeNil())
-		orderRepo = repositories.NewOrderRepository(tx)
-	})
-
-	Describe("FindAllOrdersByUserID", func() {
-		Describe("with no records in the database", func() {
-			It("returns an empty slice of orders", func() {
-				orders, err = orderRepo.FindAllOrdersByUserID(userID)
-				Expect(err).To(BeNil())
-				Expect(len(orders)).To(Equal(0))
-			})
-		})
+// postgresOrderRepositoryHarness runs each contract spec in a transaction
+// that is rolled back afterwards.
+type postgresOrderRepositoryHarness struct {
+	tx *pg.Tx
+}
 
-		Describe("when a few records exist", func() {
-			BeforeEach(func() {
-				order1 := &models.Order{
-					Total:        1000,
-					CurrencyCode: "GBP",
-					UserID:       userID,
-					RestaurantID: 8,
-					PlacedAt:     time.Now().Add(-72 * time.Hour),
-				}
-				err = tx.Insert(order1)
-				Expect(err).To(BeNil())
+func (h *postgresOrderRepositoryHarness) Setup() repositories.OrderRepository {
+	var err error
+	h.tx, err = application.ResolveDB().Begin()
+	Expect(err).To(BeNil())
+	return repositories.NewOrderRepository(h.tx)
+}
 
-				order2 := &models.Order{
-					Total:        2500,
-					CurrencyCode: "GBP",
-					UserID:       userID,
-					RestaurantID: 9,
-					PlacedAt:     time.Now().Add(-36 * time.Hour),
-				}
-				err = tx.Insert(order2)
-				Expect(err).To(BeNil())
+func (h *postgresOrderRepositoryHarness) AddOrder(order *models.Order) error {
+	return h.tx.Insert(order)
+}
 
-				order3 := &models.Order{
-					Total:        600,
-					CurrencyCode: "GBP",
-					UserID:       7,
-					RestaurantID: 8,
-					PlacedAt:     time.Now().Add(-24 * time.Hour),
-				}
-				err = tx.Insert(order3)
-				Expect(err).To(BeNil())
-			})
+func (h *postgresOrderRepositoryHarness) AddRefund(refund *models.Refund) error {
+	return h.tx.Insert(refund)
+}
 
-			It("returns only the records belonging to the user, in order from latest palced_at first", func() {
-				orders, err = orderRepo.FindAllOrdersByUserID(userID)
-				Expect(err).To(BeNil())
-				Expect(len(orders)).To(Equal(2))
-				Expect(orders[0].RestaurantID).To(Equal(9))
-				Expect(orders[1].RestaurantID).To(Equal(8))
-			})
-		})
-	})
+func (h *postgresOrderRepositoryHarness) Teardown() {
+	Expect(h.tx.Rollback()).To(Succeed())
+}
 
-	AfterEach(func() {
-		err = tx.Rollback()
-		Expect(err).To(BeNil())
-	})
-})
+var _ = repositorytest.DescribeOrderRepository("Postgres", &postgresOrderRepositoryHarness{})
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+)
+
+// OrderStatusChangeRepository is the interface that an order status change
+// repository should conform to.
+type OrderStatusChangeRepository interface {
+	FindStatusChangesByOrderIDs(ctx context.Context, orderIDs []int) (models.OrderStatusChanges, error)
+	CreateStatusChange(ctx context.Context, change *models.OrderStatusChange) error
+}
+
+// NewOrderStatusChangeRepository returns a new implementation of an order
+// status change repository.
+func NewOrderStatusChangeRepository(db orm.DB) *orderStatusChangeRepository {
+	return &orderStatusChangeRepository{
+		db: db,
+	}
+}
+
+// orderStatusChangeRepository is an implementation of an
+// OrderStatusChangeRepository.
+type orderStatusChangeRepository struct {
+	db orm.DB
+}
+
+func (r *orderStatusChangeRepository) SetDB(db orm.DB) {
+	r.db = db
+}
+
+func (r *orderStatusChangeRepository) getDB() orm.DB {
+	if r.db != nil {
+		return r.db
+	}
+
+	r.db = application.ResolveDB()
+	return r.db
+}
+
+func (r *orderStatusChangeRepository) FindStatusChangesByOrderIDs(ctx context.Context, orderIDs []int) (models.OrderStatusChanges, error) {
+	changes := models.OrderStatusChanges{}
+	if len(orderIDs) == 0 {
+		return changes, nil
+	}
+
+	err := conn(ctx, r.getDB()).ModelContext(ctx, &changes).Where("order_id IN (?)", pg.In(orderIDs)).Order("changed_at ASC").Select()
+	return changes, err
+}
+
+func (r *orderStatusChangeRepository) CreateStatusChange(ctx context.Context, change *models.OrderStatusChange) error {
+	_, err := conn(ctx, r.getDB()).ModelContext(ctx, change).Insert()
+	return err
+}
@@ -0,0 +1,65 @@
+package repositories_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/testing/factories"
+	"github.com/go-pg/pg"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OrderStatusChangeRepository", func() {
+	var (
+		tx               *pg.Tx
+		statusChangeRepo repositories.OrderStatusChangeRepository
+		changes          models.OrderStatusChanges
+		order            *models.Order
+		err              error
+	)
+
+	BeforeEach(func() {
+		tx, err = application.ResolveDB().Begin()
+		Expect(err).To(BeNil())
+		statusChangeRepo = repositories.NewOrderStatusChangeRepository(tx)
+
+		order, err = factories.Order().WithUserID(5).WithRestaurantID(9).WithPlacedAt(time.Now().Add(-time.Hour)).Insert(tx)
+		Expect(err).To(BeNil())
+	})
+
+	Describe("FindStatusChangesByOrderIDs", func() {
+		Describe("when the order has changed status", func() {
+			BeforeEach(func() {
+				err = tx.Insert(&models.OrderStatusChange{
+					OrderID:   order.ID,
+					Status:    models.OrderStatusAccepted,
+					ChangedAt: order.PlacedAt.Add(5 * time.Minute),
+				})
+				Expect(err).To(BeNil())
+				err = tx.Insert(&models.OrderStatusChange{
+					OrderID:   order.ID,
+					Status:    models.OrderStatusPlaced,
+					ChangedAt: order.PlacedAt,
+				})
+				Expect(err).To(BeNil())
+			})
+
+			It("returns the changes in the order they happened", func() {
+				changes, err = statusChangeRepo.FindStatusChangesByOrderIDs(context.Background(), []int{order.ID})
+				Expect(err).To(BeNil())
+				Expect(len(changes)).To(Equal(2))
+				Expect(changes[0].Status).To(Equal(models.OrderStatusPlaced))
+				Expect(changes[1].Status).To(Equal(models.OrderStatusAccepted))
+			})
+		})
+	})
+
+	AfterEach(func() {
+		err = tx.Rollback()
+		Expect(err).To(BeNil())
+	})
+})
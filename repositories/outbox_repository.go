@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+)
+
+// OutboxRepository is the interface that an outbox repository should conform
+// to.
+type OutboxRepository interface {
+	AddEvents(ctx context.Context, events models.OutboxEvents) error
+	ClaimDueEvents(ctx context.Context, limit int, now, leaseUntil time.Time) (models.OutboxEvents, error)
+	UpdateEvent(ctx context.Context, event *models.OutboxEvent) error
+	FindEventsForUser(ctx context.Context, userID int, afterID int64, since time.Time, limit int) (models.OutboxEvents, error)
+	FindEventByID(ctx context.Context, id int64) (*models.OutboxEvent, error)
+	FindEventsAfter(ctx context.Context, afterID int64, limit int) (models.OutboxEvents, error)
+}
+
+// NewOutboxRepository returns a new implementation of an outbox repository.
+func NewOutboxRepository(db orm.DB) *outboxRepository {
+	return &outboxRepository{
+		db: db,
+	}
+}
+
+// outboxRepository is an implementation of an OutboxRepository.
+type outboxRepository struct {
+	db orm.DB
+}
+
+func (r *outboxRepository) SetDB(db orm.DB) {
+	r.db = db
+}
+
+func (r *outboxRepository) getDB() orm.DB {
+	if r.db != nil {
+		return r.db
+	}
+
+	r.db = application.ResolveDB()
+	return r.db
+}
+
+// AddEvents stores events. It should be called with the context of the
+// transaction that makes the changes the events describe, so that the events
+// are stored if and only if the changes are.
+func (r *outboxRepository) AddEvents(ctx context.Context, events models.OutboxEvents) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	_, err := conn(ctx, r.getDB()).ModelContext(ctx, &events).Insert()
+	return err
+}
+
+// ClaimDueEvents returns up to limit pending events that are due to be
+// attempted, oldest first, and leases them by moving their next attempt to
+// leaseUntil, so that they are not claimed again while they are published.
+// Events that are locked by another relay are skipped, so several relays can
+// run at once. It should be called in a transaction that is committed before
+// the events are published.
+func (r *outboxRepository) ClaimDueEvents(ctx context.Context, limit int, now, leaseUntil time.Time) (models.OutboxEvents, error) {
+	db := conn(ctx, r.getDB())
+	events := models.OutboxEvents{}
+	err := db.ModelContext(ctx, &events).
+		Where("status = ?", models.OutboxStatusPending).
+		Where("next_attempt_at <= ?", now).
+		Order("id ASC").
+		Limit(limit).
+		For("UPDATE SKIP LOCKED").
+		Select()
+	if err != nil || len(events) == 0 {
+		return events, err
+	}
+
+	ids := make([]int64, len(events))
+	for i, event := range events {
+		event.NextAttemptAt = leaseUntil
+		ids[i] = event.ID
+	}
+
+	_, err = db.ModelContext(ctx, (*models.OutboxEvent)(nil)).
+		Set("next_attempt_at = ?", leaseUntil).
+		Where("id IN (?)", pg.In(ids)).
+		Update()
+	return events, err
+}
+
+// UpdateEvent saves the delivery state of event.
+func (r *outboxRepository) UpdateEvent(ctx context.Context, event *models.OutboxEvent) error {
+	_, err := conn(ctx, r.getDB()).ModelContext(ctx, event).
+		Column("status", "attempts", "last_error", "next_attempt_at", "delivered_at").
+		WherePK().
+		Update()
+	return err
+}
+
+// FindEventsForUser returns up to limit events about the user's orders with
+// IDs greater than afterID that occurred at or after since, oldest first,
+// whatever their delivery state.
+func (r *outboxRepository) FindEventsForUser(ctx context.Context, userID int, afterID int64, since time.Time, limit int) (models.OutboxEvents, error) {
+	events := models.OutboxEvents{}
+	err := conn(ctx, r.getDB()).ModelContext(ctx, &events).
+		Where("user_id = ?", userID).
+		Where("id > ?", afterID).
+		Where("occurred_at >= ?", since).
+		Order("id ASC").
+		Limit(limit).
+		Select()
+	return events, err
+}
+
+// FindEventByID returns ErrNotFound when the event does not exist.
+func (r *outboxRepository) FindEventByID(ctx context.Context, id int64) (*models.OutboxEvent, error) {
+	event := &models.OutboxEvent{}
+	err := conn(ctx, r.getDB()).ModelContext(ctx, event).Where("id = ?", id).Select()
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return event, nil
+}
+
+// FindEventsAfter returns up to limit events with IDs greater than afterID,
+// oldest first, whatever their delivery state.
+func (r *outboxRepository) FindEventsAfter(ctx context.Context, afterID int64, limit int) (models.OutboxEvents, error) {
+	events := models.OutboxEvents{}
+	err := conn(ctx, r.getDB()).ModelContext(ctx, &events).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Select()
+	return events, err
+}
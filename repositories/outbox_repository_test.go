@@ -0,0 +1,93 @@
+package repositories_test
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/go-pg/pg"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OutboxRepository", func() {
+	var (
+		tx         *pg.Tx
+		outboxRepo repositories.OutboxRepository
+		added      models.OutboxEvents
+		err        error
+
+		now = time.Date(2019, 5, 3, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		tx, err = application.ResolveDB().Begin()
+		Expect(err).To(BeNil())
+		outboxRepo = repositories.NewOutboxRepository(tx)
+
+		added = models.OutboxEvents{
+			{Type: models.EventTypeOrderPlaced, SchemaVersion: 1, OrderID: 3, UserID: 5, Payload: json.RawMessage(`{}`), OccurredAt: now, NextAttemptAt: now.Add(-time.Minute)},
+			{Type: models.EventTypeOrderRefunded, SchemaVersion: 1, OrderID: 3, UserID: 5, Payload: json.RawMessage(`{}`), OccurredAt: now, NextAttemptAt: now.Add(time.Minute)},
+			{Type: models.EventTypeOrderStatusChanged, SchemaVersion: 1, OrderID: 4, UserID: 6, Payload: json.RawMessage(`{}`), OccurredAt: now, NextAttemptAt: now},
+		}
+		err = outboxRepo.AddEvents(context.Background(), added)
+		Expect(err).To(BeNil())
+	})
+
+	Describe("ClaimDueEvents", func() {
+		It("returns pending events that are due, oldest first", func() {
+			due, err := outboxRepo.ClaimDueEvents(context.Background(), 10, now, now.Add(time.Minute))
+			Expect(err).To(BeNil())
+			Expect(len(due)).To(Equal(2))
+			Expect(due[0].ID).To(Equal(added[0].ID))
+			Expect(due[0].Status).To(Equal(models.OutboxStatusPending))
+			Expect(due[1].ID).To(Equal(added[2].ID))
+		})
+
+		It("does not return the events it has leased again until the lease runs out", func() {
+			_, err := outboxRepo.ClaimDueEvents(context.Background(), 10, now, now.Add(time.Minute))
+			Expect(err).To(BeNil())
+
+			due, err := outboxRepo.ClaimDueEvents(context.Background(), 10, now, now.Add(time.Minute))
+			Expect(err).To(BeNil())
+			Expect(due).To(BeEmpty())
+
+			due, err = outboxRepo.ClaimDueEvents(context.Background(), 10, now.Add(time.Minute), now.Add(2*time.Minute))
+			Expect(err).To(BeNil())
+			Expect(len(due)).To(Equal(3))
+		})
+	})
+
+	Describe("UpdateEvent", func() {
+		It("stops returning delivered events", func() {
+			added[0].MarkDelivered(now)
+			Expect(outboxRepo.UpdateEvent(context.Background(), added[0])).To(Succeed())
+
+			due, err := outboxRepo.ClaimDueEvents(context.Background(), 10, now, now.Add(time.Minute))
+			Expect(err).To(BeNil())
+			Expect(len(due)).To(Equal(1))
+			Expect(due[0].ID).To(Equal(added[2].ID))
+		})
+	})
+
+	Describe("FindEventsForUser", func() {
+		It("returns the user's events after the given one, whatever their delivery state", func() {
+			added[0].MarkDelivered(now)
+			Expect(outboxRepo.UpdateEvent(context.Background(), added[0])).To(Succeed())
+
+			found, err := outboxRepo.FindEventsForUser(context.Background(), 5, added[0].ID, now.Add(-time.Hour), 10)
+			Expect(err).To(BeNil())
+			Expect(len(found)).To(Equal(1))
+			Expect(found[0].ID).To(Equal(added[1].ID))
+			Expect(found[0].UserID).To(Equal(5))
+		})
+	})
+
+	AfterEach(func() {
+		err = tx.Rollback()
+		Expect(err).To(BeNil())
+	})
+})
@@ -0,0 +1,61 @@
+package repositories
+
+// Every table that stores personal data about the user who placed an order
+// must be covered by a statement in personalDataScrubbers and a query in
+// personalDataChecks, so that the retention job and erasure requests remove
+// the data and erasures can be verified.
+
+// personalDataScrubbers are the statements that remove the personal data of
+// a set of orders. Each is formatted with the tombstone user ID as ?0, the
+// time of the anonymisation as ?1 and the order IDs as ?2. Financial amounts
+// are kept for accounting.
+var personalDataScrubbers = []string{
+	// Stored responses hold the orders of the user whose scope they are in.
+	// The keys have to go before the orders lose their user ID, and all of
+	// the user's keys go, so a retry after this is handled as a new request.
+	`DELETE FROM idempotency_keys
+	WHERE scope IN (SELECT 'user:' || user_id FROM orders WHERE id IN (?2))`,
+	`UPDATE orders SET
+		user_id = ?0,
+		cancelled_by = CASE WHEN cancelled_by LIKE 'user:%' THEN 'user:' || ?0 ELSE cancelled_by END,
+		anonymised_at = ?1,
+		version = version + 1
+	WHERE id IN (?2)`,
+	`UPDATE refunds SET reason = '' WHERE order_id IN (?2)`,
+	`UPDATE webhook_deliveries SET body = body #- '{payload,user_id}' #- '{payload,reason}'
+	WHERE event_id IN (SELECT id FROM outbox_events WHERE order_id IN (?2))`,
+	`UPDATE outbox_events SET user_id = ?0, payload = payload - 'user_id' - 'reason'
+	WHERE order_id IN (?2)`,
+	// The audit log refuses changes unless they are marked as redactions,
+	// for the rest of the transaction.
+	redactOrderAudit,
+	`UPDATE order_audit SET
+		actor_id = CASE WHEN actor_id LIKE 'user:%' THEN 'user:' || ?0 ELSE actor_id END,
+		before = CASE WHEN before->>'cancelled_by' LIKE 'user:%'
+			THEN jsonb_set(before, '{cancelled_by}', to_jsonb('user:' || ?0)) ELSE before END,
+		after = CASE WHEN after->>'cancelled_by' LIKE 'user:%'
+			THEN jsonb_set(after, '{cancelled_by}', to_jsonb('user:' || ?0)) ELSE after END
+	WHERE order_id IN (?2)`,
+}
+
+// redactOrderAudit allows the append-only audit log to be changed until the
+// surrounding transaction ends.
+const redactOrderAudit = `SELECT set_config('order_audit.redact', 'on', true)`
+
+// personalDataCheck counts the rows of a table that still hold the personal
+// data of a user, whose ID the query is formatted with as ?0.
+type personalDataCheck struct {
+	table string
+	query string
+}
+
+// personalDataChecks are the queries that verify that nothing identifies a
+// user any more.
+var personalDataChecks = []personalDataCheck{
+	{"orders", `SELECT count(*) FROM orders WHERE user_id = ?0 OR cancelled_by = 'user:' || ?0`},
+	{"outbox_events", `SELECT count(*) FROM outbox_events WHERE user_id = ?0 OR payload->>'user_id' = ?0::text`},
+	{"order_audit", `SELECT count(*) FROM order_audit
+		WHERE actor_id = 'user:' || ?0 OR before->>'cancelled_by' = 'user:' || ?0 OR after->>'cancelled_by' = 'user:' || ?0`},
+	{"webhook_deliveries", `SELECT count(*) FROM webhook_deliveries WHERE body->'payload'->>'user_id' = ?0::text`},
+	{"idempotency_keys", `SELECT count(*) FROM idempotency_keys WHERE scope = 'user:' || ?0`},
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+)
+
+// RefundRepository is the interface that a refund repository should conform
+// to.
+type RefundRepository interface {
+	FindRefundsByOrderIDs(ctx context.Context, orderIDs []int) (models.Refunds, error)
+	CreateRefund(ctx context.Context, refund *models.Refund) error
+}
+
+// NewRefundRepository returns a new implementation of a refund repository.
+func NewRefundRepository(db orm.DB) *refundRepository {
+	return &refundRepository{
+		db: db,
+	}
+}
+
+// refundRepository is an implementation of a RefundRepository.
+type refundRepository struct {
+	db orm.DB
+}
+
+func (r *refundRepository) SetDB(db orm.DB) {
+	r.db = db
+}
+
+func (r *refundRepository) getDB() orm.DB {
+	if r.db != nil {
+		return r.db
+	}
+
+	r.db = application.ResolveDB()
+	return r.db
+}
+
+func (r *refundRepository) FindRefundsByOrderIDs(ctx context.Context, orderIDs []int) (models.Refunds, error) {
+	refunds := models.Refunds{}
+	if len(orderIDs) == 0 {
+		return refunds, nil
+	}
+
+	err := conn(ctx, r.getDB()).ModelContext(ctx, &refunds).Where("order_id IN (?)", pg.In(orderIDs)).Order("created_at ASC", "id ASC").Select()
+	return refunds, err
+}
+
+func (r *refundRepository) CreateRefund(ctx context.Context, refund *models.Refund) error {
+	_, err := conn(ctx, r.getDB()).ModelContext(ctx, refund).Insert()
+	return err
+}
@@ -0,0 +1,65 @@
+package repositories_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/testing/factories"
+	"github.com/go-pg/pg"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RefundRepository", func() {
+	var (
+		tx         *pg.Tx
+		refundRepo repositories.RefundRepository
+		refunds    models.Refunds
+		order      *models.Order
+		err        error
+	)
+
+	BeforeEach(func() {
+		tx, err = application.ResolveDB().Begin()
+		Expect(err).To(BeNil())
+		refundRepo = repositories.NewRefundRepository(tx)
+
+		order, err = factories.Order().WithUserID(5).WithRestaurantID(9).WithPlacedAt(time.Now().Add(-time.Hour)).Insert(tx)
+		Expect(err).To(BeNil())
+	})
+
+	Describe("CreateRefund", func() {
+		It("stores refunds that are returned oldest first", func() {
+			err = refundRepo.CreateRefund(context.Background(), &models.Refund{
+				OrderID:   order.ID,
+				Amount:    models.NewMoney(500, models.GBP),
+				Reason:    "Cold chips",
+				ActorID:   "agent:1",
+				CreatedAt: time.Now().Add(-time.Minute),
+			})
+			Expect(err).To(BeNil())
+			err = refundRepo.CreateRefund(context.Background(), &models.Refund{
+				OrderID:   order.ID,
+				Amount:    models.NewMoney(1000, models.GBP),
+				Reason:    "Missing item",
+				ActorID:   "agent:1",
+				CreatedAt: time.Now(),
+			})
+			Expect(err).To(BeNil())
+
+			refunds, err = refundRepo.FindRefundsByOrderIDs(context.Background(), []int{order.ID})
+			Expect(err).To(BeNil())
+			Expect(len(refunds)).To(Equal(2))
+			Expect(refunds[0].Reason).To(Equal("Cold chips"))
+			Expect(refunds.Total(models.GBP)).To(Equal(models.NewMoney(1500, models.GBP)))
+		})
+	})
+
+	AfterEach(func() {
+		err = tx.Rollback()
+		Expect(err).To(BeNil())
+	})
+})
@@ -0,0 +1,336 @@
+// Package repositorytest holds the behaviour that every implementation of a
+// repository interface must share, as Ginkgo specs that implementations plug
+// into from their own test suites.
+package repositorytest
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// OrderRepositoryHarness sets up an implementation of OrderRepository for the
+// contract specs.
+type OrderRepositoryHarness interface {
+	// Setup returns an OrderRepository without any orders. It is called
+	// before every spec.
+	Setup() repositories.OrderRepository
+	// AddOrder stores an order the way placing it would, setting its ID.
+	AddOrder(order *models.Order) error
+	// AddRefund stores a refund of an order.
+	AddRefund(refund *models.Refund) error
+	// Teardown is called after every spec.
+	Teardown()
+}
+
+// DescribeOrderRepository registers the specs that every OrderRepository must
+// pass, run against the repositories that harness sets up. Call it at the top
+// level of a test file, like Describe.
+func DescribeOrderRepository(name string, harness OrderRepositoryHarness) bool {
+	return Describe(name+" OrderRepository contract", func() {
+		var (
+			orderRepo repositories.OrderRepository
+			ctx       = context.Background()
+
+			userID   = 5
+			placedAt = time.Date(2019, 4, 20, 12, 0, 0, 0, time.UTC)
+		)
+
+		addOrders := func(orders ...*models.Order) models.Orders {
+			for _, order := range orders {
+				Expect(harness.AddOrder(order)).To(Succeed())
+			}
+			return orders
+		}
+
+		deleteOrder := func(id int) {
+			order, err := orderRepo.FindOrderByIDForUpdate(ctx, id)
+			Expect(err).To(BeNil())
+			order.DeletedAt = placedAt
+			Expect(orderRepo.UpdateOrder(ctx, order)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			orderRepo = harness.Setup()
+		})
+
+		AfterEach(func() {
+			harness.Teardown()
+		})
+
+		Describe("FindAllOrdersByUserID", func() {
+			It("returns an empty slice when the user has no orders", func() {
+				orders, err := orderRepo.FindAllOrdersByUserID(ctx, userID)
+				Expect(err).To(BeNil())
+				Expect(orders).NotTo(BeNil())
+				Expect(orders).To(BeEmpty())
+			})
+
+			Describe("when a few orders exist", func() {
+				var orders models.Orders
+
+				BeforeEach(func() {
+					orders = addOrders(
+						&models.Order{Total: models.NewMoney(1000, models.GBP), UserID: userID, RestaurantID: 8, PlacedAt: placedAt.Add(-72 * time.Hour)},
+						&models.Order{Total: models.NewMoney(2500, models.GBP), UserID: userID, RestaurantID: 9, PlacedAt: placedAt.Add(-36 * time.Hour)},
+						&models.Order{Total: models.NewMoney(600, models.GBP), UserID: 7, RestaurantID: 8, PlacedAt: placedAt.Add(-24 * time.Hour)},
+						&models.Order{Total: models.NewMoney(800, models.GBP), UserID: userID, RestaurantID: 8, PlacedAt: placedAt.Add(-48 * time.Hour)},
+					)
+				})
+
+				It("returns only the user's orders, latest placed_at first", func() {
+					found, err := orderRepo.FindAllOrdersByUserID(ctx, userID)
+					Expect(err).To(BeNil())
+					Expect(found.IDs()).To(Equal([]int{orders[1].ID, orders[3].ID, orders[0].ID}))
+				})
+
+				It("includes deleted orders only when asked to", func() {
+					deleteOrder(orders[1].ID)
+
+					found, err := orderRepo.FindAllOrdersByUserID(ctx, userID)
+					Expect(err).To(BeNil())
+					Expect(found.IDs()).To(Equal([]int{orders[3].ID, orders[0].ID}))
+
+					found, err = orderRepo.FindAllOrdersByUserIDWithDeleted(ctx, userID)
+					Expect(err).To(BeNil())
+					Expect(found.IDs()).To(Equal([]int{orders[1].ID, orders[3].ID, orders[0].ID}))
+					Expect(found[0].DeletedAt.IsZero()).To(BeFalse())
+				})
+			})
+		})
+
+		Describe("FindOrdersByRestaurant", func() {
+			var orders models.Orders
+
+			BeforeEach(func() {
+				orders = addOrders(
+					&models.Order{Total: models.NewMoney(1000, models.GBP), UserID: userID, RestaurantID: 8, PlacedAt: placedAt.Add(-48 * time.Hour)},
+					&models.Order{Total: models.NewMoney(1000, models.GBP), UserID: userID, RestaurantID: 8, PlacedAt: placedAt, Status: models.OrderStatusDelivered},
+					&models.Order{Total: models.NewMoney(1000, models.GBP), UserID: 7, RestaurantID: 8, PlacedAt: placedAt},
+					&models.Order{Total: models.NewMoney(1000, models.GBP), UserID: userID, RestaurantID: 9, PlacedAt: placedAt},
+				)
+			})
+
+			It("returns an empty slice for a restaurant without orders", func() {
+				found, err := orderRepo.FindOrdersByRestaurant(ctx, models.RestaurantOrderFilter{RestaurantID: 10, Limit: 10})
+				Expect(err).To(BeNil())
+				Expect(found).NotTo(BeNil())
+				Expect(found).To(BeEmpty())
+			})
+
+			It("returns the restaurant's orders newest first, breaking ties by descending ID", func() {
+				found, err := orderRepo.FindOrdersByRestaurant(ctx, models.RestaurantOrderFilter{RestaurantID: 8, Limit: 10})
+				Expect(err).To(BeNil())
+				Expect(found.IDs()).To(Equal([]int{orders[2].ID, orders[1].ID, orders[0].ID}))
+			})
+
+			It("leaves out deleted orders", func() {
+				deleteOrder(orders[2].ID)
+
+				found, err := orderRepo.FindOrdersByRestaurant(ctx, models.RestaurantOrderFilter{RestaurantID: 8, Limit: 10})
+				Expect(err).To(BeNil())
+				Expect(found.IDs()).To(Equal([]int{orders[1].ID, orders[0].ID}))
+			})
+
+			It("filters by placement time and status", func() {
+				from := placedAt.Add(-time.Hour)
+				found, err := orderRepo.FindOrdersByRestaurant(ctx, models.RestaurantOrderFilter{
+					RestaurantID: 8,
+					From:         &from,
+					Statuses:     []models.OrderStatus{models.OrderStatusPlaced},
+					Limit:        10,
+				})
+				Expect(err).To(BeNil())
+				Expect(found.IDs()).To(Equal([]int{orders[2].ID}))
+
+				to := placedAt
+				found, err = orderRepo.FindOrdersByRestaurant(ctx, models.RestaurantOrderFilter{RestaurantID: 8, To: &to, Limit: 10})
+				Expect(err).To(BeNil())
+				Expect(found.IDs()).To(Equal([]int{orders[0].ID}))
+			})
+
+			It("pages through the orders with cursors", func() {
+				filter := models.RestaurantOrderFilter{RestaurantID: 8, Limit: 2}
+				found, err := orderRepo.FindOrdersByRestaurant(ctx, filter)
+				Expect(err).To(BeNil())
+				Expect(found.IDs()).To(Equal([]int{orders[2].ID, orders[1].ID}))
+
+				filter.After = models.CursorAfter(found[1])
+				found, err = orderRepo.FindOrdersByRestaurant(ctx, filter)
+				Expect(err).To(BeNil())
+				Expect(found.IDs()).To(Equal([]int{orders[0].ID}))
+
+				filter.After = models.CursorAfter(found[0])
+				found, err = orderRepo.FindOrdersByRestaurant(ctx, filter)
+				Expect(err).To(BeNil())
+				Expect(found).To(BeEmpty())
+			})
+		})
+
+		Describe("FindOrderByID", func() {
+			It("returns ErrNotFound when the order does not exist", func() {
+				_, err := orderRepo.FindOrderByID(ctx, 999999)
+				Expect(err).To(Equal(repositories.ErrNotFound))
+
+				_, err = orderRepo.FindOrderByIDForUpdate(ctx, 999999)
+				Expect(err).To(Equal(repositories.ErrNotFound))
+			})
+
+			Describe("when the order exists", func() {
+				var order *models.Order
+
+				BeforeEach(func() {
+					order = addOrders(&models.Order{Total: models.NewMoney(1000, models.GBP), UserID: userID, RestaurantID: 8, PlacedAt: placedAt})[0]
+				})
+
+				It("returns the order with the default status and version", func() {
+					found, err := orderRepo.FindOrderByID(ctx, order.ID)
+					Expect(err).To(BeNil())
+					Expect(found.UserID).To(Equal(userID))
+					Expect(found.RestaurantID).To(Equal(8))
+					Expect(found.Total).To(Equal(models.NewMoney(1000, models.GBP)))
+					Expect(found.PlacedAt.Equal(placedAt)).To(BeTrue())
+					Expect(found.Status).To(Equal(models.OrderStatusPlaced))
+					Expect(found.Version).To(Equal(1))
+				})
+
+				It("returns ErrNotFound once the order is deleted", func() {
+					deleteOrder(order.ID)
+
+					_, err := orderRepo.FindOrderByID(ctx, order.ID)
+					Expect(err).To(Equal(repositories.ErrNotFound))
+				})
+			})
+		})
+
+		Describe("UpdateOrder", func() {
+			var order *models.Order
+
+			BeforeEach(func() {
+				order = addOrders(&models.Order{Total: models.NewMoney(1000, models.GBP), UserID: userID, RestaurantID: 8, PlacedAt: placedAt})[0]
+			})
+
+			It("persists the cancellation and increments the version", func() {
+				found, err := orderRepo.FindOrderByIDForUpdate(ctx, order.ID)
+				Expect(err).To(BeNil())
+
+				cancelledAt := placedAt.Add(time.Minute)
+				found.Status = models.OrderStatusCancelled
+				found.CancelledAt = &cancelledAt
+				found.CancelledBy = "user:5"
+				found.CancellationReason = models.CancellationReasonChangedMind
+				Expect(orderRepo.UpdateOrder(ctx, found)).To(Succeed())
+				Expect(found.Version).To(Equal(2))
+
+				found, err = orderRepo.FindOrderByID(ctx, order.ID)
+				Expect(err).To(BeNil())
+				Expect(found.Status).To(Equal(models.OrderStatusCancelled))
+				Expect(found.CancelledAt.Equal(cancelledAt)).To(BeTrue())
+				Expect(found.CancelledBy).To(Equal("user:5"))
+				Expect(found.CancellationReason).To(Equal(models.CancellationReasonChangedMind))
+				Expect(found.Version).To(Equal(2))
+			})
+
+			It("refuses to overwrite a newer version", func() {
+				fresh, err := orderRepo.FindOrderByID(ctx, order.ID)
+				Expect(err).To(BeNil())
+				stale, err := orderRepo.FindOrderByID(ctx, order.ID)
+				Expect(err).To(BeNil())
+
+				fresh.Status = models.OrderStatusAccepted
+				Expect(orderRepo.UpdateOrder(ctx, fresh)).To(Succeed())
+
+				stale.Status = models.OrderStatusCancelled
+				Expect(orderRepo.UpdateOrder(ctx, stale)).To(Equal(repositories.ErrVersionConflict))
+				Expect(stale.Version).To(Equal(1))
+			})
+
+			It("refuses to change a deleted order", func() {
+				deleteOrder(order.ID)
+
+				order.Version = 2
+				order.Status = models.OrderStatusAccepted
+				Expect(orderRepo.UpdateOrder(ctx, order)).To(Equal(repositories.ErrVersionConflict))
+			})
+		})
+
+		Describe("stats", func() {
+			It("returns empty results for a user without orders", func() {
+				spend, err := orderRepo.SpendByCurrencyForUser(ctx, userID)
+				Expect(err).To(BeNil())
+				Expect(spend).To(BeEmpty())
+
+				stats, err := orderRepo.RestaurantStatsForUser(ctx, userID)
+				Expect(err).To(BeNil())
+				Expect(stats).To(BeEmpty())
+
+				counts, err := orderRepo.OrderCountsForUser(ctx, userID, models.StatsPeriodWeek)
+				Expect(err).To(BeNil())
+				Expect(counts).To(BeEmpty())
+			})
+
+			Describe("when the user has orders", func() {
+				var orders models.Orders
+
+				BeforeEach(func() {
+					orders = addOrders(
+						&models.Order{Total: models.NewMoney(1000, models.GBP), UserID: userID, RestaurantID: 8, PlacedAt: time.Date(2019, 3, 4, 12, 0, 0, 0, time.UTC)},
+						&models.Order{Total: models.NewMoney(2000, models.GBP), UserID: userID, RestaurantID: 8, PlacedAt: time.Date(2019, 4, 2, 12, 0, 0, 0, time.UTC)},
+						&models.Order{Total: models.NewMoney(1500, models.EUR), UserID: userID, RestaurantID: 9, PlacedAt: time.Date(2019, 4, 9, 12, 0, 0, 0, time.UTC)},
+						&models.Order{Total: models.NewMoney(900, models.GBP), UserID: userID, RestaurantID: 9, PlacedAt: time.Date(2019, 4, 9, 13, 0, 0, 0, time.UTC), Status: models.OrderStatusCancelled},
+						&models.Order{Total: models.NewMoney(700, models.GBP), UserID: 7, RestaurantID: 8, PlacedAt: time.Date(2019, 4, 9, 13, 0, 0, 0, time.UTC)},
+						&models.Order{Total: models.NewMoney(300, models.GBP), UserID: userID, RestaurantID: 9, PlacedAt: time.Date(2019, 4, 10, 13, 0, 0, 0, time.UTC)},
+					)
+					deleteOrder(orders[5].ID)
+
+					Expect(harness.AddRefund(&models.Refund{
+						OrderID:   orders[1].ID,
+						Amount:    models.NewMoney(500, models.GBP),
+						Reason:    "Missing item",
+						ActorID:   "agent:1",
+						CreatedAt: placedAt,
+					})).To(Succeed())
+				})
+
+				It("adds up net spend by currency, leaving out cancelled and deleted orders", func() {
+					spend, err := orderRepo.SpendByCurrencyForUser(ctx, userID)
+					Expect(err).To(BeNil())
+					Expect(spend).To(Equal([]*models.CurrencySpend{
+						{Currency: models.GBP, OrderCount: 2, Total: models.NewMoney(2500, models.GBP)},
+						{Currency: models.EUR, OrderCount: 1, Total: models.NewMoney(1500, models.EUR)},
+					}))
+				})
+
+				It("adds up orders and spend by restaurant", func() {
+					stats, err := orderRepo.RestaurantStatsForUser(ctx, userID)
+					Expect(err).To(BeNil())
+					Expect(stats).To(Equal([]*models.RestaurantStats{
+						{RestaurantID: 8, OrderCount: 2, Spend: []models.Money{models.NewMoney(2500, models.GBP)}},
+						{RestaurantID: 9, OrderCount: 1, Spend: []models.Money{models.NewMoney(1500, models.EUR)}},
+					}))
+				})
+
+				It("counts orders per week, starting on Monday", func() {
+					counts, err := orderRepo.OrderCountsForUser(ctx, userID, models.StatsPeriodWeek)
+					Expect(err).To(BeNil())
+					Expect(counts).To(HaveLen(3))
+					Expect(counts[0].Start.Equal(time.Date(2019, 3, 4, 0, 0, 0, 0, time.UTC))).To(BeTrue())
+					Expect(counts[1].Start.Equal(time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC))).To(BeTrue())
+					Expect(counts[2].Start.Equal(time.Date(2019, 4, 8, 0, 0, 0, 0, time.UTC))).To(BeTrue())
+				})
+
+				It("counts orders per month", func() {
+					counts, err := orderRepo.OrderCountsForUser(ctx, userID, models.StatsPeriodMonth)
+					Expect(err).To(BeNil())
+					Expect(counts).To(HaveLen(2))
+					Expect(counts[0].OrderCount).To(Equal(1))
+					Expect(counts[1].OrderCount).To(Equal(2))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+)
+
+// RetentionRepository is the interface that a repository of the orders that
+// have outlived the retention period should conform to.
+type RetentionRepository interface {
+	CountExpiredOrders(ctx context.Context, cutoff time.Time, action models.RetentionAction) (int, error)
+	ClaimExpiredOrderIDs(ctx context.Context, cutoff time.Time, action models.RetentionAction, limit int) ([]int, error)
+	AnonymiseOrders(ctx context.Context, orderIDs []int, at time.Time) error
+	PurgeOrders(ctx context.Context, orderIDs []int) error
+}
+
+// NewRetentionRepository returns a new implementation of a retention
+// repository.
+func NewRetentionRepository(db orm.DB) *retentionRepository {
+	return &retentionRepository{
+		db: db,
+	}
+}
+
+// retentionRepository is an implementation of a RetentionRepository.
+type retentionRepository struct {
+	db orm.DB
+}
+
+func (r *retentionRepository) SetDB(db orm.DB) {
+	r.db = db
+}
+
+func (r *retentionRepository) getDB() orm.DB {
+	if r.db != nil {
+		return r.db
+	}
+
+	r.db = application.ResolveDB()
+	return r.db
+}
+
+// expiredOrders is the condition on orders that action still has to be taken
+// on. Deleted orders are included, so the queries are written by hand rather
+// than through the model.
+func expiredOrders(action models.RetentionAction) string {
+	if action == models.RetentionActionAnonymise {
+		return "placed_at < ? AND anonymised_at IS NULL"
+	}
+
+	return "placed_at < ?"
+}
+
+// CountExpiredOrders returns the number of orders placed before cutoff that
+// action has not been taken on yet.
+func (r *retentionRepository) CountExpiredOrders(ctx context.Context, cutoff time.Time, action models.RetentionAction) (int, error) {
+	var n int
+	_, err := conn(ctx, r.getDB()).QueryOneContext(ctx, pg.Scan(&n), `
+		SELECT count(*) FROM orders WHERE `+expiredOrders(action), cutoff)
+	return n, err
+}
+
+// ClaimExpiredOrderIDs returns the IDs of up to limit orders placed before
+// cutoff that action has not been taken on yet, oldest first, and locks them
+// until the surrounding transaction ends. Orders that are locked elsewhere
+// are skipped.
+func (r *retentionRepository) ClaimExpiredOrderIDs(ctx context.Context, cutoff time.Time, action models.RetentionAction, limit int) ([]int, error) {
+	ids := []int{}
+	_, err := conn(ctx, r.getDB()).QueryContext(ctx, &ids, `
+		SELECT id FROM orders WHERE `+expiredOrders(action)+`
+		ORDER BY placed_at, id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED`, cutoff, limit)
+	return ids, err
+}
+
+// AnonymiseOrders runs every statement in personalDataScrubbers against the
+// orders, removing the personal data of the orders and of everything recorded
+// about them. The orders get a new version.
+func (r *retentionRepository) AnonymiseOrders(ctx context.Context, orderIDs []int, at time.Time) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	db := conn(ctx, r.getDB())
+	for _, statement := range personalDataScrubbers {
+		if _, err := db.ExecContext(ctx, statement, models.AnonymousUserID, at, pg.In(orderIDs)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PurgeOrders deletes the orders and everything recorded about them, deleted
+// or not.
+func (r *retentionRepository) PurgeOrders(ctx context.Context, orderIDs []int) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	statements := []string{
+		`DELETE FROM webhook_deliveries
+		WHERE event_id IN (SELECT id FROM outbox_events WHERE order_id IN (?))`,
+		`DELETE FROM outbox_events WHERE order_id IN (?)`,
+		`DELETE FROM refunds WHERE order_id IN (?)`,
+		`DELETE FROM order_status_changes WHERE order_id IN (?)`,
+		`DELETE FROM order_items WHERE order_id IN (?)`,
+		redactOrderAudit,
+		`DELETE FROM order_audit WHERE order_id IN (?)`,
+		`DELETE FROM orders WHERE id IN (?)`,
+	}
+
+	db := conn(ctx, r.getDB())
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement, pg.In(orderIDs)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package repositories_test
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/go-pg/pg"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RetentionRepository", func() {
+	var (
+		tx            *pg.Tx
+		retentionRepo repositories.RetentionRepository
+		auditRepo     repositories.OrderAuditRepository
+		orders        models.Orders
+		event         *models.OutboxEvent
+		err           error
+
+		cutoff = time.Date(1995, 1, 1, 0, 0, 0, 0, time.UTC)
+		now    = time.Date(2019, 5, 10, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		tx, err = application.ResolveDB().Begin()
+		Expect(err).To(BeNil())
+		retentionRepo = repositories.NewRetentionRepository(tx)
+
+		orders = models.Orders{
+			{Total: models.NewMoney(1000, models.GBP), UserID: 5, RestaurantID: 8, PlacedAt: cutoff.AddDate(-2, 0, 0)},
+			{Total: models.NewMoney(1000, models.GBP), UserID: 5, RestaurantID: 8, PlacedAt: cutoff.AddDate(-1, 0, 0), DeletedAt: cutoff},
+			{Total: models.NewMoney(1000, models.GBP), UserID: 5, RestaurantID: 8, PlacedAt: cutoff.AddDate(1, 0, 0)},
+		}
+		for _, order := range orders {
+			Expect(tx.Insert(order)).To(Succeed())
+		}
+
+		Expect(tx.Insert(&models.Refund{
+			OrderID:   orders[0].ID,
+			Amount:    models.NewMoney(500, models.GBP),
+			Reason:    "Customer said their flat number was wrong",
+			ActorID:   "agent:1",
+			CreatedAt: cutoff,
+		})).To(Succeed())
+
+		event, err = models.NewOrderPlacedEvent(orders[0])
+		Expect(err).To(BeNil())
+		event.NextAttemptAt = cutoff
+		Expect(repositories.NewOutboxRepository(tx).AddEvents(context.Background(), models.OutboxEvents{event})).To(Succeed())
+
+		cancelled := *orders[0]
+		cancelled.Status = models.OrderStatusCancelled
+		cancelled.CancelledBy = models.UserActorID(5)
+		entry, err := models.NewOrderAuditEntry(orders[0], &cancelled, models.OrderAuditActionCancel, models.UserActorID(5), "req-1", cutoff)
+		Expect(err).To(BeNil())
+		auditRepo = repositories.NewOrderAuditRepository(tx)
+		Expect(auditRepo.AddEntry(context.Background(), entry)).To(Succeed())
+	})
+
+	Describe("CountExpiredOrders", func() {
+		It("counts deleted and undeleted orders placed before the cutoff", func() {
+			n, err := retentionRepo.CountExpiredOrders(context.Background(), cutoff, models.RetentionActionDelete)
+			Expect(err).To(BeNil())
+			Expect(n).To(Equal(2))
+		})
+	})
+
+	Describe("AnonymiseOrders", func() {
+		BeforeEach(func() {
+			ids, err := retentionRepo.ClaimExpiredOrderIDs(context.Background(), cutoff, models.RetentionActionAnonymise, 10)
+			Expect(err).To(BeNil())
+			Expect(ids).To(Equal([]int{orders[0].ID, orders[1].ID}))
+
+			Expect(retentionRepo.AnonymiseOrders(context.Background(), ids, now)).To(Succeed())
+		})
+
+		It("removes the user from the orders and their events", func() {
+			found, err := repositories.NewOrderRepository(tx).FindOrderByID(context.Background(), orders[0].ID)
+			Expect(err).To(BeNil())
+			Expect(found.UserID).To(Equal(models.AnonymousUserID))
+			Expect(found.AnonymisedAt).NotTo(BeNil())
+			Expect(found.Version).To(Equal(2))
+
+			stored, err := repositories.NewOutboxRepository(tx).FindEventByID(context.Background(), event.ID)
+			Expect(err).To(BeNil())
+			Expect(stored.UserID).To(Equal(models.AnonymousUserID))
+			payload := map[string]interface{}{}
+			Expect(json.Unmarshal(stored.Payload, &payload)).To(Succeed())
+			Expect(payload).NotTo(HaveKey("user_id"))
+		})
+
+		It("removes the user from the audit log of the orders", func() {
+			entries, err := auditRepo.FindEntriesByOrderID(context.Background(), orders[0].ID)
+			Expect(err).To(BeNil())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].ActorID).To(Equal(models.UserActorID(models.AnonymousUserID)))
+			Expect(entries[0].After).To(MatchJSON(`{"status": "cancelled", "cancelled_by": "user:0"}`))
+		})
+
+		It("clears refund reasons", func() {
+			refunds, err := repositories.NewRefundRepository(tx).FindRefundsByOrderIDs(context.Background(), []int{orders[0].ID})
+			Expect(err).To(BeNil())
+			Expect(refunds[0].Reason).To(BeEmpty())
+		})
+
+		It("does not claim anonymised orders again", func() {
+			ids, err := retentionRepo.ClaimExpiredOrderIDs(context.Background(), cutoff, models.RetentionActionAnonymise, 10)
+			Expect(err).To(BeNil())
+			Expect(ids).To(BeEmpty())
+		})
+	})
+
+	Describe("PurgeOrders", func() {
+		It("deletes the orders and everything recorded about them", func() {
+			Expect(retentionRepo.PurgeOrders(context.Background(), []int{orders[0].ID, orders[1].ID})).To(Succeed())
+
+			n, err := retentionRepo.CountExpiredOrders(context.Background(), cutoff, models.RetentionActionDelete)
+			Expect(err).To(BeNil())
+			Expect(n).To(Equal(0))
+
+			_, err = repositories.NewOutboxRepository(tx).FindEventByID(context.Background(), event.ID)
+			Expect(err).To(Equal(repositories.ErrNotFound))
+
+			entries, err := auditRepo.FindEntriesByOrderID(context.Background(), orders[0].ID)
+			Expect(err).To(BeNil())
+			Expect(entries).To(BeEmpty())
+		})
+	})
+
+	AfterEach(func() {
+		Expect(tx.Rollback()).To(Succeed())
+	})
+})
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound is returned when a requested record does not exist.
+var ErrNotFound = errors.New("record not found")
+
+// ErrVersionConflict is returned when a record was changed by someone else
+// since it was read.
+var ErrVersionConflict = errors.New("record was changed concurrently")
+
+// Transactor runs functions inside a database transaction. Repository
+// methods called with the context passed to fn take part in the transaction.
+type Transactor interface {
+	RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// transactionStarter is satisfied by both *pg.DB and *pg.Tx.
+type transactionStarter interface {
+	RunInTransaction(fn func(*pg.Tx) error) error
+}
+
+// NewTransactor returns a Transactor that starts transactions on db. When db
+// is itself a *pg.Tx, as it is in tests, functions run inside that
+// transaction and it is left to the caller to commit or roll it back.
+func NewTransactor(db transactionStarter) *transactor {
+	return &transactor{
+		db: db,
+	}
+}
+
+// transactor is an implementation of a Transactor.
+type transactor struct {
+	db transactionStarter
+}
+
+func (t *transactor) getDB() transactionStarter {
+	if t.db != nil {
+		return t.db
+	}
+
+	t.db = application.ResolveDB()
+	return t.db
+}
+
+func (t *transactor) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*pg.Tx); ok {
+		return fn(ctx)
+	}
+
+	if tx, ok := t.getDB().(*pg.Tx); ok {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	}
+
+	return t.getDB().RunInTransaction(func(tx *pg.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+type txKey struct{}
+
+// ContextWithTx returns a copy of ctx that carries tx. Repository methods and
+// Transactors called with it run inside tx, which lets tests run whole
+// requests in a transaction that they roll back afterwards.
+func ContextWithTx(ctx context.Context, tx *pg.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// conn returns the transaction carried by ctx, or db when ctx carries none.
+func conn(ctx context.Context, db orm.DB) orm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*pg.Tx); ok {
+		return tx
+	}
+
+	return db
+}
+
+// notFound translates go-pg's missing row error into ErrNotFound.
+func notFound(err error) error {
+	if err == pg.ErrNoRows {
+		return ErrNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+)
+
+// UserErasureRepository is the interface that a repository of erasures of
+// users' personal data should conform to.
+type UserErasureRepository interface {
+	LockOrderIDsByUserID(ctx context.Context, userID int) ([]int, error)
+	CountPersonalData(ctx context.Context, userID int) (map[string]int, error)
+	CreateErasure(ctx context.Context, erasure *models.UserErasure) error
+	FindLatestErasure(ctx context.Context, userID int) (*models.UserErasure, error)
+}
+
+// NewUserErasureRepository returns a new implementation of a user erasure
+// repository.
+func NewUserErasureRepository(db orm.DB) *userErasureRepository {
+	return &userErasureRepository{
+		db: db,
+	}
+}
+
+// userErasureRepository is an implementation of a UserErasureRepository.
+type userErasureRepository struct {
+	db orm.DB
+}
+
+func (r *userErasureRepository) SetDB(db orm.DB) {
+	r.db = db
+}
+
+func (r *userErasureRepository) getDB() orm.DB {
+	if r.db != nil {
+		return r.db
+	}
+
+	r.db = application.ResolveDB()
+	return r.db
+}
+
+// LockOrderIDsByUserID returns the IDs of all of the user's orders, deleted
+// or not, and locks them until the surrounding transaction ends.
+func (r *userErasureRepository) LockOrderIDsByUserID(ctx context.Context, userID int) ([]int, error) {
+	ids := []int{}
+	_, err := conn(ctx, r.getDB()).QueryContext(ctx, &ids, `
+		SELECT id FROM orders WHERE user_id = ? ORDER BY id FOR UPDATE`, userID)
+	return ids, err
+}
+
+// CountPersonalData runs every query in personalDataChecks for the user and
+// returns the number of rows of each table that still identify the user.
+func (r *userErasureRepository) CountPersonalData(ctx context.Context, userID int) (map[string]int, error) {
+	counts := make(map[string]int, len(personalDataChecks))
+	db := conn(ctx, r.getDB())
+	for _, check := range personalDataChecks {
+		var n int
+		if _, err := db.QueryOneContext(ctx, pg.Scan(&n), check.query, userID); err != nil {
+			return nil, err
+		}
+		counts[check.table] = n
+	}
+
+	return counts, nil
+}
+
+func (r *userErasureRepository) CreateErasure(ctx context.Context, erasure *models.UserErasure) error {
+	_, err := conn(ctx, r.getDB()).ModelContext(ctx, erasure).Insert()
+	return err
+}
+
+// FindLatestErasure returns ErrNotFound when the user's data has never been
+// erased.
+func (r *userErasureRepository) FindLatestErasure(ctx context.Context, userID int) (*models.UserErasure, error) {
+	erasure := &models.UserErasure{}
+	err := conn(ctx, r.getDB()).ModelContext(ctx, erasure).
+		Where("user_id = ?", userID).
+		Order("erased_at DESC", "id DESC").
+		Limit(1).
+		Select()
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return erasure, nil
+}
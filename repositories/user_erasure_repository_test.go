@@ -0,0 +1,101 @@
+package repositories_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/go-pg/pg"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UserErasureRepository", func() {
+	var (
+		tx          *pg.Tx
+		erasureRepo repositories.UserErasureRepository
+		orders      models.Orders
+		err         error
+
+		userID = 4242
+		now    = time.Date(2019, 5, 12, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		tx, err = application.ResolveDB().Begin()
+		Expect(err).To(BeNil())
+		erasureRepo = repositories.NewUserErasureRepository(tx)
+
+		orders = models.Orders{
+			{Total: models.NewMoney(1000, models.GBP), UserID: userID, RestaurantID: 8, PlacedAt: now},
+			{Total: models.NewMoney(1000, models.GBP), UserID: userID, RestaurantID: 8, PlacedAt: now, DeletedAt: now},
+		}
+		for _, order := range orders {
+			Expect(tx.Insert(order)).To(Succeed())
+		}
+
+		event, err := models.NewOrderPlacedEvent(orders[0])
+		Expect(err).To(BeNil())
+		event.NextAttemptAt = now
+		Expect(repositories.NewOutboxRepository(tx).AddEvents(context.Background(), models.OutboxEvents{event})).To(Succeed())
+
+		cancelled := *orders[0]
+		cancelled.Status = models.OrderStatusCancelled
+		cancelled.CancelledBy = models.UserActorID(userID)
+		entry, err := models.NewOrderAuditEntry(orders[0], &cancelled, models.OrderAuditActionCancel, models.UserActorID(userID), "req-1", now)
+		Expect(err).To(BeNil())
+		Expect(repositories.NewOrderAuditRepository(tx).AddEntry(context.Background(), entry)).To(Succeed())
+
+		Expect(tx.Insert(&models.IdempotencyKey{
+			Scope:               models.UserActorID(userID),
+			Key:                 "cancel-1",
+			Fingerprint:         "fingerprint",
+			LockedUntil:         now,
+			ResponseStatus:      200,
+			ResponseContentType: "application/json",
+			ResponseBody:        []byte(`{"id":1,"user_id":4242}`),
+			CreatedAt:           now,
+			CompletedAt:         &now,
+			ExpiresAt:           now.Add(24 * time.Hour),
+		})).To(Succeed())
+	})
+
+	It("finds the user's orders, deleted or not", func() {
+		ids, err := erasureRepo.LockOrderIDsByUserID(context.Background(), userID)
+		Expect(err).To(BeNil())
+		Expect(ids).To(Equal(orders.IDs()))
+	})
+
+	It("counts the rows that identify the user until their orders are anonymised", func() {
+		remaining, err := erasureRepo.CountPersonalData(context.Background(), userID)
+		Expect(err).To(BeNil())
+		Expect(remaining["orders"]).To(Equal(2))
+		Expect(remaining["outbox_events"]).To(Equal(1))
+		Expect(remaining["order_audit"]).To(Equal(1))
+		Expect(remaining["idempotency_keys"]).To(Equal(1))
+
+		Expect(repositories.NewRetentionRepository(tx).AnonymiseOrders(context.Background(), orders.IDs(), now)).To(Succeed())
+
+		remaining, err = erasureRepo.CountPersonalData(context.Background(), userID)
+		Expect(err).To(BeNil())
+		Expect(remaining).To(Equal(map[string]int{"orders": 0, "outbox_events": 0, "order_audit": 0, "webhook_deliveries": 0, "idempotency_keys": 0}))
+	})
+
+	It("finds the latest erasure", func() {
+		_, err = erasureRepo.FindLatestErasure(context.Background(), userID)
+		Expect(err).To(Equal(repositories.ErrNotFound))
+
+		Expect(erasureRepo.CreateErasure(context.Background(), &models.UserErasure{UserID: userID, ActorID: "agent:1", ErasedAt: now.Add(-time.Hour)})).To(Succeed())
+		Expect(erasureRepo.CreateErasure(context.Background(), &models.UserErasure{UserID: userID, ActorID: "agent:2", OrdersErased: 2, ErasedAt: now})).To(Succeed())
+
+		erasure, err := erasureRepo.FindLatestErasure(context.Background(), userID)
+		Expect(err).To(BeNil())
+		Expect(erasure.ActorID).To(Equal("agent:2"))
+	})
+
+	AfterEach(func() {
+		Expect(tx.Rollback()).To(Succeed())
+	})
+})
@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+)
+
+// WebhookRepository is the interface that a webhook subscription and delivery
+// repository should conform to.
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error
+	FindSubscriptionByID(ctx context.Context, id int) (*models.WebhookSubscription, error)
+	FindSubscriptionsByIDs(ctx context.Context, ids []int) (models.WebhookSubscriptions, error)
+	FindSubscriptionsByRestaurantID(ctx context.Context, restaurantID int) (models.WebhookSubscriptions, error)
+	UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id int) error
+
+	AddDeliveries(ctx context.Context, deliveries models.WebhookDeliveries) error
+	FindDeliveryByID(ctx context.Context, id int64) (*models.WebhookDelivery, error)
+	FindDeliveriesBySubscriptionID(ctx context.Context, subscriptionID, limit int) (models.WebhookDeliveries, error)
+	FindDeliveriesForUser(ctx context.Context, userID int) (models.WebhookDeliveries, error)
+	ClaimDueDeliveries(ctx context.Context, limit int, now, leaseUntil time.Time) (models.WebhookDeliveries, error)
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+// NewWebhookRepository returns a new implementation of a webhook repository.
+func NewWebhookRepository(db orm.DB) *webhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+// webhookRepository is an implementation of a WebhookRepository.
+type webhookRepository struct {
+	db orm.DB
+}
+
+func (r *webhookRepository) SetDB(db orm.DB) {
+	r.db = db
+}
+
+func (r *webhookRepository) getDB() orm.DB {
+	if r.db != nil {
+		return r.db
+	}
+
+	r.db = application.ResolveDB()
+	return r.db
+}
+
+func (r *webhookRepository) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	_, err := conn(ctx, r.getDB()).ModelContext(ctx, subscription).Insert()
+	return err
+}
+
+// FindSubscriptionByID returns ErrNotFound when the subscription does not
+// exist.
+func (r *webhookRepository) FindSubscriptionByID(ctx context.Context, id int) (*models.WebhookSubscription, error) {
+	subscription := &models.WebhookSubscription{}
+	err := conn(ctx, r.getDB()).ModelContext(ctx, subscription).Where("id = ?", id).Select()
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return subscription, nil
+}
+
+func (r *webhookRepository) FindSubscriptionsByIDs(ctx context.Context, ids []int) (models.WebhookSubscriptions, error) {
+	subscriptions := models.WebhookSubscriptions{}
+	if len(ids) == 0 {
+		return subscriptions, nil
+	}
+
+	err := conn(ctx, r.getDB()).ModelContext(ctx, &subscriptions).Where("id IN (?)", pg.In(ids)).Select()
+	return subscriptions, err
+}
+
+func (r *webhookRepository) FindSubscriptionsByRestaurantID(ctx context.Context, restaurantID int) (models.WebhookSubscriptions, error) {
+	subscriptions := models.WebhookSubscriptions{}
+	err := conn(ctx, r.getDB()).ModelContext(ctx, &subscriptions).
+		Where("restaurant_id = ?", restaurantID).
+		Order("id ASC").
+		Select()
+	return subscriptions, err
+}
+
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	_, err := conn(ctx, r.getDB()).ModelContext(ctx, subscription).
+		Column("url", "event_types", "secret", "updated_at").
+		WherePK().
+		Update()
+	return err
+}
+
+// DeleteSubscription deletes the subscription and its delivery log.
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id int) error {
+	_, err := conn(ctx, r.getDB()).ModelContext(ctx, (*models.WebhookSubscription)(nil)).Where("id = ?", id).Delete()
+	return err
+}
+
+// AddDeliveries stores deliveries. A delivery of an event that is already in
+// its subscription's log is skipped and left without an ID, so that an event
+// that is published more than once is only queued once. Replays are always
+// stored.
+func (r *webhookRepository) AddDeliveries(ctx context.Context, deliveries models.WebhookDeliveries) error {
+	db := conn(ctx, r.getDB())
+	for _, delivery := range deliveries {
+		_, err := db.ModelContext(ctx, delivery).
+			OnConflict("(subscription_id, event_id) WHERE replay_of IS NULL DO NOTHING").
+			Insert()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FindDeliveryByID returns ErrNotFound when the delivery does not exist.
+func (r *webhookRepository) FindDeliveryByID(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	err := conn(ctx, r.getDB()).ModelContext(ctx, delivery).Where("id = ?", id).Select()
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return delivery, nil
+}
+
+// FindDeliveriesBySubscriptionID returns up to limit of the subscription's
+// deliveries, newest first.
+func (r *webhookRepository) FindDeliveriesBySubscriptionID(ctx context.Context, subscriptionID, limit int) (models.WebhookDeliveries, error) {
+	deliveries := models.WebhookDeliveries{}
+	err := conn(ctx, r.getDB()).ModelContext(ctx, &deliveries).
+		Where("subscription_id = ?", subscriptionID).
+		Order("id DESC").
+		Limit(limit).
+		Select()
+	return deliveries, err
+}
+
+// FindDeliveriesForUser returns every delivery of an event about one of the
+// user's orders, oldest first.
+func (r *webhookRepository) FindDeliveriesForUser(ctx context.Context, userID int) (models.WebhookDeliveries, error) {
+	deliveries := models.WebhookDeliveries{}
+	err := conn(ctx, r.getDB()).ModelContext(ctx, &deliveries).
+		Where("event_id IN (SELECT id FROM outbox_events WHERE user_id = ?)", userID).
+		Order("id ASC").
+		Select()
+	return deliveries, err
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries that are due to be
+// attempted, oldest first, and leases them by moving their next attempt to
+// leaseUntil, so that they are not claimed again while they are sent.
+// Deliveries that are locked by another dispatcher are skipped. It should be
+// called in a transaction that is committed before the deliveries are sent.
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, now, leaseUntil time.Time) (models.WebhookDeliveries, error) {
+	db := conn(ctx, r.getDB())
+	deliveries := models.WebhookDeliveries{}
+	err := db.ModelContext(ctx, &deliveries).
+		Where("status = ?", models.WebhookDeliveryStatusPending).
+		Where("next_attempt_at <= ?", now).
+		Order("id ASC").
+		Limit(limit).
+		For("UPDATE SKIP LOCKED").
+		Select()
+	if err != nil || len(deliveries) == 0 {
+		return deliveries, err
+	}
+
+	ids := make([]int64, len(deliveries))
+	for i, delivery := range deliveries {
+		delivery.NextAttemptAt = leaseUntil
+		ids[i] = delivery.ID
+	}
+
+	_, err = db.ModelContext(ctx, (*models.WebhookDelivery)(nil)).
+		Set("next_attempt_at = ?", leaseUntil).
+		Where("id IN (?)", pg.In(ids)).
+		Update()
+	return deliveries, err
+}
+
+// UpdateDelivery saves the outcome of the latest attempt at delivery.
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	_, err := conn(ctx, r.getDB()).ModelContext(ctx, delivery).
+		Column("status", "attempts", "response_status", "last_error", "next_attempt_at", "last_attempt_at", "delivered_at").
+		WherePK().
+		Update()
+	return err
+}
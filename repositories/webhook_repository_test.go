@@ -0,0 +1,141 @@
+package repositories_test
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/go-pg/pg"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WebhookRepository", func() {
+	var (
+		tx           *pg.Tx
+		webhookRepo  repositories.WebhookRepository
+		subscription *models.WebhookSubscription
+		events       models.OutboxEvents
+		deliveries   models.WebhookDeliveries
+		err          error
+
+		now = time.Date(2019, 5, 6, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		tx, err = application.ResolveDB().Begin()
+		Expect(err).To(BeNil())
+		webhookRepo = repositories.NewWebhookRepository(tx)
+
+		subscription = &models.WebhookSubscription{
+			RestaurantID: 9,
+			URL:          "https://example.com/hooks",
+			EventTypes:   []models.EventType{models.EventTypeOrderRefunded},
+			Secret:       "0123456789abcdef",
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		Expect(webhookRepo.CreateSubscription(context.Background(), subscription)).To(Succeed())
+
+		order := &models.Order{Total: models.NewMoney(1000, models.GBP), UserID: 5, RestaurantID: 9, PlacedAt: now}
+		Expect(tx.Insert(order)).To(Succeed())
+		events = models.OutboxEvents{}
+		for i := 0; i < 2; i++ {
+			event, err := models.NewOrderPlacedEvent(order)
+			Expect(err).To(BeNil())
+			events = append(events, event)
+		}
+		Expect(repositories.NewOutboxRepository(tx).AddEvents(context.Background(), events)).To(Succeed())
+
+		deliveries = models.WebhookDeliveries{}
+		for i, due := range []time.Time{now.Add(-time.Minute), now.Add(time.Minute)} {
+			delivery, err := models.NewWebhookDelivery(subscription, events[i], due)
+			Expect(err).To(BeNil())
+			delivery.Body = json.RawMessage(`{}`)
+			deliveries = append(deliveries, delivery)
+		}
+		Expect(webhookRepo.AddDeliveries(context.Background(), deliveries)).To(Succeed())
+	})
+
+	It("finds a restaurant's subscriptions with their event filters", func() {
+		found, err := webhookRepo.FindSubscriptionsByRestaurantID(context.Background(), 9)
+		Expect(err).To(BeNil())
+		Expect(len(found)).To(Equal(1))
+		Expect(found[0].EventTypes).To(Equal([]models.EventType{models.EventTypeOrderRefunded}))
+		Expect(found[0].Secret).To(Equal("0123456789abcdef"))
+	})
+
+	It("queues an event only once for each subscription", func() {
+		again, err := models.NewWebhookDelivery(subscription, events[0], now)
+		Expect(err).To(BeNil())
+		again.Body = json.RawMessage(`{}`)
+		Expect(webhookRepo.AddDeliveries(context.Background(), models.WebhookDeliveries{again})).To(Succeed())
+		Expect(again.ID).To(BeZero())
+
+		replay := deliveries[0].Replay(now)
+		Expect(webhookRepo.AddDeliveries(context.Background(), models.WebhookDeliveries{replay})).To(Succeed())
+		Expect(replay.ID).NotTo(BeZero())
+
+		log, err := webhookRepo.FindDeliveriesBySubscriptionID(context.Background(), subscription.ID, 10)
+		Expect(err).To(BeNil())
+		Expect(len(log)).To(Equal(3))
+	})
+
+	It("claims only the deliveries that are due", func() {
+		due, err := webhookRepo.ClaimDueDeliveries(context.Background(), 10, now, now.Add(time.Minute))
+		Expect(err).To(BeNil())
+		Expect(len(due)).To(Equal(1))
+		Expect(due[0].ID).To(Equal(deliveries[0].ID))
+	})
+
+	It("does not claim leased deliveries again until the lease runs out", func() {
+		_, err := webhookRepo.ClaimDueDeliveries(context.Background(), 10, now, now.Add(30*time.Second))
+		Expect(err).To(BeNil())
+
+		due, err := webhookRepo.ClaimDueDeliveries(context.Background(), 10, now, now.Add(30*time.Second))
+		Expect(err).To(BeNil())
+		Expect(due).To(BeEmpty())
+
+		due, err = webhookRepo.ClaimDueDeliveries(context.Background(), 10, now.Add(30*time.Second), now.Add(time.Minute))
+		Expect(err).To(BeNil())
+		Expect(len(due)).To(Equal(1))
+		Expect(due[0].ID).To(Equal(deliveries[0].ID))
+	})
+
+	It("keeps the log of each delivery, newest first", func() {
+		deliveries[0].MarkSucceeded(200, now)
+		Expect(webhookRepo.UpdateDelivery(context.Background(), deliveries[0])).To(Succeed())
+
+		log, err := webhookRepo.FindDeliveriesBySubscriptionID(context.Background(), subscription.ID, 10)
+		Expect(err).To(BeNil())
+		Expect(len(log)).To(Equal(2))
+		Expect(log[1].Status).To(Equal(models.WebhookDeliveryStatusSucceeded))
+		Expect(log[1].ResponseStatus).To(Equal(200))
+	})
+
+	It("finds the deliveries of events about a user's orders", func() {
+		found, err := webhookRepo.FindDeliveriesForUser(context.Background(), 5)
+		Expect(err).To(BeNil())
+		Expect(len(found)).To(Equal(2))
+		Expect(found[0].ID).To(Equal(deliveries[0].ID))
+
+		found, err = webhookRepo.FindDeliveriesForUser(context.Background(), 6)
+		Expect(err).To(BeNil())
+		Expect(found).To(BeEmpty())
+	})
+
+	It("deletes the delivery log with the subscription", func() {
+		Expect(webhookRepo.DeleteSubscription(context.Background(), subscription.ID)).To(Succeed())
+
+		_, err := webhookRepo.FindDeliveryByID(context.Background(), deliveries[0].ID)
+		Expect(err).To(Equal(repositories.ErrNotFound))
+	})
+
+	AfterEach(func() {
+		err = tx.Rollback()
+		Expect(err).To(BeNil())
+	})
+})
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/clients/restaurant"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	"github.com/pkg/errors"
+)
+
+// Enricher loads additional data onto a page of orders. Enrichers in the same
+// pipeline run concurrently, so each one must only write to fields of the
+// orders that no other enricher touches.
+type Enricher interface {
+	Enrich(ctx context.Context, orders models.Orders) error
+}
+
+// EnricherFunc adapts an ordinary function to the Enricher interface.
+type EnricherFunc func(ctx context.Context, orders models.Orders) error
+
+// Enrich calls f(ctx, orders).
+func (f EnricherFunc) Enrich(ctx context.Context, orders models.Orders) error {
+	return f(ctx, orders)
+}
+
+// EnrichmentPipeline runs independent Enrichers concurrently.
+type EnrichmentPipeline struct {
+	concurrency int
+	stages      []enrichmentStage
+}
+
+type enrichmentStage struct {
+	name     string
+	enricher Enricher
+}
+
+// NewEnrichmentPipeline creates a pipeline that runs at most concurrency
+// enrichers at the same time. A concurrency of 1 runs them sequentially.
+func NewEnrichmentPipeline(concurrency int) *EnrichmentPipeline {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &EnrichmentPipeline{concurrency: concurrency}
+}
+
+// Add registers an Enricher with the pipeline. The name is used for the span
+// that is recorded when the enricher runs.
+func (p *EnrichmentPipeline) Add(name string, e Enricher) *EnrichmentPipeline {
+	p.stages = append(p.stages, enrichmentStage{name: name, enricher: e})
+	return p
+}
+
+// Run runs every enricher against orders. The enrichers share a context that
+// is cancelled as soon as one of them fails, and the first failure is
+// returned.
+func (p *EnrichmentPipeline) Run(ctx context.Context, orders models.Orders) error {
+	if len(orders) == 0 || len(p.stages) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		slots    = make(chan struct{}, p.concurrency)
+	)
+
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	for _, stage := range p.stages {
+		wg.Add(1)
+		go func(stage enrichmentStage) {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				fail(ctx.Err())
+				return
+			}
+
+			// A sibling may have failed while this stage waited for a slot.
+			if err := ctx.Err(); err != nil {
+				fail(err)
+				return
+			}
+
+			stageCtx, span := tracing.StartSpan(ctx, "Enricher."+stage.name)
+			defer span.End()
+
+			if err := stage.enricher.Enrich(stageCtx, orders); err != nil {
+				span.SetError(err)
+				fail(err)
+			}
+		}(stage)
+	}
+
+	wg.Wait()
+	return firstErr
+}
+
+// restaurantEnricher sets Order.Restaurant from the RestaurantService. Unless
+// allowMissing is set, an order whose restaurant no longer exists is an error.
+type restaurantEnricher struct {
+	client       restaurant.Client
+	allowMissing bool
+}
+
+func (e *restaurantEnricher) Enrich(ctx context.Context, orders models.Orders) error {
+	restaurantIDs := make([]int, 0, len(orders))
+	for _, order := range orders {
+		restaurantIDs = append(restaurantIDs, order.RestaurantID)
+	}
+
+	restaurants, err := e.client.GetRestaurantsByIDs(ctx, restaurantIDs)
+	if err != nil {
+		return err
+	}
+
+	restaurantsByID := make(map[int]*models.Restaurant)
+	for _, restaurant := range restaurants {
+		restaurantsByID[restaurant.ID] = restaurant
+	}
+
+	for _, order := range orders {
+		restaurant, ok := restaurantsByID[order.RestaurantID]
+		if !ok && e.allowMissing {
+			continue
+		}
+		if !ok {
+			return errors.Errorf("restaurant with ID %d not found", order.RestaurantID)
+		}
+
+		order.Restaurant = restaurant
+	}
+
+	return nil
+}
+
+// itemsEnricher sets Order.Items.
+type itemsEnricher struct {
+	repository repositories.OrderItemRepository
+}
+
+func (e *itemsEnricher) Enrich(ctx context.Context, orders models.Orders) error {
+	items, err := e.repository.FindItemsByOrderIDs(ctx, orders.IDs())
+	if err != nil {
+		return err
+	}
+
+	itemsByOrderID := make(map[int]models.OrderItems)
+	for _, item := range items {
+		itemsByOrderID[item.OrderID] = append(itemsByOrderID[item.OrderID], item)
+	}
+
+	for _, order := range orders {
+		order.Items = itemsByOrderID[order.ID]
+		if order.Items == nil {
+			order.Items = models.OrderItems{}
+		}
+	}
+
+	return nil
+}
+
+// statusHistoryEnricher sets Order.StatusHistory.
+type statusHistoryEnricher struct {
+	repository repositories.OrderStatusChangeRepository
+}
+
+func (e *statusHistoryEnricher) Enrich(ctx context.Context, orders models.Orders) error {
+	changes, err := e.repository.FindStatusChangesByOrderIDs(ctx, orders.IDs())
+	if err != nil {
+		return err
+	}
+
+	changesByOrderID := make(map[int]models.OrderStatusChanges)
+	for _, change := range changes {
+		changesByOrderID[change.OrderID] = append(changesByOrderID[change.OrderID], change)
+	}
+
+	for _, order := range orders {
+		order.StatusHistory = changesByOrderID[order.ID]
+		if order.StatusHistory == nil {
+			order.StatusHistory = models.OrderStatusChanges{}
+		}
+	}
+
+	return nil
+}
+
+// refundsEnricher sets Order.Refunds and Order.NetTotal.
+type refundsEnricher struct {
+	repository repositories.RefundRepository
+}
+
+func (e *refundsEnricher) Enrich(ctx context.Context, orders models.Orders) error {
+	refunds, err := e.repository.FindRefundsByOrderIDs(ctx, orders.IDs())
+	if err != nil {
+		return err
+	}
+
+	refundsByOrderID := make(map[int]models.Refunds)
+	for _, refund := range refunds {
+		refundsByOrderID[refund.OrderID] = append(refundsByOrderID[refund.OrderID], refund)
+	}
+
+	for _, order := range orders {
+		orderRefunds := refundsByOrderID[order.ID]
+		if orderRefunds == nil {
+			orderRefunds = models.Refunds{}
+		}
+		if err = order.SetRefunds(orderRefunds); err != nil {
+			return errors.Wrapf(err, "refunds of order %d", order.ID)
+		}
+	}
+
+	return nil
+}
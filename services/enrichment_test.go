@@ -0,0 +1,122 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// sleepingEnricher simulates an enricher that waits on a remote dependency.
+func sleepingEnricher(d time.Duration) services.Enricher {
+	return services.EnricherFunc(func(ctx context.Context, orders models.Orders) error {
+		select {
+		case <-time.After(d):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+var _ = Describe("EnrichmentPipeline", func() {
+	var orders models.Orders
+
+	BeforeEach(func() {
+		orders = models.Orders{{ID: 1}, {ID: 2}}
+	})
+
+	It("runs every enricher", func() {
+		var calls int32
+		count := services.EnricherFunc(func(ctx context.Context, orders models.Orders) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		})
+
+		err := services.NewEnrichmentPipeline(2).
+			Add("a", count).
+			Add("b", count).
+			Add("c", count).
+			Run(context.Background(), orders)
+		Expect(err).To(BeNil())
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(3)))
+	})
+
+	It("never runs more enrichers at once than its concurrency", func() {
+		var running, peak int32
+		track := services.EnricherFunc(func(ctx context.Context, orders models.Orders) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+
+		pipeline := services.NewEnrichmentPipeline(2)
+		for i := 0; i < 6; i++ {
+			pipeline.Add("track", track)
+		}
+		Expect(pipeline.Run(context.Background(), orders)).To(Succeed())
+		Expect(atomic.LoadInt32(&peak)).To(Equal(int32(2)))
+	})
+
+	It("cancels the remaining enrichers and returns the first error", func() {
+		started := time.Now()
+		err := services.NewEnrichmentPipeline(3).
+			Add("slow", sleepingEnricher(time.Second)).
+			Add("failing", services.EnricherFunc(func(ctx context.Context, orders models.Orders) error {
+				return errors.New("items unavailable")
+			})).
+			Run(context.Background(), orders)
+		Expect(err).To(MatchError("items unavailable"))
+		Expect(time.Since(started)).To(BeNumerically("<", 500*time.Millisecond))
+	})
+
+	It("skips enrichment when there are no orders", func() {
+		err := services.NewEnrichmentPipeline(1).
+			Add("failing", services.EnricherFunc(func(ctx context.Context, orders models.Orders) error {
+				return errors.New("should not be called")
+			})).
+			Run(context.Background(), models.Orders{})
+		Expect(err).To(BeNil())
+	})
+})
+
+// The benchmarks below model a page of orders whose restaurant lookup takes
+// 3ms while the items and status history queries take 2ms each. Run them with
+// `go test -bench Enrichment ./services` to compare the sequential and
+// concurrent latency.
+
+func benchmarkEnrichment(b *testing.B, concurrency int) {
+	pipeline := services.NewEnrichmentPipeline(concurrency).
+		Add("restaurants", sleepingEnricher(3*time.Millisecond)).
+		Add("items", sleepingEnricher(2*time.Millisecond)).
+		Add("status_history", sleepingEnricher(2*time.Millisecond))
+	orders := models.Orders{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := pipeline.Run(context.Background(), orders); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEnrichmentSequential(b *testing.B) {
+	benchmarkEnrichment(b, 1)
+}
+
+func BenchmarkEnrichmentConcurrent(b *testing.B) {
+	benchmarkEnrichment(b, 3)
+}
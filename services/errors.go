@@ -0,0 +1,62 @@
+package services
+
+import "fmt"
+
+// ErrorKind classifies an Error so that callers can decide how to report it.
+type ErrorKind int
+
+// The kinds of Error that services return.
+const (
+	// ErrorKindInvalid means the request itself is malformed.
+	ErrorKindInvalid ErrorKind = iota
+	// ErrorKindNotFound means a record that the request refers to does not
+	// exist.
+	ErrorKindNotFound
+	// ErrorKindForbidden means the actor may not perform the request.
+	ErrorKindForbidden
+	// ErrorKindConflict means the request is valid but breaks a business rule
+	// given the current state of the records it refers to.
+	ErrorKindConflict
+	// ErrorKindPreconditionFailed means the record has changed since the
+	// version that the request was based on.
+	ErrorKindPreconditionFailed
+)
+
+// Error is a business rule violation. Its Code, Message and Details are safe
+// to return to API clients.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Details map[string]interface{}
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(kind ErrorKind, code string, format string, args ...interface{}) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// withDetail adds a detail to the error and returns it.
+func (e *Error) withDetail(key string, value interface{}) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[key] = value
+	return e
+}
+
+func orderNotFound(id int) *Error {
+	return newError(ErrorKindNotFound, "order_not_found", "order with ID %d not found", id)
+}
+
+func orderVersionMismatch(id int) *Error {
+	return newError(ErrorKindPreconditionFailed, "order_version_mismatch", "order %d has changed since it was read", id)
+}
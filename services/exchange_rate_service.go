@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	"github.com/pkg/errors"
+)
+
+// DatedAmount is an amount of money together with the time at which it
+// should be converted.
+type DatedAmount struct {
+	Amount models.Money
+	At     time.Time
+}
+
+// ExchangeRateService represents the business-logic layer for exchange rates
+// and currency conversion.
+type ExchangeRateService interface {
+	LoadRates(ctx context.Context, rates models.ExchangeRates) error
+	ConvertAll(ctx context.Context, amounts []DatedAmount, to models.Currency) ([]models.Money, error)
+}
+
+// NewExchangeRateService creates an exchange rate service.
+func NewExchangeRateService() *exchangeRateService {
+	return &exchangeRateService{}
+}
+
+type exchangeRateService struct {
+	exchangeRateRepository repositories.ExchangeRateRepository
+}
+
+func (s *exchangeRateService) SetExchangeRateRepository(r repositories.ExchangeRateRepository) {
+	s.exchangeRateRepository = r
+}
+
+func (s *exchangeRateService) getExchangeRateRepository() repositories.ExchangeRateRepository {
+	if s.exchangeRateRepository != nil {
+		return s.exchangeRateRepository
+	}
+
+	s.exchangeRateRepository = repositories.NewExchangeRateRepository(application.ResolveDB())
+	return s.exchangeRateRepository
+}
+
+// LoadRates validates and stores rates. Nothing is stored if any rate is
+// invalid.
+func (s *exchangeRateService) LoadRates(ctx context.Context, rates models.ExchangeRates) error {
+	for i, rate := range rates {
+		if err := rate.Validate(); err != nil {
+			return errors.Wrapf(err, "rate %d", i+1)
+		}
+	}
+
+	return s.getExchangeRateRepository().UpsertRates(ctx, rates)
+}
+
+// ConvertAll converts each amount to the target currency at the rate that was
+// in effect at its time. A rate stored in the opposite direction is inverted
+// when no direct rate is available.
+func (s *exchangeRateService) ConvertAll(ctx context.Context, amounts []DatedAmount, to models.Currency) ([]models.Money, error) {
+	ctx, span := tracing.StartSpan(ctx, "ExchangeRateService.ConvertAll")
+	defer span.End()
+
+	seen := make(map[models.Currency]bool)
+	currencies := []models.Currency{}
+	for _, amount := range amounts {
+		if c := amount.Amount.Currency; c != to && !seen[c] {
+			seen[c] = true
+			currencies = append(currencies, c)
+		}
+	}
+
+	rates, err := s.getExchangeRateRepository().FindRatesBetween(ctx, currencies, to)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	timelines, err := newRateTimelines(rates, to)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	converted := make([]models.Money, 0, len(amounts))
+	for _, amount := range amounts {
+		if amount.Amount.Currency == to {
+			converted = append(converted, amount.Amount)
+			continue
+		}
+
+		rate := timelines[amount.Amount.Currency].rateAt(amount.At)
+		if rate == nil {
+			err = newError(ErrorKindConflict, "exchange_rate_unavailable", "no exchange rate from %s to %s at %s", amount.Amount.Currency, to, amount.At.Format(time.RFC3339)).
+				withDetail("from", amount.Amount.Currency).
+				withDetail("to", to).
+				withDetail("at", amount.At)
+			span.SetError(err)
+			return nil, err
+		}
+
+		converted = append(converted, amount.Amount.Convert(rate, to))
+	}
+
+	return converted, nil
+}
+
+// rateTimeline is the history of the rate from one currency to the target
+// currency, oldest first.
+type rateTimeline []timedRate
+
+type timedRate struct {
+	effectiveAt time.Time
+	rate        *big.Rat
+	direct      bool
+}
+
+// rateAt returns the rate in effect at t, or nil if the first rate took
+// effect after t.
+func (tl rateTimeline) rateAt(t time.Time) *big.Rat {
+	i := sort.Search(len(tl), func(i int) bool {
+		return tl[i].effectiveAt.After(t)
+	})
+	if i == 0 {
+		return nil
+	}
+
+	return tl[i-1].rate
+}
+
+// newRateTimelines builds the timeline of rates to target for every currency
+// that appears in rates. When a direct and an inverted rate take effect at
+// the same time the direct rate wins.
+func newRateTimelines(rates models.ExchangeRates, target models.Currency) (map[models.Currency]rateTimeline, error) {
+	timelines := make(map[models.Currency]rateTimeline)
+	for _, rate := range rates {
+		rat, err := rate.Rat()
+		if err != nil {
+			return nil, err
+		}
+
+		from, direct := rate.BaseCurrency, true
+		if rate.BaseCurrency == target {
+			from, direct = rate.QuoteCurrency, false
+			rat = new(big.Rat).Inv(rat)
+		}
+
+		timelines[from] = append(timelines[from], timedRate{
+			effectiveAt: rate.EffectiveAt,
+			rate:        rat,
+			direct:      direct,
+		})
+	}
+
+	for _, tl := range timelines {
+		sort.SliceStable(tl, func(i, j int) bool {
+			if tl[i].effectiveAt.Equal(tl[j].effectiveAt) {
+				return !tl[i].direct && tl[j].direct
+			}
+			return tl[i].effectiveAt.Before(tl[j].effectiveAt)
+		})
+	}
+
+	return timelines, nil
+}
@@ -0,0 +1,93 @@
+package services_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExchangeRateService", func() {
+	var (
+		ctrl                *gomock.Controller
+		exchangeRateRepo    *mock_repositories.MockExchangeRateRepository
+		exchangeRateService services.ExchangeRateService
+
+		march = time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+		april = time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		exchangeRateRepo = mock_repositories.NewMockExchangeRateRepository(ctrl)
+
+		exchangeRateServiceImpl := services.NewExchangeRateService()
+		exchangeRateServiceImpl.SetExchangeRateRepository(exchangeRateRepo)
+		exchangeRateService = exchangeRateServiceImpl
+	})
+
+	Describe("ConvertAll", func() {
+		BeforeEach(func() {
+			exchangeRateRepo.EXPECT().
+				FindRatesBetween(gomock.Any(), gomock.Eq([]models.Currency{models.GBP, models.USD}), gomock.Eq(models.EUR)).
+				Return(models.ExchangeRates{
+					{BaseCurrency: models.GBP, QuoteCurrency: models.EUR, Rate: "1.10", EffectiveAt: march},
+					{BaseCurrency: models.EUR, QuoteCurrency: models.USD, Rate: "1.25", EffectiveAt: march},
+					{BaseCurrency: models.GBP, QuoteCurrency: models.EUR, Rate: "1.20", EffectiveAt: april},
+				}, error(nil))
+		})
+
+		It("converts each amount at the rate in effect at its time", func() {
+			converted, err := exchangeRateService.ConvertAll(context.Background(), []services.DatedAmount{
+				{Amount: models.NewMoney(1000, models.GBP), At: march.Add(time.Hour)},
+				{Amount: models.NewMoney(1000, models.GBP), At: april},
+				{Amount: models.NewMoney(1000, models.USD), At: april},
+				{Amount: models.NewMoney(1000, models.EUR), At: march},
+			}, models.EUR)
+			Expect(err).To(BeNil())
+			Expect(converted).To(Equal([]models.Money{
+				models.NewMoney(1100, models.EUR),
+				models.NewMoney(1200, models.EUR),
+				models.NewMoney(800, models.EUR),
+				models.NewMoney(1000, models.EUR),
+			}))
+		})
+
+		It("fails when no rate was in effect yet", func() {
+			_, err := exchangeRateService.ConvertAll(context.Background(), []services.DatedAmount{
+				{Amount: models.NewMoney(1000, models.GBP), At: march.Add(-time.Hour)},
+				{Amount: models.NewMoney(1000, models.USD), At: april},
+			}, models.EUR)
+			Expect(err.(*services.Error).Code).To(Equal("exchange_rate_unavailable"))
+		})
+	})
+
+	Describe("LoadRates", func() {
+		It("rejects invalid rates without storing any", func() {
+			err := exchangeRateService.LoadRates(context.Background(), models.ExchangeRates{
+				{BaseCurrency: models.GBP, QuoteCurrency: models.EUR, Rate: "1.10", EffectiveAt: march},
+				{BaseCurrency: models.GBP, QuoteCurrency: models.EUR, Rate: "-1", EffectiveAt: april},
+			})
+			Expect(err).To(MatchError("rate 2: rate from GBP to EUR must be positive"))
+		})
+
+		It("stores valid rates", func() {
+			rates := models.ExchangeRates{
+				{BaseCurrency: models.GBP, QuoteCurrency: models.EUR, Rate: "1.10", EffectiveAt: march},
+			}
+			exchangeRateRepo.EXPECT().UpsertRates(gomock.Any(), gomock.Eq(rates)).Return(error(nil))
+
+			Expect(exchangeRateService.LoadRates(context.Background(), rates)).To(Succeed())
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
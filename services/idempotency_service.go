@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+)
+
+// defaultIdempotencyKeyTTL is how long a response is replayed for repeats of
+// a request, unless IDEMPOTENCY_KEY_TTL says otherwise.
+const defaultIdempotencyKeyTTL = 24 * time.Hour
+
+// idempotencyLockTimeout is how long a key stays locked by a request that has
+// not completed. A request that takes longer than this, or whose process died,
+// can be retried with the same key.
+const idempotencyLockTimeout = time.Minute
+
+// IdempotencyService represents the business-logic layer for idempotency
+// keys.
+type IdempotencyService interface {
+	Begin(ctx context.Context, scope, key, fingerprint string) (*models.IdempotencyKey, error)
+	Complete(ctx context.Context, key *models.IdempotencyKey, status int, contentType string, body []byte) error
+	Release(ctx context.Context, key *models.IdempotencyKey) error
+	PurgeExpired(ctx context.Context) (int, error)
+}
+
+// NewIdempotencyService creates an idempotency service.
+func NewIdempotencyService() *idempotencyService {
+	return &idempotencyService{}
+}
+
+type idempotencyService struct {
+	idempotencyKeyRepository repositories.IdempotencyKeyRepository
+	ttl                      time.Duration
+	now                      func() time.Time
+}
+
+func (s *idempotencyService) SetIdempotencyKeyRepository(r repositories.IdempotencyKeyRepository) {
+	s.idempotencyKeyRepository = r
+}
+
+func (s *idempotencyService) getIdempotencyKeyRepository() repositories.IdempotencyKeyRepository {
+	if s.idempotencyKeyRepository != nil {
+		return s.idempotencyKeyRepository
+	}
+
+	s.idempotencyKeyRepository = repositories.NewIdempotencyKeyRepository(application.ResolveDB())
+	return s.idempotencyKeyRepository
+}
+
+// SetTTL overrides how long responses are kept for replay.
+func (s *idempotencyService) SetTTL(d time.Duration) {
+	s.ttl = d
+}
+
+func (s *idempotencyService) getTTL() time.Duration {
+	if s.ttl != 0 {
+		return s.ttl
+	}
+
+	s.ttl = defaultIdempotencyKeyTTL
+	if d, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_KEY_TTL")); err == nil && d > 0 {
+		s.ttl = d
+	}
+	return s.ttl
+}
+
+// SetClock overrides the function used to tell the current time.
+func (s *idempotencyService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+func (s *idempotencyService) getClock() func() time.Time {
+	if s.now != nil {
+		return s.now
+	}
+
+	s.now = time.Now
+	return s.now
+}
+
+// Begin claims key for a request. When the key is new the returned key is
+// not completed and the request should go ahead. When a repeat of an earlier
+// request arrives the completed key is returned so that its response can be
+// replayed. Reusing a key for a different request, or repeating a request
+// that is still being handled, is a conflict.
+func (s *idempotencyService) Begin(ctx context.Context, scope, key, fingerprint string) (*models.IdempotencyKey, error) {
+	ctx, span := tracing.StartSpan(ctx, "IdempotencyService.Begin")
+	defer span.End()
+
+	now := s.getClock()()
+	claim := &models.IdempotencyKey{
+		Scope:       scope,
+		Key:         key,
+		Fingerprint: fingerprint,
+		LockedUntil: now.Add(idempotencyLockTimeout),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.getTTL()),
+	}
+
+	claimed, err := s.getIdempotencyKeyRepository().ClaimKey(ctx, claim, now)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	span.SetAttribute("idempotency.claimed", claimed)
+	if claimed {
+		return claim, nil
+	}
+
+	existing, err := s.getIdempotencyKeyRepository().FindKey(ctx, scope, key)
+	if err == repositories.ErrNotFound {
+		// The key was released between the claim and the lookup.
+		err = idempotencyRequestInProgress(key)
+	}
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	if existing.Fingerprint != fingerprint {
+		err = newError(ErrorKindConflict, "idempotency_key_reused", "idempotency key %q was used for a different request", key)
+		span.SetError(err)
+		return nil, err
+	}
+
+	if !existing.IsCompleted() {
+		err = idempotencyRequestInProgress(key)
+		span.SetError(err)
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+// Complete stores the response to the request that claimed key.
+func (s *idempotencyService) Complete(ctx context.Context, key *models.IdempotencyKey, status int, contentType string, body []byte) error {
+	completedAt := s.getClock()()
+	key.ResponseStatus = status
+	key.ResponseContentType = contentType
+	key.ResponseBody = body
+	key.CompletedAt = &completedAt
+
+	return s.getIdempotencyKeyRepository().CompleteKey(ctx, key)
+}
+
+// Release gives up the claim on key without storing a response, so that the
+// request can be retried.
+func (s *idempotencyService) Release(ctx context.Context, key *models.IdempotencyKey) error {
+	return s.getIdempotencyKeyRepository().ReleaseKey(ctx, key)
+}
+
+// PurgeExpired deletes the keys that can no longer be replayed.
+func (s *idempotencyService) PurgeExpired(ctx context.Context) (int, error) {
+	return s.getIdempotencyKeyRepository().DeleteExpiredKeys(ctx, s.getClock()())
+}
+
+func idempotencyRequestInProgress(key string) *Error {
+	return newError(ErrorKindConflict, "idempotency_request_in_progress", "a request with idempotency key %q is still being handled", key)
+}
@@ -0,0 +1,119 @@
+package services_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IdempotencyService", func() {
+	var (
+		ctrl               *gomock.Controller
+		idempotencyKeyRepo *mock_repositories.MockIdempotencyKeyRepository
+		idempotencyService services.IdempotencyService
+		key                *models.IdempotencyKey
+		err                error
+
+		now = time.Date(2019, 4, 27, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		idempotencyKeyRepo = mock_repositories.NewMockIdempotencyKeyRepository(ctrl)
+
+		idempotencyServiceImpl := services.NewIdempotencyService()
+		idempotencyServiceImpl.SetIdempotencyKeyRepository(idempotencyKeyRepo)
+		idempotencyServiceImpl.SetTTL(time.Hour)
+		idempotencyServiceImpl.SetClock(func() time.Time { return now })
+		idempotencyService = idempotencyServiceImpl
+	})
+
+	Describe("Begin", func() {
+		Describe("when the key is new", func() {
+			BeforeEach(func() {
+				idempotencyKeyRepo.EXPECT().ClaimKey(gomock.Any(), gomock.Eq(&models.IdempotencyKey{
+					Scope:       "user:5",
+					Key:         "abc",
+					Fingerprint: "f1",
+					LockedUntil: now.Add(time.Minute),
+					CreatedAt:   now,
+					ExpiresAt:   now.Add(time.Hour),
+				}), gomock.Eq(now)).Return(true, error(nil))
+
+				key, err = idempotencyService.Begin(context.Background(), "user:5", "abc", "f1")
+			})
+
+			It("returns the claim for the request to go ahead", func() {
+				Expect(err).To(BeNil())
+				Expect(key.IsCompleted()).To(BeFalse())
+			})
+		})
+
+		Describe("when the key is taken", func() {
+			var existing *models.IdempotencyKey
+
+			BeforeEach(func() {
+				existing = &models.IdempotencyKey{Scope: "user:5", Key: "abc", Fingerprint: "f1"}
+				idempotencyKeyRepo.EXPECT().ClaimKey(gomock.Any(), gomock.Any(), gomock.Eq(now)).Return(false, error(nil))
+				idempotencyKeyRepo.EXPECT().FindKey(gomock.Any(), gomock.Eq("user:5"), gomock.Eq("abc")).Return(existing, error(nil))
+			})
+
+			It("rejects a different request", func() {
+				_, err = idempotencyService.Begin(context.Background(), "user:5", "abc", "f2")
+				Expect(err.(*services.Error).Code).To(Equal("idempotency_key_reused"))
+			})
+
+			It("rejects a repeat while the first request is being handled", func() {
+				_, err = idempotencyService.Begin(context.Background(), "user:5", "abc", "f1")
+				Expect(err.(*services.Error).Code).To(Equal("idempotency_request_in_progress"))
+			})
+
+			It("returns the completed key of a finished request", func() {
+				existing.CompletedAt = &now
+				key, err = idempotencyService.Begin(context.Background(), "user:5", "abc", "f1")
+				Expect(err).To(BeNil())
+				Expect(key).To(Equal(existing))
+			})
+		})
+
+		Describe("when the key is released between the claim and the lookup", func() {
+			BeforeEach(func() {
+				idempotencyKeyRepo.EXPECT().ClaimKey(gomock.Any(), gomock.Any(), gomock.Eq(now)).Return(false, error(nil))
+				idempotencyKeyRepo.EXPECT().FindKey(gomock.Any(), gomock.Eq("user:5"), gomock.Eq("abc")).Return(nil, repositories.ErrNotFound)
+
+				_, err = idempotencyService.Begin(context.Background(), "user:5", "abc", "f1")
+			})
+
+			It("asks the caller to retry", func() {
+				Expect(err.(*services.Error).Code).To(Equal("idempotency_request_in_progress"))
+			})
+		})
+	})
+
+	Describe("Complete", func() {
+		It("stores the response", func() {
+			key = &models.IdempotencyKey{ID: 1}
+			idempotencyKeyRepo.EXPECT().CompleteKey(gomock.Any(), gomock.Eq(&models.IdempotencyKey{
+				ID:                  1,
+				ResponseStatus:      201,
+				ResponseContentType: "application/json",
+				ResponseBody:        []byte(`{}`),
+				CompletedAt:         &now,
+			})).Return(error(nil))
+
+			Expect(idempotencyService.Complete(context.Background(), key, 201, "application/json", []byte(`{}`))).To(Succeed())
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
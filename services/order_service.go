@@ -1,28 +1,104 @@
 package services
 
 import (
+	"context"
+	"os"
+	"sort"
+	"time"
+
 	"github.com/SebastianCoetzee/blog-order-service-example/application"
 	"github.com/SebastianCoetzee/blog-order-service-example/clients/restaurant"
 	"github.com/SebastianCoetzee/blog-order-service-example/models"
 	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
 	"github.com/go-pg/pg/orm"
-	"github.com/pkg/errors"
 )
 
+// defaultEnrichmentConcurrency is the number of enrichers that may run at the
+// same time while loading a page of orders.
+const defaultEnrichmentConcurrency = 3
+
+// defaultCancellationWindow is how long after an order is placed it may be
+// cancelled, unless ORDER_CANCELLATION_WINDOW says otherwise.
+const defaultCancellationWindow = 15 * time.Minute
+
 // OrderService represents the business-logic layer for Orders in the system.
 type OrderService interface {
-	FindAllOrdersByUserID(userID int) (models.Orders, error)
+	FindAllOrdersByUserID(ctx context.Context, userID int) (models.Orders, error)
+	FindOrdersForRestaurant(ctx context.Context, filter models.RestaurantOrderFilter, actor *models.Actor) (*models.RestaurantOrderPage, error)
+	FindOrderByID(ctx context.Context, orderID int, actor *models.Actor) (*models.Order, error)
+	CancelOrder(ctx context.Context, orderID, version int, reason models.CancellationReason, actor *models.Actor) (*models.Order, error)
+	RefundOrder(ctx context.Context, orderID, version int, req RefundRequest, actor *models.Actor) (*models.Order, error)
+	DeleteOrder(ctx context.Context, orderID, version int, actor *models.Actor) error
+	FindOrderAudit(ctx context.Context, orderID int, actor *models.Actor) (models.OrderAuditEntries, error)
+	SpendForUser(ctx context.Context, userID int, currency models.Currency, actor *models.Actor) (*models.SpendSummary, error)
+	StatsForUser(ctx context.Context, userID int, period models.StatsPeriod, actor *models.Actor) (*models.OrderStats, error)
+	FindOrderEventsForUser(ctx context.Context, userID int, afterID int64, actor *models.Actor) (models.OutboxEvents, error)
+}
+
+// Bounds on the events that are replayed to a user who resumes a stream of
+// their order events. Older events are not replayed.
+const (
+	orderEventReplayWindow = 24 * time.Hour
+	maxOrderEventReplay    = 500
+)
+
+// AnyVersion can be passed as the version of an order to change it whatever
+// its current version is.
+const AnyVersion = 0
+
+// RefundRequest describes an amount of an order to refund.
+type RefundRequest struct {
+	Amount models.Money `json:"amount"`
+	Reason string       `json:"reason"`
 }
 
+// The number of orders on a page of a restaurant's orders, unless the caller
+// asks for fewer.
+const (
+	defaultRestaurantOrdersLimit = 20
+	maxRestaurantOrdersLimit     = 100
+)
+
+// topRestaurantsLimit is the number of favourite restaurants included in a
+// user's order stats.
+const topRestaurantsLimit = 5
+
 // NewOrderService creates an order service.
 func NewOrderService() *orderService {
-	return &orderService{}
+	return &orderService{
+		enrichmentConcurrency: defaultEnrichmentConcurrency,
+	}
 }
 
 type orderService struct {
-	db               orm.DB
-	restaurantClient restaurant.Client
-	orderRepository  repositories.OrderRepository
+	db                          orm.DB
+	transactor                  repositories.Transactor
+	restaurantClient            restaurant.Client
+	exchangeRateService         ExchangeRateService
+	orderRepository             repositories.OrderRepository
+	orderItemRepository         repositories.OrderItemRepository
+	orderStatusChangeRepository repositories.OrderStatusChangeRepository
+	refundRepository            repositories.RefundRepository
+	outboxRepository            repositories.OutboxRepository
+	orderEventNotifier          repositories.OrderEventNotifier
+	orderAuditRepository        repositories.OrderAuditRepository
+	enrichmentConcurrency       int
+	cancellationWindow          time.Duration
+	now                         func() time.Time
+}
+
+func (s *orderService) SetTransactor(t repositories.Transactor) {
+	s.transactor = t
+}
+
+func (s *orderService) getTransactor() repositories.Transactor {
+	if s.transactor != nil {
+		return s.transactor
+	}
+
+	s.transactor = repositories.NewTransactor(application.ResolveDB())
+	return s.transactor
 }
 
 func (s *orderService) SetOrderRepository(r repositories.OrderRepository) {
@@ -38,6 +114,84 @@ func (s *orderService) getOrderRepository() repositories.OrderRepository {
 	return s.orderRepository
 }
 
+func (s *orderService) SetOrderItemRepository(r repositories.OrderItemRepository) {
+	s.orderItemRepository = r
+}
+
+func (s *orderService) getOrderItemRepository() repositories.OrderItemRepository {
+	if s.orderItemRepository != nil {
+		return s.orderItemRepository
+	}
+
+	s.orderItemRepository = repositories.NewOrderItemRepository(application.ResolveDB())
+	return s.orderItemRepository
+}
+
+func (s *orderService) SetOrderStatusChangeRepository(r repositories.OrderStatusChangeRepository) {
+	s.orderStatusChangeRepository = r
+}
+
+func (s *orderService) getOrderStatusChangeRepository() repositories.OrderStatusChangeRepository {
+	if s.orderStatusChangeRepository != nil {
+		return s.orderStatusChangeRepository
+	}
+
+	s.orderStatusChangeRepository = repositories.NewOrderStatusChangeRepository(application.ResolveDB())
+	return s.orderStatusChangeRepository
+}
+
+func (s *orderService) SetRefundRepository(r repositories.RefundRepository) {
+	s.refundRepository = r
+}
+
+func (s *orderService) getRefundRepository() repositories.RefundRepository {
+	if s.refundRepository != nil {
+		return s.refundRepository
+	}
+
+	s.refundRepository = repositories.NewRefundRepository(application.ResolveDB())
+	return s.refundRepository
+}
+
+func (s *orderService) SetOutboxRepository(r repositories.OutboxRepository) {
+	s.outboxRepository = r
+}
+
+func (s *orderService) getOutboxRepository() repositories.OutboxRepository {
+	if s.outboxRepository != nil {
+		return s.outboxRepository
+	}
+
+	s.outboxRepository = repositories.NewOutboxRepository(application.ResolveDB())
+	return s.outboxRepository
+}
+
+func (s *orderService) SetOrderEventNotifier(n repositories.OrderEventNotifier) {
+	s.orderEventNotifier = n
+}
+
+func (s *orderService) getOrderEventNotifier() repositories.OrderEventNotifier {
+	if s.orderEventNotifier != nil {
+		return s.orderEventNotifier
+	}
+
+	s.orderEventNotifier = repositories.NewOrderEventNotifier(application.ResolveDB())
+	return s.orderEventNotifier
+}
+
+func (s *orderService) SetOrderAuditRepository(r repositories.OrderAuditRepository) {
+	s.orderAuditRepository = r
+}
+
+func (s *orderService) getOrderAuditRepository() repositories.OrderAuditRepository {
+	if s.orderAuditRepository != nil {
+		return s.orderAuditRepository
+	}
+
+	s.orderAuditRepository = repositories.NewOrderAuditRepository(application.ResolveDB())
+	return s.orderAuditRepository
+}
+
 func (s *orderService) SetRestaurantClient(c restaurant.Client) {
 	s.restaurantClient = c
 }
@@ -51,39 +205,684 @@ func (s *orderService) getRestaurantClient() restaurant.Client {
 	return s.restaurantClient
 }
 
-func (s *orderService) FindAllOrdersByUserID(userID int) (models.Orders, error) {
-	orders, err := s.getOrderRepository().FindAllOrdersByUserID(userID)
+func (s *orderService) SetExchangeRateService(e ExchangeRateService) {
+	s.exchangeRateService = e
+}
+
+func (s *orderService) getExchangeRateService() ExchangeRateService {
+	if s.exchangeRateService != nil {
+		return s.exchangeRateService
+	}
+
+	s.exchangeRateService = NewExchangeRateService()
+	return s.exchangeRateService
+}
+
+// SetEnrichmentConcurrency sets how many enrichers may run at the same time.
+func (s *orderService) SetEnrichmentConcurrency(n int) {
+	s.enrichmentConcurrency = n
+}
+
+// SetCancellationWindow overrides how long after an order is placed it may be
+// cancelled.
+func (s *orderService) SetCancellationWindow(d time.Duration) {
+	s.cancellationWindow = d
+}
+
+func (s *orderService) getCancellationWindow() time.Duration {
+	if s.cancellationWindow != 0 {
+		return s.cancellationWindow
+	}
+
+	s.cancellationWindow = defaultCancellationWindow
+	if d, err := time.ParseDuration(os.Getenv("ORDER_CANCELLATION_WINDOW")); err == nil && d > 0 {
+		s.cancellationWindow = d
+	}
+	return s.cancellationWindow
+}
+
+// SetClock overrides the function used to tell the current time.
+func (s *orderService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+func (s *orderService) getClock() func() time.Time {
+	if s.now != nil {
+		return s.now
+	}
+
+	s.now = time.Now
+	return s.now
+}
+
+// enrichmentPipeline returns the pipeline that loads the restaurant, items,
+// status history and refunds of a page of orders. None of these depend on each other,
+// so they run concurrently.
+func (s *orderService) enrichmentPipeline() *EnrichmentPipeline {
+	return NewEnrichmentPipeline(s.enrichmentConcurrency).
+		Add("restaurants", &restaurantEnricher{client: s.getRestaurantClient()}).
+		Add("items", &itemsEnricher{repository: s.getOrderItemRepository()}).
+		Add("status_history", &statusHistoryEnricher{repository: s.getOrderStatusChangeRepository()}).
+		Add("refunds", &refundsEnricher{repository: s.getRefundRepository()})
+}
+
+func (s *orderService) FindAllOrdersByUserID(ctx context.Context, userID int) (models.Orders, error) {
+	ctx, span := tracing.StartSpan(ctx, "OrderService.FindAllOrdersByUserID")
+	defer span.End()
+
+	orders, err := s.getOrderRepository().FindAllOrdersByUserID(ctx, userID)
 	if err != nil {
+		span.SetError(err)
 		return nil, err
 	}
+	span.SetAttribute("orders.count", len(orders))
 
 	if len(orders) == 0 {
 		return orders, nil
 	}
 
-	restaurantIDs := make([]int, 0, len(orders))
+	if err = s.enrichmentPipeline().Run(ctx, orders); err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// FindOrdersForRestaurant returns a page of the orders placed with a
+// restaurant, newest first. Only the restaurant itself and support agents may
+// list its orders, and the customer behind each order is only shown to callers
+// with the orders:customer_details or orders:admin scope.
+func (s *orderService) FindOrdersForRestaurant(ctx context.Context, filter models.RestaurantOrderFilter, actor *models.Actor) (*models.RestaurantOrderPage, error) {
+	ctx, span := tracing.StartSpan(ctx, "OrderService.FindOrdersForRestaurant")
+	defer span.End()
+
+	if !actor.IsRestaurant(filter.RestaurantID) && !actor.HasScope(models.ScopeOrdersAdmin) {
+		err := newError(ErrorKindForbidden, "forbidden", "not allowed to list the orders of restaurant %d", filter.RestaurantID)
+		span.SetError(err)
+		return nil, err
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultRestaurantOrdersLimit
+	}
+	if filter.Limit > maxRestaurantOrdersLimit {
+		filter.Limit = maxRestaurantOrdersLimit
+	}
+	limit := filter.Limit
+
+	// Ask for one more order than fits on the page to find out whether there
+	// is a next page.
+	filter.Limit++
+	orders, err := s.getOrderRepository().FindOrdersByRestaurant(ctx, filter)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	span.SetAttribute("orders.count", len(orders))
+
+	page := &models.RestaurantOrderPage{Orders: []*models.RestaurantOrder{}}
+	if len(orders) > limit {
+		orders = orders[:limit]
+		page.NextCursor = models.CursorAfter(orders[limit-1]).Encode()
+	}
+
+	if len(orders) > 0 {
+		err = NewEnrichmentPipeline(s.enrichmentConcurrency).
+			Add("items", &itemsEnricher{repository: s.getOrderItemRepository()}).
+			Add("status_history", &statusHistoryEnricher{repository: s.getOrderStatusChangeRepository()}).
+			Add("refunds", &refundsEnricher{repository: s.getRefundRepository()}).
+			Run(ctx, orders)
+		if err != nil {
+			span.SetError(err)
+			return nil, err
+		}
+	}
+
+	withCustomer := actor.HasScope(models.ScopeOrdersCustomerDetails) || actor.HasScope(models.ScopeOrdersAdmin)
 	for _, order := range orders {
-		restaurantIDs = append(restaurantIDs, order.RestaurantID)
+		page.Orders = append(page.Orders, models.NewRestaurantOrder(order, withCustomer))
 	}
 
-	restaurants, err := s.getRestaurantClient().GetRestaurantsByIDs(restaurantIDs)
+	return page, nil
+}
+
+// FindOrderByID returns a single order with its restaurant, items, status
+// history and refunds. Only the user who placed the order and support agents
+// may see it.
+func (s *orderService) FindOrderByID(ctx context.Context, orderID int, actor *models.Actor) (*models.Order, error) {
+	ctx, span := tracing.StartSpan(ctx, "OrderService.FindOrderByID")
+	defer span.End()
+
+	order, err := s.getOrderRepository().FindOrderByID(ctx, orderID)
+	if err == repositories.ErrNotFound {
+		err = orderNotFound(orderID)
+	}
 	if err != nil {
+		span.SetError(err)
 		return nil, err
 	}
 
+	if !actor.IsUser(order.UserID) && !actor.HasScope(models.ScopeOrdersAdmin) {
+		// Orders of other users are reported as missing so that their IDs
+		// cannot be probed.
+		err = orderNotFound(orderID)
+		span.SetError(err)
+		return nil, err
+	}
+
+	if err = s.enrichmentPipeline().Run(ctx, models.Orders{order}); err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// checkOrderVersion fails when the order has changed since the version that a
+// request was based on.
+func checkOrderVersion(order *models.Order, version int) error {
+	if version == AnyVersion || version == order.Version {
+		return nil
+	}
+
+	return orderVersionMismatch(order.ID).withDetail("current_version", order.Version)
+}
+
+// addEvents adds events to the outbox and announces them to every instance of
+// the service once the transaction in ctx commits.
+func (s *orderService) addEvents(ctx context.Context, events ...*models.OutboxEvent) error {
+	if err := s.getOutboxRepository().AddEvents(ctx, events); err != nil {
+		return err
+	}
+
+	return s.getOrderEventNotifier().NotifyOrderEvents(ctx, events)
+}
+
+// updateOrder saves order, reporting a concurrent change as a version
+// mismatch, and adds the change from before to the audit log. Every change to
+// an order goes through updateOrder inside the transaction in ctx, so a
+// change is never committed without its audit entry.
+func (s *orderService) updateOrder(ctx context.Context, before, order *models.Order, action models.OrderAuditAction, actor *models.Actor) error {
+	err := s.getOrderRepository().UpdateOrder(ctx, order)
+	if err == repositories.ErrVersionConflict {
+		return orderVersionMismatch(order.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	entry, err := models.NewOrderAuditEntry(before, order, action, actor.ID, tracing.RequestIDFromContext(ctx), s.getClock()())
+	if err != nil {
+		return err
+	}
+
+	return s.getOrderAuditRepository().AddEntry(ctx, entry)
+}
+
+// CancelOrder cancels an order on behalf of its user or a support agent. An
+// order can only be cancelled within the cancellation window after it was
+// placed, and only before the restaurant starts preparing it. An
+// OrderStatusChanged event is added to the outbox in the same transaction,
+// and the restaurant is told about the cancellation when the event is
+// relayed, so the restaurant never hears of a cancellation that was rolled
+// back. Unless version is AnyVersion, it must be the order's current version.
+// The cancelled order is returned with its restaurant, items, status history
+// and refunds.
+func (s *orderService) CancelOrder(ctx context.Context, orderID, version int, reason models.CancellationReason, actor *models.Actor) (*models.Order, error) {
+	ctx, span := tracing.StartSpan(ctx, "OrderService.CancelOrder")
+	defer span.End()
+
+	if !reason.IsValid() {
+		err := newError(ErrorKindInvalid, "invalid_cancellation_reason", "%q is not a valid cancellation reason", reason)
+		span.SetError(err)
+		return nil, err
+	}
+
+	var order *models.Order
+	err := s.getTransactor().RunInTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = s.getOrderRepository().FindOrderByIDForUpdate(ctx, orderID)
+		if err == repositories.ErrNotFound {
+			return orderNotFound(orderID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if !actor.IsUser(order.UserID) && !actor.HasScope(models.ScopeOrdersAdmin) {
+			return newError(ErrorKindForbidden, "forbidden", "not allowed to cancel order %d", orderID)
+		}
+
+		if err = checkOrderVersion(order, version); err != nil {
+			return err
+		}
+
+		if !order.Status.IsCancellable() {
+			return newError(ErrorKindConflict, "order_not_cancellable", "orders that are %s cannot be cancelled", order.Status).
+				withDetail("status", order.Status)
+		}
+
+		now := s.getClock()()
+		deadline := order.PlacedAt.Add(s.getCancellationWindow())
+		if now.After(deadline) {
+			return newError(ErrorKindConflict, "cancellation_window_expired", "order %d can no longer be cancelled", orderID).
+				withDetail("placed_at", order.PlacedAt).
+				withDetail("cancellable_until", deadline)
+		}
+
+		before := *order
+		order.Status = models.OrderStatusCancelled
+		order.CancelledAt = &now
+		order.CancelledBy = actor.ID
+		order.CancellationReason = reason
+		if err = s.updateOrder(ctx, &before, order, models.OrderAuditActionCancel, actor); err != nil {
+			return err
+		}
+
+		change := &models.OrderStatusChange{
+			OrderID:   order.ID,
+			Status:    models.OrderStatusCancelled,
+			ChangedAt: now,
+		}
+		if err = s.getOrderStatusChangeRepository().CreateStatusChange(ctx, change); err != nil {
+			return err
+		}
+
+		event, err := models.NewOrderStatusChangedEvent(order, change)
+		if err != nil {
+			return err
+		}
+		return s.addEvents(ctx, event)
+	})
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	// The cancelled order is returned the way FindOrderByID returns it.
+	if err = s.enrichmentPipeline().Run(ctx, models.Orders{order}); err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// RefundOrder refunds all or part of an order. Refunds are only available to
+// support agents, must be in the currency the order was paid in and may not
+// add up to more than the order total. The order row is locked while the
+// refund is recorded so that concurrent refunds cannot exceed the total
+// between them. An OrderRefunded event is added to the outbox in the same
+// transaction. The order is returned with its full refund history. Unless
+// version is AnyVersion, it must be the order's current version.
+func (s *orderService) RefundOrder(ctx context.Context, orderID, version int, req RefundRequest, actor *models.Actor) (*models.Order, error) {
+	ctx, span := tracing.StartSpan(ctx, "OrderService.RefundOrder")
+	defer span.End()
+
+	if !actor.HasScope(models.ScopeOrdersAdmin) {
+		err := newError(ErrorKindForbidden, "forbidden", "not allowed to refund order %d", orderID)
+		span.SetError(err)
+		return nil, err
+	}
+
+	if !req.Amount.IsPositive() {
+		err := newError(ErrorKindInvalid, "invalid_refund_amount", "refund amount must be positive")
+		span.SetError(err)
+		return nil, err
+	}
+
+	if req.Reason == "" {
+		err := newError(ErrorKindInvalid, "missing_refund_reason", "a reason is required for refunds")
+		span.SetError(err)
+		return nil, err
+	}
+
+	var order *models.Order
+	err := s.getTransactor().RunInTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = s.getOrderRepository().FindOrderByIDForUpdate(ctx, orderID)
+		if err == repositories.ErrNotFound {
+			return orderNotFound(orderID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = checkOrderVersion(order, version); err != nil {
+			return err
+		}
+
+		if req.Amount.Currency != order.Total.Currency {
+			return newError(ErrorKindInvalid, "refund_currency_mismatch", "refund currency %s does not match order currency %s", req.Amount.Currency, order.Total.Currency).
+				withDetail("order_currency", order.Total.Currency)
+		}
+
+		refunds, err := s.getRefundRepository().FindRefundsByOrderIDs(ctx, []int{orderID})
+		if err != nil {
+			return err
+		}
+
+		if err = order.SetRefunds(refunds); err != nil {
+			return err
+		}
+
+		refundable := *order.NetTotal
+		if cmp, err := req.Amount.Cmp(refundable); err != nil || cmp > 0 {
+			return newError(ErrorKindConflict, "refund_exceeds_total", "refund of %s exceeds the refundable amount of %s", req.Amount, refundable).
+				withDetail("refundable_amount", refundable)
+		}
+
+		refund := &models.Refund{
+			OrderID:   orderID,
+			Amount:    req.Amount,
+			Reason:    req.Reason,
+			ActorID:   actor.ID,
+			CreatedAt: s.getClock()(),
+		}
+		if err = s.getRefundRepository().CreateRefund(ctx, refund); err != nil {
+			return err
+		}
+
+		// A refund changes the order's net total, so it gets a new version.
+		before := *order
+		if err = order.SetRefunds(append(refunds, refund)); err != nil {
+			return err
+		}
+		if err = s.updateOrder(ctx, &before, order, models.OrderAuditActionRefund, actor); err != nil {
+			return err
+		}
+
+		event, err := models.NewOrderRefundedEvent(order, refund)
+		if err != nil {
+			return err
+		}
+		return s.addEvents(ctx, event)
+	})
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// DeleteOrder soft deletes an order. Only support agents may delete orders.
+// A deleted order is kept in the database until the retention job removes it,
+// but it is left out of every listing, lookup and statistic. Unless version is
+// AnyVersion, it must be the order's current version.
+func (s *orderService) DeleteOrder(ctx context.Context, orderID, version int, actor *models.Actor) error {
+	ctx, span := tracing.StartSpan(ctx, "OrderService.DeleteOrder")
+	defer span.End()
+
+	if !actor.HasScope(models.ScopeOrdersAdmin) {
+		err := newError(ErrorKindForbidden, "forbidden", "not allowed to delete order %d", orderID)
+		span.SetError(err)
+		return err
+	}
+
+	err := s.getTransactor().RunInTransaction(ctx, func(ctx context.Context) error {
+		order, err := s.getOrderRepository().FindOrderByIDForUpdate(ctx, orderID)
+		if err == repositories.ErrNotFound {
+			return orderNotFound(orderID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = checkOrderVersion(order, version); err != nil {
+			return err
+		}
+
+		before := *order
+		order.DeletedAt = s.getClock()()
+		return s.updateOrder(ctx, &before, order, models.OrderAuditActionDelete, actor)
+	})
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+
+	return nil
+}
+
+// FindOrderAudit returns the audit log of an order, oldest first. The log of
+// a deleted order is still available. Only support agents may see it.
+func (s *orderService) FindOrderAudit(ctx context.Context, orderID int, actor *models.Actor) (models.OrderAuditEntries, error) {
+	ctx, span := tracing.StartSpan(ctx, "OrderService.FindOrderAudit")
+	defer span.End()
+
+	if !actor.HasScope(models.ScopeOrdersAdmin) {
+		err := newError(ErrorKindForbidden, "forbidden", "not allowed to see the audit log of order %d", orderID)
+		span.SetError(err)
+		return nil, err
+	}
+
+	entries, err := s.getOrderAuditRepository().FindEntriesByOrderID(ctx, orderID)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	span.SetAttribute("audit.entries", len(entries))
+
+	// Orders that have never changed have no entries, so an empty log is
+	// only reported as missing when the order does not exist.
+	if len(entries) == 0 {
+		_, err = s.getOrderRepository().FindOrderByID(ctx, orderID)
+		if err == repositories.ErrNotFound {
+			err = orderNotFound(orderID)
+		}
+		if err != nil {
+			span.SetError(err)
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+// SpendForUser adds up what a user has spent across their orders in the given
+// currency. Cancelled orders are left out and refunds are subtracted. Each
+// order is converted at the exchange rate in effect when it was placed. Only
+// the user and admins may see it.
+func (s *orderService) SpendForUser(ctx context.Context, userID int, currency models.Currency, actor *models.Actor) (*models.SpendSummary, error) {
+	ctx, span := tracing.StartSpan(ctx, "OrderService.SpendForUser")
+	defer span.End()
+
+	if !actor.IsUser(userID) && !actor.HasScope(models.ScopeOrdersAdmin) {
+		err := newError(ErrorKindForbidden, "forbidden", "not allowed to see the spend of user %d", userID)
+		span.SetError(err)
+		return nil, err
+	}
+
+	if !currency.IsValid() {
+		err := newError(ErrorKindInvalid, "unknown_currency", "%q is not a supported currency", currency)
+		span.SetError(err)
+		return nil, err
+	}
+
+	orders, err := s.getOrderRepository().FindAllOrdersByUserID(ctx, userID)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	spent := models.Orders{}
+	for _, order := range orders {
+		if order.Status != models.OrderStatusCancelled {
+			spent = append(spent, order)
+		}
+	}
+
+	summary := &models.SpendSummary{
+		UserID:     userID,
+		Currency:   currency,
+		Total:      models.ZeroMoney(currency),
+		OrderCount: len(spent),
+	}
+	if len(spent) == 0 {
+		return summary, nil
+	}
+
+	refunds := &refundsEnricher{repository: s.getRefundRepository()}
+	if err = refunds.Enrich(ctx, spent); err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	amounts := make([]DatedAmount, 0, len(spent))
+	for _, order := range spent {
+		amounts = append(amounts, DatedAmount{Amount: *order.NetTotal, At: order.PlacedAt})
+	}
+
+	converted, err := s.getExchangeRateService().ConvertAll(ctx, amounts, currency)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	for _, amount := range converted {
+		if summary.Total, err = summary.Total.Add(amount); err != nil {
+			span.SetError(err)
+			return nil, err
+		}
+	}
+
+	return summary, nil
+}
+
+// StatsForUser summarises a user's orders with aggregate queries rather than
+// loading every order. The favourite restaurants are ranked by number of
+// orders, then by spend in the currency the user orders in most, and only
+// those are looked up in the RestaurantService. Only the user and admins may
+// see them.
+func (s *orderService) StatsForUser(ctx context.Context, userID int, period models.StatsPeriod, actor *models.Actor) (*models.OrderStats, error) {
+	ctx, span := tracing.StartSpan(ctx, "OrderService.StatsForUser")
+	defer span.End()
+
+	if !actor.IsUser(userID) && !actor.HasScope(models.ScopeOrdersAdmin) {
+		err := newError(ErrorKindForbidden, "forbidden", "not allowed to see the order stats of user %d", userID)
+		span.SetError(err)
+		return nil, err
+	}
+
+	stats, err := s.statsForUser(ctx, userID, period)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (s *orderService) statsForUser(ctx context.Context, userID int, period models.StatsPeriod) (*models.OrderStats, error) {
+	spend, err := s.getOrderRepository().SpendByCurrencyForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	restaurants, err := s.getOrderRepository().RestaurantStatsForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.getOrderRepository().OrderCountsForUser(ctx, userID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.OrderStats{
+		UserID:          userID,
+		SpendByCurrency: spend,
+		TopRestaurants:  restaurants,
+		Period:          period,
+		OrdersPerPeriod: counts,
+	}
+	for _, cs := range spend {
+		stats.OrderCount += cs.OrderCount
+		cs.AverageOrderValue = cs.Total.Div(int64(cs.OrderCount))
+	}
+
+	// SpendByCurrency is ordered by number of orders, most first.
+	var mainCurrency models.Currency
+	if len(spend) > 0 {
+		mainCurrency = spend[0].Currency
+	}
+	sort.SliceStable(restaurants, func(i, j int) bool {
+		a, b := restaurants[i], restaurants[j]
+		if a.OrderCount != b.OrderCount {
+			return a.OrderCount > b.OrderCount
+		}
+		return a.SpendIn(mainCurrency).MinorUnits > b.SpendIn(mainCurrency).MinorUnits
+	})
+	if len(restaurants) > topRestaurantsLimit {
+		stats.TopRestaurants = restaurants[:topRestaurantsLimit]
+	}
+
+	if err = s.enrichTopRestaurants(ctx, stats.TopRestaurants); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// enrichTopRestaurants sets RestaurantStats.Restaurant from the
+// RestaurantService. Restaurants that the RestaurantService no longer knows
+// about are left without details rather than failing the stats.
+func (s *orderService) enrichTopRestaurants(ctx context.Context, stats []*models.RestaurantStats) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(stats))
+	for _, rs := range stats {
+		ids = append(ids, rs.RestaurantID)
+	}
+
+	restaurants, err := s.getRestaurantClient().GetRestaurantsByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
 	restaurantsByID := make(map[int]*models.Restaurant)
 	for _, restaurant := range restaurants {
 		restaurantsByID[restaurant.ID] = restaurant
 	}
 
-	for _, order := range orders {
-		restaurant, ok := restaurantsByID[order.RestaurantID]
-		if !ok {
-			return nil, errors.Errorf("restaurant with ID %d not found", order.RestaurantID)
-		}
+	for _, rs := range stats {
+		rs.Restaurant = restaurantsByID[rs.RestaurantID]
+	}
+
+	return nil
+}
 
-		order.Restaurant = restaurant
+// FindOrderEventsForUser returns the events about a user's orders that came
+// after the event with ID afterID, oldest first, so that a stream of them can
+// be resumed. An afterID of 0 means that the caller has not seen any events
+// and so has nothing to catch up on. Only events from the last day are
+// replayed, up to a limit. Only the user and support agents may see the
+// events.
+func (s *orderService) FindOrderEventsForUser(ctx context.Context, userID int, afterID int64, actor *models.Actor) (models.OutboxEvents, error) {
+	ctx, span := tracing.StartSpan(ctx, "OrderService.FindOrderEventsForUser")
+	defer span.End()
+
+	if !actor.IsUser(userID) && !actor.HasScope(models.ScopeOrdersAdmin) {
+		err := newError(ErrorKindForbidden, "forbidden", "not allowed to see the order events of user %d", userID)
+		span.SetError(err)
+		return nil, err
 	}
 
-	return orders, nil
+	if afterID <= 0 {
+		return models.OutboxEvents{}, nil
+	}
+
+	since := s.getClock()().Add(-orderEventReplayWindow)
+	events, err := s.getOutboxRepository().FindEventsForUser(ctx, userID, afterID, since, maxOrderEventReplay)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	span.SetAttribute("events.count", len(events))
+
+	return events, nil
 }
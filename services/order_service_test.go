@@ -1,17 +1,21 @@
 package services_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 
 	"github.com/SebastianCoetzee/blog-order-service-example/clients/mock_restaurant"
-	"github.com/SebastianCoetzee/blog-order-service-example/clients/restaurant"
 	"github.com/SebastianCoetzee/blog-order-service-example/mock_repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_services"
 	"github.com/SebastianCoetzee/blog-order-service-example/models"
 	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
 	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -23,37 +27,76 @@ func TestOrderService(t *testing.T) {
 
 var _ = Describe("OrderService", func() {
 	var (
-		restaurantClient restaurant.Client
-		orderRepo        repositories.OrderRepository
-		orderService     services.OrderService
-		orders           models.Orders
-		ctrl             *gomock.Controller
-		err              error
-
-		userID = 5
+		ctrl                *gomock.Controller
+		orderRepo           *mock_repositories.MockOrderRepository
+		itemRepo            *mock_repositories.MockOrderItemRepository
+		statusChangeRepo    *mock_repositories.MockOrderStatusChangeRepository
+		refundRepo          *mock_repositories.MockRefundRepository
+		outboxRepo          *mock_repositories.MockOutboxRepository
+		notifier            *mock_repositories.MockOrderEventNotifier
+		auditRepo           *mock_repositories.MockOrderAuditRepository
+		restaurantClient    *mock_restaurant.MockClient
+		exchangeRateService *mock_services.MockExchangeRateService
+		orderService        services.OrderService
+		now                 time.Time
+
+		admin = &models.Actor{ID: "agent:1", Scopes: []string{models.ScopeOrdersAdmin}}
 	)
 
 	BeforeEach(func() {
 		ctrl = gomock.NewController(GinkgoT())
+		orderRepo = mock_repositories.NewMockOrderRepository(ctrl)
+		itemRepo = mock_repositories.NewMockOrderItemRepository(ctrl)
+		statusChangeRepo = mock_repositories.NewMockOrderStatusChangeRepository(ctrl)
+		refundRepo = mock_repositories.NewMockRefundRepository(ctrl)
+		outboxRepo = mock_repositories.NewMockOutboxRepository(ctrl)
+		notifier = mock_repositories.NewMockOrderEventNotifier(ctrl)
+		auditRepo = mock_repositories.NewMockOrderAuditRepository(ctrl)
+		restaurantClient = mock_restaurant.NewMockClient(ctrl)
+		exchangeRateService = mock_services.NewMockExchangeRateService(ctrl)
+		now = time.Now()
 	})
 
+	// The service is put together after the BeforeEach blocks of the specs,
+	// which may replace the mocks.
 	JustBeforeEach(func() {
 		orderServiceImpl := services.NewOrderService()
+		orderServiceImpl.SetTransactor(passThroughTransactor(ctrl))
 		orderServiceImpl.SetOrderRepository(orderRepo)
+		orderServiceImpl.SetOrderItemRepository(itemRepo)
+		orderServiceImpl.SetOrderStatusChangeRepository(statusChangeRepo)
+		orderServiceImpl.SetRefundRepository(refundRepo)
+		orderServiceImpl.SetOutboxRepository(outboxRepo)
+		orderServiceImpl.SetOrderEventNotifier(notifier)
+		orderServiceImpl.SetOrderAuditRepository(auditRepo)
 		orderServiceImpl.SetRestaurantClient(restaurantClient)
+		orderServiceImpl.SetExchangeRateService(exchangeRateService)
+		orderServiceImpl.SetCancellationWindow(15 * time.Minute)
+		orderServiceImpl.SetClock(func() time.Time { return now })
 		orderService = orderServiceImpl
 	})
 
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
 	Describe("FindAllOrdersByUserID", func() {
+		var (
+			orders models.Orders
+			err    error
+
+			userID = 5
+		)
+
 		Describe("with no records in the database", func() {
 			BeforeEach(func() {
 				orderRepoMock := mock_repositories.NewMockOrderRepository(ctrl)
-				orderRepoMock.EXPECT().FindAllOrdersByUserID(gomock.Eq(userID))
+				orderRepoMock.EXPECT().FindAllOrdersByUserID(gomock.Any(), gomock.Eq(userID))
 				orderRepo = orderRepoMock
 			})
 
 			It("returns an empty slice of orders", func() {
-				orders, err = orderService.FindAllOrdersByUserID(userID)
+				orders, err = orderService.FindAllOrdersByUserID(context.Background(), userID)
 				Expect(err).To(BeNil())
 				Expect(len(orders)).To(Equal(0))
 			})
@@ -62,15 +105,15 @@ var _ = Describe("OrderService", func() {
 		Describe("when a few records exist", func() {
 			BeforeEach(func() {
 				order1 := &models.Order{
-					Total:        1000,
-					CurrencyCode: "GBP",
+					ID:           1,
+					Total:        models.NewMoney(1000, models.GBP),
 					UserID:       userID,
 					RestaurantID: 8,
 					PlacedAt:     time.Now().Add(-72 * time.Hour),
 				}
 				order2 := &models.Order{
-					Total:        2500,
-					CurrencyCode: "GBP",
+					ID:           2,
+					Total:        models.NewMoney(2500, models.GBP),
 					UserID:       userID,
 					RestaurantID: 9,
 					PlacedAt:     time.Now().Add(-36 * time.Hour),
@@ -78,22 +121,49 @@ var _ = Describe("OrderService", func() {
 
 				orderRepoMock := mock_repositories.NewMockOrderRepository(ctrl)
 				orderRepoMock.EXPECT().
-					FindAllOrdersByUserID(gomock.Eq(userID)).
+					FindAllOrdersByUserID(gomock.Any(), gomock.Eq(userID)).
 					Return(models.Orders{order2, order1}, error(nil))
 				orderRepo = orderRepoMock
+
+				itemRepoMock := mock_repositories.NewMockOrderItemRepository(ctrl)
+				itemRepoMock.EXPECT().
+					FindItemsByOrderIDs(gomock.Any(), gomock.Eq([]int{2, 1})).
+					Return(models.OrderItems{
+						{ID: 3, OrderID: 2, Name: "Chicken wrap", Quantity: 2, UnitPrice: 1250},
+					}, error(nil)).
+					AnyTimes()
+				itemRepo = itemRepoMock
+
+				statusChangeRepoMock := mock_repositories.NewMockOrderStatusChangeRepository(ctrl)
+				statusChangeRepoMock.EXPECT().
+					FindStatusChangesByOrderIDs(gomock.Any(), gomock.Eq([]int{2, 1})).
+					Return(models.OrderStatusChanges{
+						{ID: 4, OrderID: 1, Status: models.OrderStatusPlaced, ChangedAt: order1.PlacedAt},
+					}, error(nil)).
+					AnyTimes()
+				statusChangeRepo = statusChangeRepoMock
+
+				refundRepoMock := mock_repositories.NewMockRefundRepository(ctrl)
+				refundRepoMock.EXPECT().
+					FindRefundsByOrderIDs(gomock.Any(), gomock.Eq([]int{2, 1})).
+					Return(models.Refunds{
+						{ID: 5, OrderID: 2, Amount: models.NewMoney(500, models.GBP), Reason: "Cold chips"},
+					}, error(nil)).
+					AnyTimes()
+				refundRepo = refundRepoMock
 			})
 
 			Describe("when not all Restaurants can be found", func() {
 				BeforeEach(func() {
 					restaurantClientMock := mock_restaurant.NewMockClient(ctrl)
 					restaurantClientMock.EXPECT().
-						GetRestaurantsByIDs(gomock.Eq([]int{9, 8})).
+						GetRestaurantsByIDs(gomock.Any(), gomock.Eq([]int{9, 8})).
 						Return(models.Restaurants{}, error(nil))
 					restaurantClient = restaurantClientMock
 				})
 
 				It("returns only the records belonging to the user, in order from latest palced_at first", func() {
-					orders, err = orderService.FindAllOrdersByUserID(userID)
+					orders, err = orderService.FindAllOrdersByUserID(context.Background(), userID)
 					Expect(err).To(MatchError("restaurant with ID 9 not found"))
 				})
 			})
@@ -112,25 +182,764 @@ var _ = Describe("OrderService", func() {
 
 					restaurantClientMock := mock_restaurant.NewMockClient(ctrl)
 					restaurantClientMock.EXPECT().
-						GetRestaurantsByIDs(gomock.Eq([]int{9, 8})).
+						GetRestaurantsByIDs(gomock.Any(), gomock.Eq([]int{9, 8})).
 						Return(models.Restaurants{restaurant1, restaurant2}, error(nil))
 					restaurantClient = restaurantClientMock
 				})
 
 				It("returns only the records belonging to the user, in order from latest palced_at first", func() {
-					orders, err = orderService.FindAllOrdersByUserID(userID)
+					orders, err = orderService.FindAllOrdersByUserID(context.Background(), userID)
 					Expect(err).To(BeNil())
 					Expect(len(orders)).To(Equal(2))
 					Expect(orders[0].Restaurant.Name).To(Equal("Nando's"))
-					Expect(orders[0].Total).To(Equal(2500))
+					Expect(orders[0].Total).To(Equal(models.NewMoney(2500, models.GBP)))
 					Expect(orders[1].Restaurant.Name).To(Equal("KFC"))
-					Expect(orders[1].Total).To(Equal(1000))
+					Expect(orders[1].Total).To(Equal(models.NewMoney(1000, models.GBP)))
+				})
+
+				It("loads the items and status history of every order", func() {
+					orders, err = orderService.FindAllOrdersByUserID(context.Background(), userID)
+					Expect(err).To(BeNil())
+					Expect(len(orders[0].Items)).To(Equal(1))
+					Expect(orders[0].Items[0].Name).To(Equal("Chicken wrap"))
+					Expect(len(orders[0].StatusHistory)).To(Equal(0))
+					Expect(len(orders[1].Items)).To(Equal(0))
+					Expect(len(orders[1].StatusHistory)).To(Equal(1))
+					Expect(orders[1].StatusHistory[0].Status).To(Equal(models.OrderStatusPlaced))
+				})
+
+				It("includes the refund history and net total of every order", func() {
+					orders, err = orderService.FindAllOrdersByUserID(context.Background(), userID)
+					Expect(err).To(BeNil())
+					Expect(len(orders[0].Refunds)).To(Equal(1))
+					Expect(*orders[0].NetTotal).To(Equal(models.NewMoney(2000, models.GBP)))
+					Expect(len(orders[1].Refunds)).To(Equal(0))
+					Expect(*orders[1].NetTotal).To(Equal(models.NewMoney(1000, models.GBP)))
 				})
 			})
 		})
 	})
 
-	AfterEach(func() {
-		ctrl.Finish()
+	Describe("CancelOrder", func() {
+		var (
+			events    models.OutboxEvents
+			entry     *models.OrderAuditEntry
+			order     *models.Order
+			actor     *models.Actor
+			reason    models.CancellationReason
+			cancelled *models.Order
+			version   int
+			err       error
+		)
+
+		BeforeEach(func() {
+			now = time.Date(2019, 4, 10, 12, 0, 0, 0, time.UTC)
+			events = nil
+			entry = nil
+
+			order = &models.Order{
+				ID:           3,
+				UserID:       5,
+				RestaurantID: 9,
+				Total:        models.NewMoney(2500, models.GBP),
+				Status:       models.OrderStatusPlaced,
+				PlacedAt:     now.Add(-5 * time.Minute),
+				Version:      1,
+			}
+			actor = &models.Actor{ID: "user:5"}
+			reason = models.CancellationReasonChangedMind
+			version = 1
+		})
+
+		JustBeforeEach(func() {
+			ctx := tracing.ContextWithRequestID(context.Background(), "req-1")
+			cancelled, err = orderService.CancelOrder(ctx, 3, version, reason, actor)
+		})
+
+		Describe("with an unknown reason code", func() {
+			BeforeEach(func() {
+				reason = "bored"
+			})
+
+			It("rejects the request", func() {
+				Expect(err).To(MatchError(`"bored" is not a valid cancellation reason`))
+				Expect(err.(*services.Error).Kind).To(Equal(services.ErrorKindInvalid))
+			})
+		})
+
+		Describe("when the order does not exist", func() {
+			BeforeEach(func() {
+				orderRepo.EXPECT().FindOrderByIDForUpdate(gomock.Any(), gomock.Eq(3)).Return(nil, repositories.ErrNotFound)
+			})
+
+			It("returns a not found error", func() {
+				Expect(err.(*services.Error).Kind).To(Equal(services.ErrorKindNotFound))
+			})
+		})
+
+		Describe("when the order exists", func() {
+			BeforeEach(func() {
+				orderRepo.EXPECT().FindOrderByIDForUpdate(gomock.Any(), gomock.Eq(3)).Return(order, error(nil))
+			})
+
+			Describe("and the actor is another user", func() {
+				BeforeEach(func() {
+					actor = &models.Actor{ID: "user:6"}
+				})
+
+				It("forbids the cancellation", func() {
+					Expect(err.(*services.Error).Kind).To(Equal(services.ErrorKindForbidden))
+				})
+			})
+
+			Describe("and it has changed since the caller read it", func() {
+				BeforeEach(func() {
+					order.Version = 2
+				})
+
+				It("fails the precondition", func() {
+					serviceErr := err.(*services.Error)
+					Expect(serviceErr.Kind).To(Equal(services.ErrorKindPreconditionFailed))
+					Expect(serviceErr.Details["current_version"]).To(Equal(2))
+				})
+			})
+
+			Describe("and the restaurant has started preparing it", func() {
+				BeforeEach(func() {
+					order.Status = models.OrderStatusPreparing
+				})
+
+				It("refuses to cancel it", func() {
+					Expect(err.(*services.Error).Code).To(Equal("order_not_cancellable"))
+				})
+			})
+
+			Describe("and the cancellation window has passed", func() {
+				BeforeEach(func() {
+					order.PlacedAt = now.Add(-20 * time.Minute)
+				})
+
+				It("returns a structured error with the deadline", func() {
+					serviceErr := err.(*services.Error)
+					Expect(serviceErr.Kind).To(Equal(services.ErrorKindConflict))
+					Expect(serviceErr.Code).To(Equal("cancellation_window_expired"))
+					Expect(serviceErr.Details["cancellable_until"]).To(Equal(now.Add(-5 * time.Minute)))
+				})
+			})
+
+			Describe("and it is still within the window", func() {
+				BeforeEach(func() {
+					orderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Eq(order)).Return(nil)
+					auditRepo.EXPECT().AddEntry(gomock.Any(), gomock.Any()).
+						Do(func(_ context.Context, e *models.OrderAuditEntry) { entry = e }).
+						Return(nil)
+					statusChangeRepo.EXPECT().CreateStatusChange(gomock.Any(), gomock.Eq(&models.OrderStatusChange{
+						OrderID:   3,
+						Status:    models.OrderStatusCancelled,
+						ChangedAt: now,
+					})).Return(nil)
+					outboxRepo.EXPECT().AddEvents(gomock.Any(), gomock.Any()).
+						Do(func(_ context.Context, e models.OutboxEvents) { events = e }).
+						Return(nil)
+					notifier.EXPECT().NotifyOrderEvents(gomock.Any(), gomock.Any()).Return(nil)
+
+					restaurantClient.EXPECT().GetRestaurantsByIDs(gomock.Any(), gomock.Eq([]int{9})).Return(models.Restaurants{{ID: 9, Name: "Nando's"}}, error(nil))
+					itemRepo.EXPECT().FindItemsByOrderIDs(gomock.Any(), gomock.Eq([]int{3})).Return(models.OrderItems{
+						{ID: 4, OrderID: 3, Name: "Chicken wrap", Quantity: 2, UnitPrice: 1250},
+					}, error(nil))
+					statusChangeRepo.EXPECT().FindStatusChangesByOrderIDs(gomock.Any(), gomock.Eq([]int{3})).Return(models.OrderStatusChanges{
+						{ID: 5, OrderID: 3, Status: models.OrderStatusCancelled, ChangedAt: now},
+					}, error(nil))
+					refundRepo.EXPECT().FindRefundsByOrderIDs(gomock.Any(), gomock.Eq([]int{3})).Return(models.Refunds{}, error(nil))
+				})
+
+				It("records who cancelled the order, when and why", func() {
+					Expect(err).To(BeNil())
+					Expect(cancelled.Status).To(Equal(models.OrderStatusCancelled))
+					Expect(*cancelled.CancelledAt).To(Equal(now))
+					Expect(cancelled.CancelledBy).To(Equal("user:5"))
+					Expect(cancelled.CancellationReason).To(Equal(models.CancellationReasonChangedMind))
+				})
+
+				It("returns the order the way FindOrderByID does", func() {
+					Expect(cancelled.Restaurant.Name).To(Equal("Nando's"))
+					Expect(len(cancelled.Items)).To(Equal(1))
+					Expect(len(cancelled.StatusHistory)).To(Equal(1))
+					Expect(cancelled.Refunds).To(BeEmpty())
+					Expect(*cancelled.NetTotal).To(Equal(models.NewMoney(2500, models.GBP)))
+				})
+
+				It("adds an OrderStatusChanged event to the outbox", func() {
+					Expect(len(events)).To(Equal(1))
+					Expect(events[0].Type).To(Equal(models.EventTypeOrderStatusChanged))
+					Expect(events[0].OrderID).To(Equal(3))
+					Expect(events[0].Payload).To(MatchJSON(`{
+						"order_id": 3,
+						"status": "cancelled",
+						"changed_at": "2019-04-10T12:00:00Z",
+						"cancellation_reason": "changed_mind",
+						"order_version": 1
+					}`))
+				})
+
+				It("records the cancellation in the audit log", func() {
+					Expect(entry.OrderID).To(Equal(3))
+					Expect(entry.ActorID).To(Equal("user:5"))
+					Expect(entry.Action).To(Equal(models.OrderAuditActionCancel))
+					Expect(entry.RequestID).To(Equal("req-1"))
+					Expect(entry.CreatedAt).To(Equal(now))
+					Expect(entry.Before).To(MatchJSON(`{
+						"status": "placed",
+						"cancelled_at": null,
+						"cancelled_by": "",
+						"cancellation_reason": ""
+					}`))
+					Expect(entry.After).To(MatchJSON(`{
+						"status": "cancelled",
+						"cancelled_at": "2019-04-10T12:00:00Z",
+						"cancelled_by": "user:5",
+						"cancellation_reason": "changed_mind"
+					}`))
+				})
+
+				It("leaves notifying the restaurant to the outbox", func() {
+					// The restaurant client mock fails the spec if it is called.
+					Expect(err).To(BeNil())
+				})
+			})
+
+			Describe("and the actor is a support agent", func() {
+				BeforeEach(func() {
+					actor = &models.Actor{ID: "agent:1", Scopes: []string{models.ScopeOrdersAdmin}}
+					orderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(nil)
+					auditRepo.EXPECT().AddEntry(gomock.Any(), gomock.Any()).Return(nil)
+					statusChangeRepo.EXPECT().CreateStatusChange(gomock.Any(), gomock.Any()).Return(nil)
+					outboxRepo.EXPECT().AddEvents(gomock.Any(), gomock.Any()).Return(nil)
+					notifier.EXPECT().NotifyOrderEvents(gomock.Any(), gomock.Any()).Return(nil)
+					restaurantClient.EXPECT().GetRestaurantsByIDs(gomock.Any(), gomock.Any()).Return(models.Restaurants{{ID: 9}}, error(nil))
+					itemRepo.EXPECT().FindItemsByOrderIDs(gomock.Any(), gomock.Any()).Return(models.OrderItems{}, error(nil))
+					statusChangeRepo.EXPECT().FindStatusChangesByOrderIDs(gomock.Any(), gomock.Any()).Return(models.OrderStatusChanges{}, error(nil))
+					refundRepo.EXPECT().FindRefundsByOrderIDs(gomock.Any(), gomock.Any()).Return(models.Refunds{}, error(nil))
+				})
+
+				It("allows the cancellation", func() {
+					Expect(err).To(BeNil())
+					Expect(cancelled.CancelledBy).To(Equal("agent:1"))
+				})
+			})
+		})
+	})
+
+	Describe("RefundOrder", func() {
+		var (
+			events   models.OutboxEvents
+			entry    *models.OrderAuditEntry
+			order    *models.Order
+			actor    *models.Actor
+			req      services.RefundRequest
+			refunded *models.Order
+			err      error
+		)
+
+		BeforeEach(func() {
+			now = time.Date(2019, 4, 14, 12, 0, 0, 0, time.UTC)
+			events = nil
+			entry = nil
+
+			order = &models.Order{
+				ID:       3,
+				UserID:   5,
+				Total:    models.NewMoney(2500, models.GBP),
+				PlacedAt: now.Add(-time.Hour),
+			}
+			actor = admin
+			req = services.RefundRequest{Amount: models.NewMoney(1000, models.GBP), Reason: "Missing item"}
+		})
+
+		JustBeforeEach(func() {
+			refunded, err = orderService.RefundOrder(context.Background(), 3, services.AnyVersion, req, actor)
+		})
+
+		Describe("when the actor is not a support agent", func() {
+			BeforeEach(func() {
+				actor = &models.Actor{ID: "user:5"}
+			})
+
+			It("forbids the refund", func() {
+				Expect(err.(*services.Error).Kind).To(Equal(services.ErrorKindForbidden))
+			})
+		})
+
+		Describe("with a non-positive amount", func() {
+			BeforeEach(func() {
+				req.Amount = models.ZeroMoney(models.GBP)
+			})
+
+			It("rejects the refund", func() {
+				Expect(err.(*services.Error).Code).To(Equal("invalid_refund_amount"))
+			})
+		})
+
+		Describe("when the order exists", func() {
+			BeforeEach(func() {
+				orderRepo.EXPECT().FindOrderByIDForUpdate(gomock.Any(), gomock.Eq(3)).Return(order, error(nil))
+			})
+
+			Describe("and the currency does not match", func() {
+				BeforeEach(func() {
+					req.Amount = models.NewMoney(1000, models.EUR)
+				})
+
+				It("rejects the refund", func() {
+					Expect(err.(*services.Error).Code).To(Equal("refund_currency_mismatch"))
+				})
+			})
+
+			Describe("and earlier refunds leave too little to refund", func() {
+				BeforeEach(func() {
+					refundRepo.EXPECT().
+						FindRefundsByOrderIDs(gomock.Any(), gomock.Eq([]int{3})).
+						Return(models.Refunds{{ID: 1, OrderID: 3, Amount: models.NewMoney(2000, models.GBP)}}, error(nil))
+				})
+
+				It("refuses to refund more than the order total", func() {
+					serviceErr := err.(*services.Error)
+					Expect(serviceErr.Code).To(Equal("refund_exceeds_total"))
+					Expect(serviceErr.Details["refundable_amount"]).To(Equal(models.NewMoney(500, models.GBP)))
+				})
+			})
+
+			Describe("and the amount is refundable", func() {
+				BeforeEach(func() {
+					refundRepo.EXPECT().
+						FindRefundsByOrderIDs(gomock.Any(), gomock.Eq([]int{3})).
+						Return(models.Refunds{{ID: 1, OrderID: 3, Amount: models.NewMoney(500, models.GBP)}}, error(nil))
+					refundRepo.EXPECT().CreateRefund(gomock.Any(), gomock.Eq(&models.Refund{
+						OrderID:   3,
+						Amount:    models.NewMoney(1000, models.GBP),
+						Reason:    "Missing item",
+						ActorID:   "agent:1",
+						CreatedAt: now,
+					})).Return(nil)
+					orderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Eq(order)).Return(nil)
+					auditRepo.EXPECT().AddEntry(gomock.Any(), gomock.Any()).
+						Do(func(_ context.Context, e *models.OrderAuditEntry) { entry = e }).
+						Return(nil)
+					outboxRepo.EXPECT().AddEvents(gomock.Any(), gomock.Any()).
+						Do(func(_ context.Context, e models.OutboxEvents) { events = e }).
+						Return(nil)
+					notifier.EXPECT().NotifyOrderEvents(gomock.Any(), gomock.Any()).Return(nil)
+				})
+
+				It("records the refund and returns the net total", func() {
+					Expect(err).To(BeNil())
+					Expect(len(refunded.Refunds)).To(Equal(2))
+					Expect(*refunded.NetTotal).To(Equal(models.NewMoney(1000, models.GBP)))
+				})
+
+				It("adds an OrderRefunded event with the new net total to the outbox", func() {
+					Expect(len(events)).To(Equal(1))
+					Expect(events[0].Type).To(Equal(models.EventTypeOrderRefunded))
+					payload := models.OrderRefundedPayload{}
+					Expect(json.Unmarshal(events[0].Payload, &payload)).To(Succeed())
+					Expect(payload.Amount).To(Equal(models.NewMoney(1000, models.GBP)))
+					Expect(payload.NetTotal).To(Equal(models.NewMoney(1000, models.GBP)))
+				})
+
+				It("records the change to the net total in the audit log", func() {
+					Expect(entry.ActorID).To(Equal("agent:1"))
+					Expect(entry.Action).To(Equal(models.OrderAuditActionRefund))
+					Expect(entry.Before).To(MatchJSON(`{"net_total": {"minor_units": 2000, "currency": "GBP", "decimal": "20.00"}}`))
+					Expect(entry.After).To(MatchJSON(`{"net_total": {"minor_units": 1000, "currency": "GBP", "decimal": "10.00"}}`))
+				})
+			})
+		})
+	})
+
+	Describe("SpendForUser", func() {
+		var (
+			summary *models.SpendSummary
+			err     error
+
+			user     = &models.Actor{ID: "user:5"}
+			placedAt = time.Date(2019, 4, 14, 12, 0, 0, 0, time.UTC)
+		)
+
+		Describe("when the actor is another user", func() {
+			JustBeforeEach(func() {
+				summary, err = orderService.SpendForUser(context.Background(), 5, models.EUR, &models.Actor{ID: "user:6"})
+			})
+
+			It("is forbidden", func() {
+				Expect(summary).To(BeNil())
+				Expect(err.(*services.Error).Kind).To(Equal(services.ErrorKindForbidden))
+			})
+		})
+
+		Describe("when the user has orders in several currencies", func() {
+			BeforeEach(func() {
+				orderRepo.EXPECT().FindAllOrdersByUserID(gomock.Any(), gomock.Eq(5)).Return(models.Orders{
+					{ID: 1, UserID: 5, Total: models.NewMoney(2000, models.GBP), Status: models.OrderStatusDelivered, PlacedAt: placedAt},
+					{ID: 2, UserID: 5, Total: models.NewMoney(900, models.GBP), Status: models.OrderStatusCancelled, PlacedAt: placedAt},
+					{ID: 3, UserID: 5, Total: models.NewMoney(1500, models.EUR), Status: models.OrderStatusDelivered, PlacedAt: placedAt.Add(time.Hour)},
+				}, error(nil))
+				refundRepo.EXPECT().
+					FindRefundsByOrderIDs(gomock.Any(), gomock.Eq([]int{1, 3})).
+					Return(models.Refunds{{ID: 1, OrderID: 1, Amount: models.NewMoney(500, models.GBP)}}, error(nil))
+				exchangeRateService.EXPECT().
+					ConvertAll(gomock.Any(), gomock.Eq([]services.DatedAmount{
+						{Amount: models.NewMoney(1500, models.GBP), At: placedAt},
+						{Amount: models.NewMoney(1500, models.EUR), At: placedAt.Add(time.Hour)},
+					}), gomock.Eq(models.EUR)).
+					Return([]models.Money{models.NewMoney(1800, models.EUR), models.NewMoney(1500, models.EUR)}, error(nil))
+			})
+
+			JustBeforeEach(func() {
+				summary, err = orderService.SpendForUser(context.Background(), 5, models.EUR, user)
+			})
+
+			It("adds up the converted net totals of orders that were not cancelled", func() {
+				Expect(err).To(BeNil())
+				Expect(summary).To(Equal(&models.SpendSummary{
+					UserID:     5,
+					Currency:   models.EUR,
+					Total:      models.NewMoney(3300, models.EUR),
+					OrderCount: 2,
+				}))
+			})
+		})
+
+		Describe("when the user has no orders", func() {
+			BeforeEach(func() {
+				orderRepo.EXPECT().FindAllOrdersByUserID(gomock.Any(), gomock.Eq(5)).Return(models.Orders{}, error(nil))
+			})
+
+			JustBeforeEach(func() {
+				summary, err = orderService.SpendForUser(context.Background(), 5, models.JPY, admin)
+			})
+
+			It("returns a zero total", func() {
+				Expect(err).To(BeNil())
+				Expect(summary.Total).To(Equal(models.ZeroMoney(models.JPY)))
+				Expect(summary.OrderCount).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("StatsForUser", func() {
+		var (
+			stats  *models.OrderStats
+			counts []*models.PeriodCount
+			actor  *models.Actor
+			err    error
+		)
+
+		JustBeforeEach(func() {
+			stats, err = orderService.StatsForUser(context.Background(), 5, models.StatsPeriodMonth, actor)
+		})
+
+		Describe("when the actor is another user", func() {
+			BeforeEach(func() {
+				actor = &models.Actor{ID: "user:6"}
+			})
+
+			It("is forbidden", func() {
+				Expect(stats).To(BeNil())
+				Expect(err.(*services.Error).Kind).To(Equal(services.ErrorKindForbidden))
+			})
+		})
+
+		Describe("when the actor is the user", func() {
+			BeforeEach(func() {
+				actor = &models.Actor{ID: "user:5"}
+
+				counts = []*models.PeriodCount{
+					{Start: time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), OrderCount: 4},
+					{Start: time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC), OrderCount: 3},
+				}
+
+				orderRepo.EXPECT().SpendByCurrencyForUser(gomock.Any(), gomock.Eq(5)).Return([]*models.CurrencySpend{
+					{Currency: models.GBP, OrderCount: 6, Total: models.NewMoney(10000, models.GBP)},
+					{Currency: models.EUR, OrderCount: 1, Total: models.NewMoney(1250, models.EUR)},
+				}, error(nil))
+				orderRepo.EXPECT().RestaurantStatsForUser(gomock.Any(), gomock.Eq(5)).Return([]*models.RestaurantStats{
+					{RestaurantID: 1, OrderCount: 2, Spend: []models.Money{models.NewMoney(2000, models.GBP)}},
+					{RestaurantID: 2, OrderCount: 2, Spend: []models.Money{models.NewMoney(3000, models.GBP)}},
+					{RestaurantID: 3, OrderCount: 3, Spend: []models.Money{models.NewMoney(5000, models.GBP), models.NewMoney(1250, models.EUR)}},
+				}, error(nil))
+				orderRepo.EXPECT().OrderCountsForUser(gomock.Any(), gomock.Eq(5), gomock.Eq(models.StatsPeriodMonth)).Return(counts, error(nil))
+				restaurantClient.EXPECT().GetRestaurantsByIDs(gomock.Any(), gomock.Eq([]int{3, 2, 1})).Return(models.Restaurants{
+					{ID: 2, Name: "Pizza Place"},
+					{ID: 3, Name: "Noodle Bar"},
+				}, error(nil))
+			})
+
+			It("adds up the orders and averages the spend in each currency", func() {
+				Expect(err).To(BeNil())
+				Expect(stats.OrderCount).To(Equal(7))
+				Expect(stats.SpendByCurrency[0].AverageOrderValue).To(Equal(models.NewMoney(1667, models.GBP)))
+				Expect(stats.SpendByCurrency[1].AverageOrderValue).To(Equal(models.NewMoney(1250, models.EUR)))
+				Expect(stats.OrdersPerPeriod).To(Equal(counts))
+			})
+
+			It("ranks restaurants by orders and then by spend", func() {
+				Expect(stats.TopRestaurants[0].RestaurantID).To(Equal(3))
+				Expect(stats.TopRestaurants[0].Restaurant.Name).To(Equal("Noodle Bar"))
+				Expect(stats.TopRestaurants[1].RestaurantID).To(Equal(2))
+				Expect(stats.TopRestaurants[2].RestaurantID).To(Equal(1))
+				Expect(stats.TopRestaurants[2].Restaurant).To(BeNil())
+			})
+		})
+	})
+
+	Describe("FindOrdersForRestaurant", func() {
+		var (
+			filter models.RestaurantOrderFilter
+			actor  *models.Actor
+			page   *models.RestaurantOrderPage
+			err    error
+
+			placedAt = time.Date(2019, 4, 20, 12, 0, 0, 0, time.UTC)
+		)
+
+		BeforeEach(func() {
+			filter = models.RestaurantOrderFilter{RestaurantID: 8, Limit: 2}
+			actor = &models.Actor{ID: "restaurant:8"}
+		})
+
+		JustBeforeEach(func() {
+			page, err = orderService.FindOrdersForRestaurant(context.Background(), filter, actor)
+		})
+
+		Describe("when the actor is another restaurant", func() {
+			BeforeEach(func() {
+				actor = &models.Actor{ID: "restaurant:9"}
+			})
+
+			It("forbids the listing", func() {
+				Expect(err.(*services.Error).Kind).To(Equal(services.ErrorKindForbidden))
+			})
+		})
+
+		Describe("when there are more orders than fit on a page", func() {
+			BeforeEach(func() {
+				expectedFilter := filter
+				expectedFilter.Limit = 3
+				orderRepo.EXPECT().FindOrdersByRestaurant(gomock.Any(), gomock.Eq(expectedFilter)).Return(models.Orders{
+					{ID: 3, UserID: 5, RestaurantID: 8, Total: models.NewMoney(1000, models.GBP), PlacedAt: placedAt, CancelledBy: "user:5"},
+					{ID: 2, UserID: 6, RestaurantID: 8, Total: models.NewMoney(1000, models.GBP), PlacedAt: placedAt.Add(-time.Hour)},
+					{ID: 1, UserID: 7, RestaurantID: 8, Total: models.NewMoney(1000, models.GBP), PlacedAt: placedAt.Add(-2 * time.Hour)},
+				}, error(nil))
+				itemRepo.EXPECT().FindItemsByOrderIDs(gomock.Any(), gomock.Eq([]int{3, 2})).Return(models.OrderItems{}, error(nil))
+				statusChangeRepo.EXPECT().FindStatusChangesByOrderIDs(gomock.Any(), gomock.Eq([]int{3, 2})).Return(models.OrderStatusChanges{}, error(nil))
+				refundRepo.EXPECT().FindRefundsByOrderIDs(gomock.Any(), gomock.Eq([]int{3, 2})).Return(models.Refunds{}, error(nil))
+			})
+
+			It("returns a page with a cursor after its last order", func() {
+				Expect(err).To(BeNil())
+				Expect(len(page.Orders)).To(Equal(2))
+
+				cursor, err := models.ParseOrderCursor(page.NextCursor)
+				Expect(err).To(BeNil())
+				Expect(cursor).To(Equal(&models.OrderCursor{PlacedAt: placedAt.Add(-time.Hour), ID: 2}))
+			})
+
+			It("hides the customers", func() {
+				Expect(page.Orders[0].UserID).To(BeNil())
+				Expect(page.Orders[0].CancelledBy).To(BeEmpty())
+			})
+
+			Describe("and the actor may see customer details", func() {
+				BeforeEach(func() {
+					actor.Scopes = []string{models.ScopeOrdersCustomerDetails}
+				})
+
+				It("shows the customers", func() {
+					Expect(*page.Orders[0].UserID).To(Equal(5))
+					Expect(page.Orders[0].CancelledBy).To(Equal("user:5"))
+				})
+			})
+		})
+
+		Describe("when the last page is reached", func() {
+			BeforeEach(func() {
+				orderRepo.EXPECT().FindOrdersByRestaurant(gomock.Any(), gomock.Any()).Return(models.Orders{}, error(nil))
+			})
+
+			It("returns an empty page without a cursor", func() {
+				Expect(err).To(BeNil())
+				Expect(page.Orders).To(BeEmpty())
+				Expect(page.NextCursor).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("FindOrderByID", func() {
+		var (
+			actor *models.Actor
+			found *models.Order
+			err   error
+		)
+
+		BeforeEach(func() {
+			orderRepo.EXPECT().FindOrderByID(gomock.Any(), gomock.Eq(3)).Return(&models.Order{
+				ID:           3,
+				UserID:       5,
+				RestaurantID: 9,
+				Total:        models.NewMoney(1000, models.GBP),
+				Version:      2,
+			}, error(nil))
+		})
+
+		JustBeforeEach(func() {
+			found, err = orderService.FindOrderByID(context.Background(), 3, actor)
+		})
+
+		Describe("when the actor is another user", func() {
+			BeforeEach(func() {
+				actor = &models.Actor{ID: "user:6"}
+			})
+
+			It("reports the order as missing", func() {
+				Expect(err.(*services.Error).Kind).To(Equal(services.ErrorKindNotFound))
+			})
+		})
+
+		Describe("when the actor placed the order", func() {
+			BeforeEach(func() {
+				actor = &models.Actor{ID: "user:5"}
+				restaurantClient.EXPECT().GetRestaurantsByIDs(gomock.Any(), gomock.Eq([]int{9})).Return(models.Restaurants{{ID: 9, Name: "Nando's"}}, error(nil))
+				itemRepo.EXPECT().FindItemsByOrderIDs(gomock.Any(), gomock.Eq([]int{3})).Return(models.OrderItems{}, error(nil))
+				statusChangeRepo.EXPECT().FindStatusChangesByOrderIDs(gomock.Any(), gomock.Eq([]int{3})).Return(models.OrderStatusChanges{}, error(nil))
+				refundRepo.EXPECT().FindRefundsByOrderIDs(gomock.Any(), gomock.Eq([]int{3})).Return(models.Refunds{}, error(nil))
+			})
+
+			It("returns the enriched order", func() {
+				Expect(err).To(BeNil())
+				Expect(found.Restaurant.Name).To(Equal("Nando's"))
+				Expect(found.Version).To(Equal(2))
+			})
+		})
+	})
+
+	Describe("FindOrderEventsForUser", func() {
+		BeforeEach(func() {
+			now = time.Date(2019, 5, 8, 12, 0, 0, 0, time.UTC)
+		})
+
+		It("forbids other users", func() {
+			_, err := orderService.FindOrderEventsForUser(context.Background(), 5, 6, &models.Actor{ID: "user:6"})
+			Expect(err.(*services.Error).Kind).To(Equal(services.ErrorKindForbidden))
+		})
+
+		It("has nothing to replay to a caller that has seen no events", func() {
+			events, err := orderService.FindOrderEventsForUser(context.Background(), 5, 0, &models.Actor{ID: "user:5"})
+			Expect(err).To(BeNil())
+			Expect(events).To(BeEmpty())
+		})
+
+		It("replays the events of the last day after the last one seen", func() {
+			missed := models.OutboxEvents{{ID: 7, UserID: 5}}
+			outboxRepo.EXPECT().
+				FindEventsForUser(gomock.Any(), gomock.Eq(5), gomock.Eq(int64(6)), gomock.Eq(now.Add(-24*time.Hour)), gomock.Eq(500)).
+				Return(missed, error(nil))
+
+			events, err := orderService.FindOrderEventsForUser(context.Background(), 5, 6, &models.Actor{ID: "user:5"})
+			Expect(err).To(BeNil())
+			Expect(events).To(Equal(missed))
+		})
+	})
+
+	Describe("DeleteOrder", func() {
+		BeforeEach(func() {
+			now = time.Date(2019, 5, 10, 12, 0, 0, 0, time.UTC)
+		})
+
+		It("forbids actors that are not support agents", func() {
+			err := orderService.DeleteOrder(context.Background(), 3, 1, &models.Actor{ID: "user:5"})
+			Expect(err.(*services.Error).Kind).To(Equal(services.ErrorKindForbidden))
+		})
+
+		It("reports a missing order", func() {
+			orderRepo.EXPECT().FindOrderByIDForUpdate(gomock.Any(), gomock.Eq(3)).Return(nil, repositories.ErrNotFound)
+
+			err := orderService.DeleteOrder(context.Background(), 3, 1, admin)
+			Expect(err.(*services.Error).Code).To(Equal("order_not_found"))
+		})
+
+		It("refuses to delete an order that has changed", func() {
+			orderRepo.EXPECT().FindOrderByIDForUpdate(gomock.Any(), gomock.Eq(3)).Return(&models.Order{ID: 3, Version: 2}, error(nil))
+
+			err := orderService.DeleteOrder(context.Background(), 3, 1, admin)
+			Expect(err.(*services.Error).Code).To(Equal("order_version_mismatch"))
+		})
+
+		It("marks the order as deleted", func() {
+			orderRepo.EXPECT().FindOrderByIDForUpdate(gomock.Any(), gomock.Eq(3)).Return(&models.Order{ID: 3, Version: 1}, error(nil))
+			orderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Eq(&models.Order{ID: 3, Version: 1, DeletedAt: now})).Return(error(nil))
+			auditRepo.EXPECT().AddEntry(gomock.Any(), gomock.Any()).
+				Do(func(_ context.Context, e *models.OrderAuditEntry) {
+					Expect(e.Action).To(Equal(models.OrderAuditActionDelete))
+					Expect(e.Before).To(MatchJSON(`{"deleted_at": null}`))
+					Expect(e.After).To(MatchJSON(`{"deleted_at": "2019-05-10T12:00:00Z"}`))
+				}).
+				Return(nil)
+
+			Expect(orderService.DeleteOrder(context.Background(), 3, 1, admin)).To(Succeed())
+		})
+
+		It("fails when the deletion cannot be audited, so that it is rolled back", func() {
+			orderRepo.EXPECT().FindOrderByIDForUpdate(gomock.Any(), gomock.Eq(3)).Return(&models.Order{ID: 3, Version: 1}, error(nil))
+			orderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(error(nil))
+			auditRepo.EXPECT().AddEntry(gomock.Any(), gomock.Any()).Return(errors.New("connection reset"))
+
+			Expect(orderService.DeleteOrder(context.Background(), 3, 1, admin)).To(MatchError("connection reset"))
+		})
+	})
+
+	Describe("FindOrderAudit", func() {
+		It("forbids actors that are not support agents", func() {
+			_, err := orderService.FindOrderAudit(context.Background(), 3, &models.Actor{ID: "user:5"})
+			Expect(err.(*services.Error).Kind).To(Equal(services.ErrorKindForbidden))
+		})
+
+		It("returns the audit log of the order", func() {
+			entries := models.OrderAuditEntries{{ID: 1, OrderID: 3, Action: models.OrderAuditActionDelete}}
+			auditRepo.EXPECT().FindEntriesByOrderID(gomock.Any(), gomock.Eq(3)).Return(entries, error(nil))
+
+			found, err := orderService.FindOrderAudit(context.Background(), 3, admin)
+			Expect(err).To(BeNil())
+			Expect(found).To(Equal(entries))
+		})
+
+		It("returns an empty log for an order that has never changed", func() {
+			auditRepo.EXPECT().FindEntriesByOrderID(gomock.Any(), gomock.Eq(3)).Return(models.OrderAuditEntries{}, error(nil))
+			orderRepo.EXPECT().FindOrderByID(gomock.Any(), gomock.Eq(3)).Return(&models.Order{ID: 3}, error(nil))
+
+			found, err := orderService.FindOrderAudit(context.Background(), 3, admin)
+			Expect(err).To(BeNil())
+			Expect(found).To(BeEmpty())
+		})
+
+		It("reports a missing order", func() {
+			auditRepo.EXPECT().FindEntriesByOrderID(gomock.Any(), gomock.Eq(3)).Return(models.OrderAuditEntries{}, error(nil))
+			orderRepo.EXPECT().FindOrderByID(gomock.Any(), gomock.Eq(3)).Return(nil, repositories.ErrNotFound)
+
+			_, err := orderService.FindOrderAudit(context.Background(), 3, admin)
+			Expect(err.(*services.Error).Code).To(Equal("order_not_found"))
+		})
 	})
 })
+
+// passThroughTransactor returns a Transactor mock that runs the function it is
+// given without a database transaction.
+func passThroughTransactor(ctrl *gomock.Controller) *mock_repositories.MockTransactor {
+	transactorMock := mock_repositories.NewMockTransactor(ctrl)
+	transactorMock.EXPECT().
+		RunInTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).
+		AnyTimes()
+	return transactorMock
+}
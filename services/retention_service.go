@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	"github.com/pkg/errors"
+)
+
+// defaultRetentionBatchSize is the number of orders handled in each
+// transaction, unless ORDER_RETENTION_BATCH_SIZE says otherwise.
+const defaultRetentionBatchSize = 500
+
+// RetentionService represents the business-logic layer for the retention of
+// orders.
+type RetentionService interface {
+	ApplyRetention(ctx context.Context, dryRun bool) (*models.RetentionReport, error)
+}
+
+// NewRetentionService creates a retention service.
+func NewRetentionService() *retentionService {
+	return &retentionService{}
+}
+
+type retentionService struct {
+	transactor          repositories.Transactor
+	retentionRepository repositories.RetentionRepository
+	policy              *models.RetentionPolicy
+	now                 func() time.Time
+}
+
+func (s *retentionService) SetTransactor(t repositories.Transactor) {
+	s.transactor = t
+}
+
+func (s *retentionService) getTransactor() repositories.Transactor {
+	if s.transactor != nil {
+		return s.transactor
+	}
+
+	s.transactor = repositories.NewTransactor(application.ResolveDB())
+	return s.transactor
+}
+
+func (s *retentionService) SetRetentionRepository(r repositories.RetentionRepository) {
+	s.retentionRepository = r
+}
+
+func (s *retentionService) getRetentionRepository() repositories.RetentionRepository {
+	if s.retentionRepository != nil {
+		return s.retentionRepository
+	}
+
+	s.retentionRepository = repositories.NewRetentionRepository(application.ResolveDB())
+	return s.retentionRepository
+}
+
+// SetPolicy overrides the retention policy read from the environment.
+func (s *retentionService) SetPolicy(p models.RetentionPolicy) {
+	s.policy = &p
+}
+
+// getPolicy returns the retention policy. Unless one was set, it is read from
+// ORDER_RETENTION_YEARS, ORDER_RETENTION_ACTION and
+// ORDER_RETENTION_BATCH_SIZE. There is no default retention period, so that
+// orders are never removed by accident.
+func (s *retentionService) getPolicy() (models.RetentionPolicy, error) {
+	if s.policy != nil {
+		return *s.policy, nil
+	}
+
+	years, err := strconv.Atoi(os.Getenv("ORDER_RETENTION_YEARS"))
+	if err != nil || years < 1 {
+		return models.RetentionPolicy{}, errors.New("ORDER_RETENTION_YEARS must be a whole number of years of at least 1")
+	}
+
+	action := models.RetentionAction(os.Getenv("ORDER_RETENTION_ACTION"))
+	if action == "" {
+		action = models.RetentionActionAnonymise
+	}
+	if !action.IsValid() {
+		return models.RetentionPolicy{}, errors.Errorf("ORDER_RETENTION_ACTION must be %q or %q", models.RetentionActionAnonymise, models.RetentionActionDelete)
+	}
+
+	batchSize := defaultRetentionBatchSize
+	if n, err := strconv.Atoi(os.Getenv("ORDER_RETENTION_BATCH_SIZE")); err == nil && n > 0 {
+		batchSize = n
+	}
+
+	s.SetPolicy(models.RetentionPolicy{Years: years, Action: action, BatchSize: batchSize})
+	return *s.policy, nil
+}
+
+// SetClock overrides the function used to tell the current time.
+func (s *retentionService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+func (s *retentionService) getClock() func() time.Time {
+	if s.now != nil {
+		return s.now
+	}
+
+	s.now = time.Now
+	return s.now
+}
+
+// ApplyRetention anonymises or deletes, as the policy says, every order
+// placed longer ago than the retention period. Orders are handled in batches
+// of their own transactions, so a run that fails part of the way through
+// keeps the batches that completed and the next run carries on from there.
+// A dry run only counts the orders that would be handled.
+func (s *retentionService) ApplyRetention(ctx context.Context, dryRun bool) (*models.RetentionReport, error) {
+	ctx, span := tracing.StartSpan(ctx, "RetentionService.ApplyRetention")
+	defer span.End()
+
+	policy, err := s.getPolicy()
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	now := s.getClock()()
+	report := &models.RetentionReport{
+		Action: policy.Action,
+		Cutoff: policy.Cutoff(now),
+		DryRun: dryRun,
+	}
+	span.SetAttribute("retention.action", string(report.Action))
+	span.SetAttribute("retention.dry_run", dryRun)
+
+	if dryRun {
+		report.Orders, err = s.getRetentionRepository().CountExpiredOrders(ctx, report.Cutoff, policy.Action)
+		if err != nil {
+			span.SetError(err)
+			return nil, err
+		}
+
+		return report, nil
+	}
+
+	for {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+
+		var n int
+		err = s.getTransactor().RunInTransaction(ctx, func(ctx context.Context) error {
+			ids, err := s.getRetentionRepository().ClaimExpiredOrderIDs(ctx, report.Cutoff, policy.Action, policy.BatchSize)
+			if err != nil || len(ids) == 0 {
+				return err
+			}
+
+			n = len(ids)
+			if policy.Action == models.RetentionActionDelete {
+				return s.getRetentionRepository().PurgeOrders(ctx, ids)
+			}
+			return s.getRetentionRepository().AnonymiseOrders(ctx, ids, now)
+		})
+		if err != nil || n == 0 {
+			break
+		}
+
+		report.Orders += n
+		report.Batches++
+		if n < policy.BatchSize {
+			break
+		}
+	}
+
+	span.SetAttribute("retention.orders", report.Orders)
+	if err != nil {
+		span.SetError(err)
+		return report, err
+	}
+
+	return report, nil
+}
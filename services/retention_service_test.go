@@ -0,0 +1,120 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RetentionService", func() {
+	var (
+		ctrl          *gomock.Controller
+		retentionRepo *mock_repositories.MockRetentionRepository
+		policy        models.RetentionPolicy
+		report        *models.RetentionReport
+		dryRun        bool
+		err           error
+
+		now    = time.Date(2019, 5, 10, 12, 0, 0, 0, time.UTC)
+		cutoff = time.Date(2012, 5, 10, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		retentionRepo = mock_repositories.NewMockRetentionRepository(ctrl)
+		policy = models.RetentionPolicy{Years: 7, Action: models.RetentionActionAnonymise, BatchSize: 2}
+		dryRun = false
+	})
+
+	JustBeforeEach(func() {
+		retentionServiceImpl := services.NewRetentionService()
+		retentionServiceImpl.SetTransactor(passThroughTransactor(ctrl))
+		retentionServiceImpl.SetRetentionRepository(retentionRepo)
+		retentionServiceImpl.SetPolicy(policy)
+		retentionServiceImpl.SetClock(func() time.Time { return now })
+
+		report, err = retentionServiceImpl.ApplyRetention(context.Background(), dryRun)
+	})
+
+	Describe("ApplyRetention", func() {
+		Describe("in a dry run", func() {
+			BeforeEach(func() {
+				dryRun = true
+				retentionRepo.EXPECT().CountExpiredOrders(gomock.Any(), gomock.Eq(cutoff), gomock.Eq(models.RetentionActionAnonymise)).Return(5, error(nil))
+			})
+
+			It("counts the orders without changing them", func() {
+				Expect(err).To(BeNil())
+				Expect(report).To(Equal(&models.RetentionReport{
+					Action: models.RetentionActionAnonymise,
+					Cutoff: cutoff,
+					DryRun: true,
+					Orders: 5,
+				}))
+			})
+		})
+
+		Describe("when anonymising", func() {
+			BeforeEach(func() {
+				gomock.InOrder(
+					retentionRepo.EXPECT().ClaimExpiredOrderIDs(gomock.Any(), gomock.Eq(cutoff), gomock.Eq(models.RetentionActionAnonymise), gomock.Eq(2)).Return([]int{1, 2}, error(nil)),
+					retentionRepo.EXPECT().AnonymiseOrders(gomock.Any(), gomock.Eq([]int{1, 2}), gomock.Eq(now)).Return(error(nil)),
+					retentionRepo.EXPECT().ClaimExpiredOrderIDs(gomock.Any(), gomock.Eq(cutoff), gomock.Eq(models.RetentionActionAnonymise), gomock.Eq(2)).Return([]int{3}, error(nil)),
+					retentionRepo.EXPECT().AnonymiseOrders(gomock.Any(), gomock.Eq([]int{3}), gomock.Eq(now)).Return(error(nil)),
+				)
+			})
+
+			It("works through the orders in batches until a batch is short", func() {
+				Expect(err).To(BeNil())
+				Expect(report.Orders).To(Equal(3))
+				Expect(report.Batches).To(Equal(2))
+			})
+		})
+
+		Describe("when deleting", func() {
+			BeforeEach(func() {
+				policy.Action = models.RetentionActionDelete
+				gomock.InOrder(
+					retentionRepo.EXPECT().ClaimExpiredOrderIDs(gomock.Any(), gomock.Eq(cutoff), gomock.Eq(models.RetentionActionDelete), gomock.Eq(2)).Return([]int{1, 2}, error(nil)),
+					retentionRepo.EXPECT().PurgeOrders(gomock.Any(), gomock.Eq([]int{1, 2})).Return(error(nil)),
+					retentionRepo.EXPECT().ClaimExpiredOrderIDs(gomock.Any(), gomock.Eq(cutoff), gomock.Eq(models.RetentionActionDelete), gomock.Eq(2)).Return([]int{}, error(nil)),
+				)
+			})
+
+			It("stops when there is nothing left to delete", func() {
+				Expect(err).To(BeNil())
+				Expect(report.Orders).To(Equal(2))
+				Expect(report.Batches).To(Equal(1))
+			})
+		})
+
+		Describe("when a batch fails", func() {
+			BeforeEach(func() {
+				gomock.InOrder(
+					retentionRepo.EXPECT().ClaimExpiredOrderIDs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]int{1, 2}, error(nil)),
+					retentionRepo.EXPECT().AnonymiseOrders(gomock.Any(), gomock.Any(), gomock.Any()).Return(error(nil)),
+					retentionRepo.EXPECT().ClaimExpiredOrderIDs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]int{3, 4}, error(nil)),
+					retentionRepo.EXPECT().AnonymiseOrders(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("boom")),
+				)
+			})
+
+			It("reports the batches that completed with the error", func() {
+				Expect(err).To(MatchError("boom"))
+				Expect(report.Orders).To(Equal(2))
+				Expect(report.Batches).To(Equal(1))
+			})
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
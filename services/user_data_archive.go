@@ -0,0 +1,269 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+)
+
+// The files of a user data archive that are not data files.
+const (
+	userDataManifestName  = "manifest.json"
+	userDataChecksumsName = "SHA256SUMS"
+)
+
+// userDataManifest describes the files of a user data archive.
+type userDataManifest struct {
+	UserID      int                    `json:"user_id"`
+	GeneratedAt time.Time              `json:"generated_at"`
+	Files       []userDataManifestFile `json:"files"`
+}
+
+// userDataManifestFile is a file in the manifest of a user data archive.
+type userDataManifestFile struct {
+	Name    string `json:"name"`
+	Records int    `json:"records"`
+	Bytes   int    `json:"bytes"`
+	SHA256  string `json:"sha256"`
+}
+
+// archiveFile is a file to be written to a ZIP archive.
+type archiveFile struct {
+	name    string
+	records int
+	data    []byte
+}
+
+// exportedOrder is an order as it appears in orders.json. Unlike the API
+// representation it includes the restaurant ID and whether the order was
+// deleted.
+type exportedOrder struct {
+	ID                 int                       `json:"id"`
+	RestaurantID       int                       `json:"restaurant_id"`
+	RestaurantName     string                    `json:"restaurant_name,omitempty"`
+	Status             models.OrderStatus        `json:"status"`
+	Total              models.Money              `json:"total"`
+	NetTotal           *models.Money             `json:"net_total,omitempty"`
+	PlacedAt           time.Time                 `json:"placed_at"`
+	CancelledAt        *time.Time                `json:"cancelled_at,omitempty"`
+	CancelledBy        string                    `json:"cancelled_by,omitempty"`
+	CancellationReason models.CancellationReason `json:"cancellation_reason,omitempty"`
+	DeletedAt          *time.Time                `json:"deleted_at,omitempty"`
+	Items              models.OrderItems         `json:"items"`
+	StatusHistory      models.OrderStatusChanges `json:"status_history"`
+	Refunds            models.Refunds            `json:"refunds"`
+}
+
+func newExportedOrder(order *models.Order) *exportedOrder {
+	exported := &exportedOrder{
+		ID:                 order.ID,
+		RestaurantID:       order.RestaurantID,
+		Status:             order.Status,
+		Total:              order.Total,
+		NetTotal:           order.NetTotal,
+		PlacedAt:           order.PlacedAt,
+		CancelledAt:        order.CancelledAt,
+		CancelledBy:        order.CancelledBy,
+		CancellationReason: order.CancellationReason,
+		Items:              order.Items,
+		StatusHistory:      order.StatusHistory,
+		Refunds:            order.Refunds,
+	}
+	if order.Restaurant != nil {
+		exported.RestaurantName = order.Restaurant.Name
+	}
+	if !order.DeletedAt.IsZero() {
+		deletedAt := order.DeletedAt
+		exported.DeletedAt = &deletedAt
+	}
+
+	return exported
+}
+
+// WriteUserDataArchive writes export to w as a ZIP archive. The archive holds
+// the orders and webhook deliveries as JSON, the orders, items, status history
+// and refunds as CSV, a manifest.json listing every file with its number of
+// records, size and SHA-256 checksum, and a SHA256SUMS file in the format of
+// sha256sum that covers the manifest too.
+func WriteUserDataArchive(w io.Writer, export *models.UserDataExport) error {
+	files, err := userDataFiles(export)
+	if err != nil {
+		return err
+	}
+
+	manifest := userDataManifest{UserID: export.UserID, GeneratedAt: export.GeneratedAt, Files: []userDataManifestFile{}}
+	for _, f := range files {
+		manifest.Files = append(manifest.Files, userDataManifestFile{
+			Name:    f.name,
+			Records: f.records,
+			Bytes:   len(f.data),
+			SHA256:  sha256Hex(f.data),
+		})
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	files = append(files, archiveFile{name: userDataManifestName, data: manifestData})
+
+	checksums := &bytes.Buffer{}
+	for _, f := range files {
+		fmt.Fprintf(checksums, "%s  %s\n", sha256Hex(f.data), f.name)
+	}
+	files = append(files, archiveFile{name: userDataChecksumsName, data: checksums.Bytes()})
+
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     f.name,
+			Method:   zip.Deflate,
+			Modified: export.GeneratedAt,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = fw.Write(f.data); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// userDataFiles returns the data files of a user data archive.
+func userDataFiles(export *models.UserDataExport) ([]archiveFile, error) {
+	exported := make([]*exportedOrder, 0, len(export.Orders))
+	for _, order := range export.Orders {
+		exported = append(exported, newExportedOrder(order))
+	}
+
+	ordersJSON, err := json.MarshalIndent(exported, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := export.WebhookDeliveries
+	if deliveries == nil {
+		deliveries = models.WebhookDeliveries{}
+	}
+	deliveriesJSON, err := json.MarshalIndent(deliveries, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	orderRows := [][]string{{
+		"id", "restaurant_id", "restaurant_name", "status", "currency", "total", "net_total",
+		"placed_at", "cancelled_at", "cancelled_by", "cancellation_reason", "deleted_at",
+	}}
+	itemRows := [][]string{{"order_id", "name", "quantity", "unit_price"}}
+	statusRows := [][]string{{"order_id", "status", "changed_at"}}
+	refundRows := [][]string{{"order_id", "refund_id", "currency", "amount", "reason", "actor_id", "created_at"}}
+
+	for _, order := range exported {
+		netTotal := ""
+		if order.NetTotal != nil {
+			netTotal = order.NetTotal.Decimal()
+		}
+		orderRows = append(orderRows, []string{
+			strconv.Itoa(order.ID),
+			strconv.Itoa(order.RestaurantID),
+			order.RestaurantName,
+			string(order.Status),
+			string(order.Total.Currency),
+			order.Total.Decimal(),
+			netTotal,
+			formatCSVTime(&order.PlacedAt),
+			formatCSVTime(order.CancelledAt),
+			order.CancelledBy,
+			string(order.CancellationReason),
+			formatCSVTime(order.DeletedAt),
+		})
+
+		for _, item := range order.Items {
+			itemRows = append(itemRows, []string{
+				strconv.Itoa(order.ID),
+				item.Name,
+				strconv.Itoa(item.Quantity),
+				models.NewMoney(item.UnitPrice, order.Total.Currency).Decimal(),
+			})
+		}
+
+		for _, change := range order.StatusHistory {
+			statusRows = append(statusRows, []string{
+				strconv.Itoa(order.ID),
+				string(change.Status),
+				formatCSVTime(&change.ChangedAt),
+			})
+		}
+
+		for _, refund := range order.Refunds {
+			refundRows = append(refundRows, []string{
+				strconv.Itoa(order.ID),
+				strconv.Itoa(refund.ID),
+				string(refund.Amount.Currency),
+				refund.Amount.Decimal(),
+				refund.Reason,
+				refund.ActorID,
+				formatCSVTime(&refund.CreatedAt),
+			})
+		}
+	}
+
+	files := []archiveFile{
+		{name: "orders.json", records: len(exported), data: ordersJSON},
+		{name: "webhook_deliveries.json", records: len(deliveries), data: deliveriesJSON},
+	}
+	for _, table := range []struct {
+		name string
+		rows [][]string
+	}{
+		{"orders.csv", orderRows},
+		{"order_items.csv", itemRows},
+		{"status_history.csv", statusRows},
+		{"refunds.csv", refundRows},
+	} {
+		data, err := encodeCSV(table.rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, archiveFile{name: table.name, records: len(table.rows) - 1, data: data})
+	}
+
+	return files, nil
+}
+
+// encodeCSV encodes rows, the first of which is the header, as CSV.
+func encodeCSV(rows [][]string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	cw := csv.NewWriter(buf)
+	if err := cw.WriteAll(rows); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// formatCSVTime formats t in UTC as RFC 3339, or as an empty cell when t is
+// nil.
+func formatCSVTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
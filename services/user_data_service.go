@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/clients/restaurant"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	"github.com/pkg/errors"
+)
+
+// UserDataService represents the business-logic layer for requests that data
+// subjects make about the data stored about them.
+type UserDataService interface {
+	ExportUserData(ctx context.Context, userID int) (*models.UserDataExport, error)
+	EraseUserData(ctx context.Context, userID int, actor *models.Actor) (*models.UserErasure, bool, error)
+	VerifyErasure(ctx context.Context, userID int) (*models.ErasureVerification, error)
+}
+
+// NewUserDataService creates a user data service.
+func NewUserDataService() *userDataService {
+	return &userDataService{}
+}
+
+type userDataService struct {
+	transactor                  repositories.Transactor
+	restaurantClient            restaurant.Client
+	orderRepository             repositories.OrderRepository
+	orderItemRepository         repositories.OrderItemRepository
+	orderStatusChangeRepository repositories.OrderStatusChangeRepository
+	refundRepository            repositories.RefundRepository
+	webhookRepository           repositories.WebhookRepository
+	retentionRepository         repositories.RetentionRepository
+	userErasureRepository       repositories.UserErasureRepository
+	now                         func() time.Time
+}
+
+func (s *userDataService) SetTransactor(t repositories.Transactor) {
+	s.transactor = t
+}
+
+func (s *userDataService) getTransactor() repositories.Transactor {
+	if s.transactor != nil {
+		return s.transactor
+	}
+
+	s.transactor = repositories.NewTransactor(application.ResolveDB())
+	return s.transactor
+}
+
+func (s *userDataService) SetRestaurantClient(c restaurant.Client) {
+	s.restaurantClient = c
+}
+
+func (s *userDataService) getRestaurantClient() restaurant.Client {
+	if s.restaurantClient != nil {
+		return s.restaurantClient
+	}
+
+	s.restaurantClient = restaurant.NewClient()
+	return s.restaurantClient
+}
+
+func (s *userDataService) SetOrderRepository(r repositories.OrderRepository) {
+	s.orderRepository = r
+}
+
+func (s *userDataService) getOrderRepository() repositories.OrderRepository {
+	if s.orderRepository != nil {
+		return s.orderRepository
+	}
+
+	s.orderRepository = repositories.NewOrderRepository(application.ResolveDB())
+	return s.orderRepository
+}
+
+func (s *userDataService) SetOrderItemRepository(r repositories.OrderItemRepository) {
+	s.orderItemRepository = r
+}
+
+func (s *userDataService) getOrderItemRepository() repositories.OrderItemRepository {
+	if s.orderItemRepository != nil {
+		return s.orderItemRepository
+	}
+
+	s.orderItemRepository = repositories.NewOrderItemRepository(application.ResolveDB())
+	return s.orderItemRepository
+}
+
+func (s *userDataService) SetOrderStatusChangeRepository(r repositories.OrderStatusChangeRepository) {
+	s.orderStatusChangeRepository = r
+}
+
+func (s *userDataService) getOrderStatusChangeRepository() repositories.OrderStatusChangeRepository {
+	if s.orderStatusChangeRepository != nil {
+		return s.orderStatusChangeRepository
+	}
+
+	s.orderStatusChangeRepository = repositories.NewOrderStatusChangeRepository(application.ResolveDB())
+	return s.orderStatusChangeRepository
+}
+
+func (s *userDataService) SetRefundRepository(r repositories.RefundRepository) {
+	s.refundRepository = r
+}
+
+func (s *userDataService) getRefundRepository() repositories.RefundRepository {
+	if s.refundRepository != nil {
+		return s.refundRepository
+	}
+
+	s.refundRepository = repositories.NewRefundRepository(application.ResolveDB())
+	return s.refundRepository
+}
+
+func (s *userDataService) SetWebhookRepository(r repositories.WebhookRepository) {
+	s.webhookRepository = r
+}
+
+func (s *userDataService) getWebhookRepository() repositories.WebhookRepository {
+	if s.webhookRepository != nil {
+		return s.webhookRepository
+	}
+
+	s.webhookRepository = repositories.NewWebhookRepository(application.ResolveDB())
+	return s.webhookRepository
+}
+
+func (s *userDataService) SetRetentionRepository(r repositories.RetentionRepository) {
+	s.retentionRepository = r
+}
+
+func (s *userDataService) getRetentionRepository() repositories.RetentionRepository {
+	if s.retentionRepository != nil {
+		return s.retentionRepository
+	}
+
+	s.retentionRepository = repositories.NewRetentionRepository(application.ResolveDB())
+	return s.retentionRepository
+}
+
+func (s *userDataService) SetUserErasureRepository(r repositories.UserErasureRepository) {
+	s.userErasureRepository = r
+}
+
+func (s *userDataService) getUserErasureRepository() repositories.UserErasureRepository {
+	if s.userErasureRepository != nil {
+		return s.userErasureRepository
+	}
+
+	s.userErasureRepository = repositories.NewUserErasureRepository(application.ResolveDB())
+	return s.userErasureRepository
+}
+
+// SetClock overrides the function used to tell the current time.
+func (s *userDataService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+func (s *userDataService) getClock() func() time.Time {
+	if s.now != nil {
+		return s.now
+	}
+
+	s.now = time.Now
+	return s.now
+}
+
+// ExportUserData gathers everything stored about a user: their orders,
+// including deleted ones, with the orders' restaurants, items, status
+// history and refunds, and the webhook deliveries of events about the
+// orders. Orders whose restaurant no longer exists are exported without one
+// rather than failing the export.
+func (s *userDataService) ExportUserData(ctx context.Context, userID int) (*models.UserDataExport, error) {
+	ctx, span := tracing.StartSpan(ctx, "UserDataService.ExportUserData")
+	defer span.End()
+
+	orders, err := s.getOrderRepository().FindAllOrdersByUserIDWithDeleted(ctx, userID)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	span.SetAttribute("export.orders", len(orders))
+
+	err = NewEnrichmentPipeline(defaultEnrichmentConcurrency).
+		Add("restaurants", &restaurantEnricher{client: s.getRestaurantClient(), allowMissing: true}).
+		Add("items", &itemsEnricher{repository: s.getOrderItemRepository()}).
+		Add("status_history", &statusHistoryEnricher{repository: s.getOrderStatusChangeRepository()}).
+		Add("refunds", &refundsEnricher{repository: s.getRefundRepository()}).
+		Run(ctx, orders)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	deliveries, err := s.getWebhookRepository().FindDeliveriesForUser(ctx, userID)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	return &models.UserDataExport{
+		UserID:            userID,
+		GeneratedAt:       s.getClock()(),
+		Orders:            orders,
+		WebhookDeliveries: deliveries,
+	}, nil
+}
+
+// EraseUserData anonymises all of a user's orders, deleted or not, at the
+// user's request. The orders and everything recorded about them are scrubbed
+// of personal data the same way the retention job anonymises old orders, so
+// the user ID is replaced with models.AnonymousUserID and financial totals
+// are kept for accounting. In the same transaction the erasure is verified,
+// and recorded as a models.UserErasure. Only support agents may erase data.
+//
+// Erasing is idempotent: when the user has no orders left and their data has
+// already been erased, the earlier erasure is returned and nothing changes.
+// The boolean result reports whether a new erasure was recorded.
+func (s *userDataService) EraseUserData(ctx context.Context, userID int, actor *models.Actor) (*models.UserErasure, bool, error) {
+	ctx, span := tracing.StartSpan(ctx, "UserDataService.EraseUserData")
+	defer span.End()
+
+	if !actor.HasScope(models.ScopeOrdersAdmin) {
+		err := newError(ErrorKindForbidden, "forbidden", "not allowed to erase the data of user %d", userID)
+		span.SetError(err)
+		return nil, false, err
+	}
+
+	if userID == models.AnonymousUserID {
+		err := newError(ErrorKindInvalid, "invalid_user_id", "user %d is the tombstone of erased users", userID)
+		span.SetError(err)
+		return nil, false, err
+	}
+
+	var (
+		erasure *models.UserErasure
+		created bool
+	)
+	err := s.getTransactor().RunInTransaction(ctx, func(ctx context.Context) error {
+		orderIDs, err := s.getUserErasureRepository().LockOrderIDsByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if len(orderIDs) == 0 {
+			erasure, err = s.getUserErasureRepository().FindLatestErasure(ctx, userID)
+			if err == nil {
+				return nil
+			}
+			if err != repositories.ErrNotFound {
+				return err
+			}
+		}
+
+		now := s.getClock()()
+		if err = s.getRetentionRepository().AnonymiseOrders(ctx, orderIDs, now); err != nil {
+			return err
+		}
+
+		remaining, err := s.getUserErasureRepository().CountPersonalData(ctx, userID)
+		if err != nil {
+			return err
+		}
+		for table, n := range remaining {
+			if n > 0 {
+				return errors.Errorf("erasing user %d left %d rows in %s", userID, n, table)
+			}
+		}
+
+		erasure = &models.UserErasure{
+			UserID:       userID,
+			ActorID:      actor.ID,
+			OrdersErased: len(orderIDs),
+			ErasedAt:     now,
+		}
+		created = true
+		return s.getUserErasureRepository().CreateErasure(ctx, erasure)
+	})
+	if err != nil {
+		span.SetError(err)
+		return nil, false, err
+	}
+
+	span.SetAttribute("erasure.orders", erasure.OrdersErased)
+	span.SetAttribute("erasure.created", created)
+	return erasure, created, nil
+}
+
+// VerifyErasure counts the rows of every table that still identify a user,
+// and returns the user's latest erasure, if any. A user is erased when no
+// rows identify them.
+func (s *userDataService) VerifyErasure(ctx context.Context, userID int) (*models.ErasureVerification, error) {
+	ctx, span := tracing.StartSpan(ctx, "UserDataService.VerifyErasure")
+	defer span.End()
+
+	remaining, err := s.getUserErasureRepository().CountPersonalData(ctx, userID)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	verification := &models.ErasureVerification{UserID: userID, Erased: true, Remaining: remaining}
+	for _, n := range remaining {
+		if n > 0 {
+			verification.Erased = false
+		}
+	}
+
+	verification.LastErasure, err = s.getUserErasureRepository().FindLatestErasure(ctx, userID)
+	if err == repositories.ErrNotFound {
+		err = nil
+	}
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	return verification, nil
+}
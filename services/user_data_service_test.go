@@ -0,0 +1,249 @@
+package services_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/clients/mock_restaurant"
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UserDataService", func() {
+	var (
+		ctrl             *gomock.Controller
+		restaurantClient *mock_restaurant.MockClient
+		orderRepo        *mock_repositories.MockOrderRepository
+		itemRepo         *mock_repositories.MockOrderItemRepository
+		statusChangeRepo *mock_repositories.MockOrderStatusChangeRepository
+		refundRepo       *mock_repositories.MockRefundRepository
+		webhookRepo      *mock_repositories.MockWebhookRepository
+		userDataService  services.UserDataService
+
+		now = time.Date(2019, 5, 12, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		restaurantClient = mock_restaurant.NewMockClient(ctrl)
+		orderRepo = mock_repositories.NewMockOrderRepository(ctrl)
+		itemRepo = mock_repositories.NewMockOrderItemRepository(ctrl)
+		statusChangeRepo = mock_repositories.NewMockOrderStatusChangeRepository(ctrl)
+		refundRepo = mock_repositories.NewMockRefundRepository(ctrl)
+		webhookRepo = mock_repositories.NewMockWebhookRepository(ctrl)
+
+		userDataServiceImpl := services.NewUserDataService()
+		userDataServiceImpl.SetRestaurantClient(restaurantClient)
+		userDataServiceImpl.SetOrderRepository(orderRepo)
+		userDataServiceImpl.SetOrderItemRepository(itemRepo)
+		userDataServiceImpl.SetOrderStatusChangeRepository(statusChangeRepo)
+		userDataServiceImpl.SetRefundRepository(refundRepo)
+		userDataServiceImpl.SetWebhookRepository(webhookRepo)
+		userDataServiceImpl.SetClock(func() time.Time { return now })
+		userDataService = userDataServiceImpl
+	})
+
+	Describe("ExportUserData", func() {
+		It("gathers the user's orders, with deleted ones and restaurants that no longer exist", func() {
+			orders := models.Orders{
+				{ID: 3, UserID: 5, RestaurantID: 8, Total: models.NewMoney(2500, models.GBP)},
+				{ID: 4, UserID: 5, RestaurantID: 9, Total: models.NewMoney(1000, models.GBP), DeletedAt: now},
+			}
+			deliveries := models.WebhookDeliveries{{ID: 11, EventID: 7}}
+
+			orderRepo.EXPECT().FindAllOrdersByUserIDWithDeleted(gomock.Any(), gomock.Eq(5)).Return(orders, error(nil))
+			restaurantClient.EXPECT().GetRestaurantsByIDs(gomock.Any(), gomock.Eq([]int{8, 9})).
+				Return(models.Restaurants{{ID: 8, Name: "Pizza Place"}}, error(nil))
+			itemRepo.EXPECT().FindItemsByOrderIDs(gomock.Any(), gomock.Eq([]int{3, 4})).
+				Return(models.OrderItems{{OrderID: 3, Name: "Margherita", Quantity: 1, UnitPrice: 2500}}, error(nil))
+			statusChangeRepo.EXPECT().FindStatusChangesByOrderIDs(gomock.Any(), gomock.Eq([]int{3, 4})).Return(models.OrderStatusChanges{}, error(nil))
+			refundRepo.EXPECT().FindRefundsByOrderIDs(gomock.Any(), gomock.Eq([]int{3, 4})).Return(models.Refunds{}, error(nil))
+			webhookRepo.EXPECT().FindDeliveriesForUser(gomock.Any(), gomock.Eq(5)).Return(deliveries, error(nil))
+
+			export, err := userDataService.ExportUserData(context.Background(), 5)
+			Expect(err).To(BeNil())
+			Expect(export.UserID).To(Equal(5))
+			Expect(export.GeneratedAt).To(Equal(now))
+			Expect(export.Orders[0].Restaurant.Name).To(Equal("Pizza Place"))
+			Expect(export.Orders[0].Items).To(HaveLen(1))
+			Expect(export.Orders[1].Restaurant).To(BeNil())
+			Expect(export.WebhookDeliveries).To(Equal(deliveries))
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
+
+var _ = Describe("WriteUserDataArchive", func() {
+	var files map[string][]byte
+
+	BeforeEach(func() {
+		placedAt := time.Date(2019, 4, 1, 18, 30, 0, 0, time.UTC)
+		net := models.NewMoney(2000, models.GBP)
+		export := &models.UserDataExport{
+			UserID:      5,
+			GeneratedAt: time.Date(2019, 5, 12, 12, 0, 0, 0, time.UTC),
+			Orders: models.Orders{{
+				ID:            3,
+				RestaurantID:  8,
+				Restaurant:    &models.Restaurant{ID: 8, Name: "Pizza Place"},
+				Total:         models.NewMoney(2500, models.GBP),
+				NetTotal:      &net,
+				Status:        models.OrderStatusDelivered,
+				PlacedAt:      placedAt,
+				Items:         models.OrderItems{{Name: "Margherita", Quantity: 1, UnitPrice: 2500}},
+				StatusHistory: models.OrderStatusChanges{{Status: models.OrderStatusDelivered, ChangedAt: placedAt.Add(time.Hour)}},
+				Refunds:       models.Refunds{{ID: 1, Amount: models.NewMoney(500, models.GBP), Reason: "Cold", ActorID: "agent:1", CreatedAt: placedAt.Add(2 * time.Hour)}},
+			}},
+		}
+
+		buf := &bytes.Buffer{}
+		Expect(services.WriteUserDataArchive(buf, export)).To(Succeed())
+
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		Expect(err).To(BeNil())
+
+		files = map[string][]byte{}
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			Expect(err).To(BeNil())
+			files[f.Name], err = ioutil.ReadAll(rc)
+			Expect(err).To(BeNil())
+			rc.Close()
+		}
+	})
+
+	It("writes the data as JSON and CSV", func() {
+		Expect(string(files["orders.csv"])).To(Equal(
+			"id,restaurant_id,restaurant_name,status,currency,total,net_total,placed_at,cancelled_at,cancelled_by,cancellation_reason,deleted_at\n" +
+				"3,8,Pizza Place,delivered,GBP,25.00,20.00,2019-04-01T18:30:00Z,,,,\n",
+		))
+		Expect(string(files["order_items.csv"])).To(Equal("order_id,name,quantity,unit_price\n3,Margherita,1,25.00\n"))
+		Expect(string(files["refunds.csv"])).To(ContainSubstring("3,1,GBP,5.00,Cold,agent:1,2019-04-01T20:30:00Z"))
+		Expect(string(files["status_history.csv"])).To(ContainSubstring("3,delivered,2019-04-01T19:30:00Z"))
+		Expect(files["orders.json"]).To(ContainSubstring(`"restaurant_name": "Pizza Place"`))
+		Expect(string(files["webhook_deliveries.json"])).To(Equal("[]"))
+	})
+
+	It("lists every data file in the manifest with its checksum", func() {
+		manifest := struct {
+			UserID int `json:"user_id"`
+			Files  []struct {
+				Name    string `json:"name"`
+				Records int    `json:"records"`
+				SHA256  string `json:"sha256"`
+			} `json:"files"`
+		}{}
+		Expect(json.Unmarshal(files["manifest.json"], &manifest)).To(Succeed())
+		Expect(manifest.UserID).To(Equal(5))
+		Expect(manifest.Files).To(HaveLen(6))
+		Expect(manifest.Files[0].Name).To(Equal("orders.json"))
+		Expect(manifest.Files[0].Records).To(Equal(1))
+	})
+
+	It("writes checksums of every other file", func() {
+		lines := strings.Split(strings.TrimSpace(string(files["SHA256SUMS"])), "\n")
+		Expect(lines).To(HaveLen(7))
+		Expect(lines[6]).To(HaveSuffix("  manifest.json"))
+	})
+})
+
+var _ = Describe("UserDataService", func() {
+	var (
+		ctrl            *gomock.Controller
+		retentionRepo   *mock_repositories.MockRetentionRepository
+		erasureRepo     *mock_repositories.MockUserErasureRepository
+		userDataService services.UserDataService
+		admin           = &models.Actor{ID: "agent:1", Scopes: []string{models.ScopeOrdersAdmin}}
+		nothingLeft     = map[string]int{"orders": 0, "outbox_events": 0}
+
+		now = time.Date(2019, 5, 12, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		retentionRepo = mock_repositories.NewMockRetentionRepository(ctrl)
+		erasureRepo = mock_repositories.NewMockUserErasureRepository(ctrl)
+
+		userDataServiceImpl := services.NewUserDataService()
+		userDataServiceImpl.SetTransactor(passThroughTransactor(ctrl))
+		userDataServiceImpl.SetRetentionRepository(retentionRepo)
+		userDataServiceImpl.SetUserErasureRepository(erasureRepo)
+		userDataServiceImpl.SetClock(func() time.Time { return now })
+		userDataService = userDataServiceImpl
+	})
+
+	Describe("EraseUserData", func() {
+		It("forbids actors that are not support agents", func() {
+			_, _, err := userDataService.EraseUserData(context.Background(), 5, &models.Actor{ID: "user:5"})
+			Expect(err.(*services.Error).Kind).To(Equal(services.ErrorKindForbidden))
+		})
+
+		It("anonymises the user's orders and records the erasure", func() {
+			gomock.InOrder(
+				erasureRepo.EXPECT().LockOrderIDsByUserID(gomock.Any(), gomock.Eq(5)).Return([]int{3, 4}, error(nil)),
+				retentionRepo.EXPECT().AnonymiseOrders(gomock.Any(), gomock.Eq([]int{3, 4}), gomock.Eq(now)).Return(error(nil)),
+				erasureRepo.EXPECT().CountPersonalData(gomock.Any(), gomock.Eq(5)).Return(nothingLeft, error(nil)),
+				erasureRepo.EXPECT().CreateErasure(gomock.Any(), gomock.Eq(&models.UserErasure{
+					UserID:       5,
+					ActorID:      "agent:1",
+					OrdersErased: 2,
+					ErasedAt:     now,
+				})).Return(error(nil)),
+			)
+
+			erasure, created, err := userDataService.EraseUserData(context.Background(), 5, admin)
+			Expect(err).To(BeNil())
+			Expect(created).To(BeTrue())
+			Expect(erasure.OrdersErased).To(Equal(2))
+		})
+
+		It("returns the earlier erasure when there is nothing left to erase", func() {
+			earlier := &models.UserErasure{ID: 1, UserID: 5, OrdersErased: 2}
+			erasureRepo.EXPECT().LockOrderIDsByUserID(gomock.Any(), gomock.Eq(5)).Return([]int{}, error(nil))
+			erasureRepo.EXPECT().FindLatestErasure(gomock.Any(), gomock.Eq(5)).Return(earlier, error(nil))
+
+			erasure, created, err := userDataService.EraseUserData(context.Background(), 5, admin)
+			Expect(err).To(BeNil())
+			Expect(created).To(BeFalse())
+			Expect(erasure).To(Equal(earlier))
+		})
+
+		It("fails when personal data remains after anonymising", func() {
+			erasureRepo.EXPECT().LockOrderIDsByUserID(gomock.Any(), gomock.Eq(5)).Return([]int{3}, error(nil))
+			retentionRepo.EXPECT().AnonymiseOrders(gomock.Any(), gomock.Any(), gomock.Any()).Return(error(nil))
+			erasureRepo.EXPECT().CountPersonalData(gomock.Any(), gomock.Eq(5)).Return(map[string]int{"outbox_events": 1}, error(nil))
+
+			_, _, err := userDataService.EraseUserData(context.Background(), 5, admin)
+			Expect(err).To(MatchError("erasing user 5 left 1 rows in outbox_events"))
+		})
+	})
+
+	Describe("VerifyErasure", func() {
+		It("reports the rows that still identify the user", func() {
+			erasureRepo.EXPECT().CountPersonalData(gomock.Any(), gomock.Eq(5)).Return(map[string]int{"orders": 1}, error(nil))
+			erasureRepo.EXPECT().FindLatestErasure(gomock.Any(), gomock.Eq(5)).Return(nil, repositories.ErrNotFound)
+
+			verification, err := userDataService.VerifyErasure(context.Background(), 5)
+			Expect(err).To(BeNil())
+			Expect(verification).To(Equal(&models.ErasureVerification{UserID: 5, Remaining: map[string]int{"orders": 1}}))
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
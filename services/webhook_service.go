@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/application"
+	"github.com/SebastianCoetzee/blog-order-service-example/events"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+)
+
+// webhookDeliveriesLimit is how many of a subscription's latest deliveries
+// are returned from its delivery log.
+const webhookDeliveriesLimit = 100
+
+// minWebhookSecretLength is the shortest secret that a restaurant may choose.
+const minWebhookSecretLength = 16
+
+// WebhookService represents the business-logic layer for webhook
+// subscriptions and their delivery logs.
+type WebhookService interface {
+	CreateSubscription(ctx context.Context, restaurantID int, req WebhookSubscriptionRequest, actor *models.Actor) (*models.WebhookSubscription, error)
+	FindSubscriptionsForRestaurant(ctx context.Context, restaurantID int, actor *models.Actor) (models.WebhookSubscriptions, error)
+	FindSubscriptionByID(ctx context.Context, id int, actor *models.Actor) (*models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, id int, req WebhookSubscriptionRequest, actor *models.Actor) (*models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int, actor *models.Actor) error
+	FindDeliveries(ctx context.Context, subscriptionID int, actor *models.Actor) (models.WebhookDeliveries, error)
+	ReplayDelivery(ctx context.Context, subscriptionID int, deliveryID int64, actor *models.Actor) (*models.WebhookDelivery, error)
+}
+
+// WebhookSubscriptionRequest holds the fields of a subscription that a caller
+// may set. When updating a subscription, an empty URL or Secret and a nil
+// EventTypes leave the current value in place; an empty, non-nil EventTypes
+// subscribes to every event.
+type WebhookSubscriptionRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	// Secret is generated when a subscription is created without one.
+	Secret string `json:"secret"`
+}
+
+// NewWebhookService creates a webhook service.
+func NewWebhookService() *webhookService {
+	return &webhookService{}
+}
+
+type webhookService struct {
+	webhookRepository repositories.WebhookRepository
+	resolver          events.Resolver
+	now               func() time.Time
+}
+
+func (s *webhookService) SetWebhookRepository(r repositories.WebhookRepository) {
+	s.webhookRepository = r
+}
+
+func (s *webhookService) getWebhookRepository() repositories.WebhookRepository {
+	if s.webhookRepository != nil {
+		return s.webhookRepository
+	}
+
+	s.webhookRepository = repositories.NewWebhookRepository(application.ResolveDB())
+	return s.webhookRepository
+}
+
+// SetResolver overrides the resolver used to look up the hosts of webhook
+// URLs.
+func (s *webhookService) SetResolver(r events.Resolver) {
+	s.resolver = r
+}
+
+func (s *webhookService) getResolver() events.Resolver {
+	if s.resolver != nil {
+		return s.resolver
+	}
+
+	return net.DefaultResolver
+}
+
+// SetClock overrides the function used to tell the current time.
+func (s *webhookService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+func (s *webhookService) getClock() func() time.Time {
+	if s.now != nil {
+		return s.now
+	}
+
+	return time.Now
+}
+
+// canManageWebhooks reports whether actor may manage the webhooks of the
+// restaurant with the given ID.
+func canManageWebhooks(actor *models.Actor, restaurantID int) bool {
+	return actor.IsRestaurant(restaurantID) || actor.HasScope(models.ScopeOrdersAdmin)
+}
+
+// CreateSubscription subscribes a restaurant's webhook URL to the events about
+// its orders. Only the restaurant itself and support agents may subscribe.
+func (s *webhookService) CreateSubscription(ctx context.Context, restaurantID int, req WebhookSubscriptionRequest, actor *models.Actor) (*models.WebhookSubscription, error) {
+	ctx, span := tracing.StartSpan(ctx, "WebhookService.CreateSubscription")
+	defer span.End()
+
+	if !canManageWebhooks(actor, restaurantID) {
+		err := newError(ErrorKindForbidden, "forbidden", "not allowed to manage the webhooks of restaurant %d", restaurantID)
+		span.SetError(err)
+		return nil, err
+	}
+
+	if req.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			span.SetError(err)
+			return nil, err
+		}
+		req.Secret = secret
+	}
+
+	now := s.getClock()()
+	subscription := &models.WebhookSubscription{
+		RestaurantID: restaurantID,
+		EventTypes:   []models.EventType{},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.applyWebhookSubscriptionRequest(ctx, subscription, req); err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	if err := s.getWebhookRepository().CreateSubscription(ctx, subscription); err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// FindSubscriptionsForRestaurant returns the restaurant's subscriptions.
+func (s *webhookService) FindSubscriptionsForRestaurant(ctx context.Context, restaurantID int, actor *models.Actor) (models.WebhookSubscriptions, error) {
+	ctx, span := tracing.StartSpan(ctx, "WebhookService.FindSubscriptionsForRestaurant")
+	defer span.End()
+
+	if !canManageWebhooks(actor, restaurantID) {
+		err := newError(ErrorKindForbidden, "forbidden", "not allowed to manage the webhooks of restaurant %d", restaurantID)
+		span.SetError(err)
+		return nil, err
+	}
+
+	subscriptions, err := s.getWebhookRepository().FindSubscriptionsByRestaurantID(ctx, restaurantID)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// FindSubscriptionByID returns a subscription. Subscriptions of other
+// restaurants are reported as not found.
+func (s *webhookService) FindSubscriptionByID(ctx context.Context, id int, actor *models.Actor) (*models.WebhookSubscription, error) {
+	ctx, span := tracing.StartSpan(ctx, "WebhookService.FindSubscriptionByID")
+	defer span.End()
+
+	subscription, err := s.findSubscription(ctx, id, actor)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// UpdateSubscription changes the URL, event filter or secret of a
+// subscription.
+func (s *webhookService) UpdateSubscription(ctx context.Context, id int, req WebhookSubscriptionRequest, actor *models.Actor) (*models.WebhookSubscription, error) {
+	ctx, span := tracing.StartSpan(ctx, "WebhookService.UpdateSubscription")
+	defer span.End()
+
+	subscription, err := s.findSubscription(ctx, id, actor)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	if err = s.applyWebhookSubscriptionRequest(ctx, subscription, req); err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	subscription.UpdatedAt = s.getClock()()
+
+	if err = s.getWebhookRepository().UpdateSubscription(ctx, subscription); err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// DeleteSubscription deletes a subscription along with its delivery log.
+func (s *webhookService) DeleteSubscription(ctx context.Context, id int, actor *models.Actor) error {
+	ctx, span := tracing.StartSpan(ctx, "WebhookService.DeleteSubscription")
+	defer span.End()
+
+	if _, err := s.findSubscription(ctx, id, actor); err != nil {
+		span.SetError(err)
+		return err
+	}
+
+	if err := s.getWebhookRepository().DeleteSubscription(ctx, id); err != nil {
+		span.SetError(err)
+		return err
+	}
+
+	return nil
+}
+
+// FindDeliveries returns the latest entries of a subscription's delivery log,
+// newest first.
+func (s *webhookService) FindDeliveries(ctx context.Context, subscriptionID int, actor *models.Actor) (models.WebhookDeliveries, error) {
+	ctx, span := tracing.StartSpan(ctx, "WebhookService.FindDeliveries")
+	defer span.End()
+
+	if _, err := s.findSubscription(ctx, subscriptionID, actor); err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	deliveries, err := s.getWebhookRepository().FindDeliveriesBySubscriptionID(ctx, subscriptionID, webhookDeliveriesLimit)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// ReplayDelivery schedules the body of an earlier delivery to be sent again,
+// whatever the outcome of the earlier delivery. The replay is a new entry in
+// the delivery log.
+func (s *webhookService) ReplayDelivery(ctx context.Context, subscriptionID int, deliveryID int64, actor *models.Actor) (*models.WebhookDelivery, error) {
+	ctx, span := tracing.StartSpan(ctx, "WebhookService.ReplayDelivery")
+	defer span.End()
+
+	if _, err := s.findSubscription(ctx, subscriptionID, actor); err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	delivery, err := s.getWebhookRepository().FindDeliveryByID(ctx, deliveryID)
+	if err == repositories.ErrNotFound || (err == nil && delivery.SubscriptionID != subscriptionID) {
+		err = newError(ErrorKindNotFound, "webhook_delivery_not_found", "webhook delivery with ID %d not found", deliveryID)
+	}
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	replay := delivery.Replay(s.getClock()())
+	if err = s.getWebhookRepository().AddDeliveries(ctx, models.WebhookDeliveries{replay}); err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+
+	return replay, nil
+}
+
+// findSubscription returns the subscription with the given ID, or a not found
+// error when it does not exist or the actor may not manage it.
+func (s *webhookService) findSubscription(ctx context.Context, id int, actor *models.Actor) (*models.WebhookSubscription, error) {
+	subscription, err := s.getWebhookRepository().FindSubscriptionByID(ctx, id)
+	if err == repositories.ErrNotFound || (err == nil && !canManageWebhooks(actor, subscription.RestaurantID)) {
+		return nil, newError(ErrorKindNotFound, "webhook_subscription_not_found", "webhook subscription with ID %d not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// applyWebhookSubscriptionRequest validates req and copies the fields it sets
+// onto subscription. URLs whose host cannot be resolved, or resolves to an
+// address on this service's own network, are rejected; the webhook
+// dispatcher checks the address again when it connects.
+func (s *webhookService) applyWebhookSubscriptionRequest(ctx context.Context, subscription *models.WebhookSubscription, req WebhookSubscriptionRequest) error {
+	if req.URL != "" {
+		u, err := url.Parse(req.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+			return newError(ErrorKindInvalid, "invalid_webhook_url", "%q is not an absolute http or https URL", req.URL)
+		}
+		err = events.CheckWebhookHost(ctx, s.getResolver(), u.Hostname())
+		if err == events.ErrInternalWebhookAddress {
+			return newError(ErrorKindInvalid, "invalid_webhook_url", "%q resolves to a loopback, private, link-local or unspecified address", req.URL)
+		}
+		if err != nil {
+			return newError(ErrorKindInvalid, "invalid_webhook_url", "the host of %q cannot be resolved", req.URL)
+		}
+		subscription.URL = req.URL
+	}
+	if subscription.URL == "" {
+		return newError(ErrorKindInvalid, "invalid_webhook_url", "a webhook URL is required")
+	}
+
+	if req.EventTypes != nil {
+		eventTypes := make([]models.EventType, 0, len(req.EventTypes))
+		for _, name := range req.EventTypes {
+			eventType, err := models.ParseEventType(name)
+			if err != nil {
+				return newError(ErrorKindInvalid, "invalid_event_type", "%s", err)
+			}
+			eventTypes = append(eventTypes, eventType)
+		}
+		subscription.EventTypes = eventTypes
+	}
+
+	if req.Secret != "" {
+		if len(req.Secret) < minWebhookSecretLength {
+			return newError(ErrorKindInvalid, "invalid_webhook_secret", "webhook secrets must be at least %d characters long", minWebhookSecretLength)
+		}
+		subscription.Secret = req.Secret
+	}
+
+	return nil
+}
+
+// generateWebhookSecret returns a random secret for signing deliveries.
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,180 @@
+package services_test
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/mock_repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/repositories"
+	"github.com/SebastianCoetzee/blog-order-service-example/services"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// resolverFunc is an events.Resolver that answers lookups with a function.
+type resolverFunc func(ctx context.Context, host string) ([]net.IPAddr, error)
+
+func (f resolverFunc) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	return f(ctx, host)
+}
+
+var _ = Describe("WebhookService", func() {
+	var (
+		ctrl           *gomock.Controller
+		webhookRepo    *mock_repositories.MockWebhookRepository
+		webhookService services.WebhookService
+		actor          *models.Actor
+
+		now = time.Date(2019, 5, 6, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		webhookRepo = mock_repositories.NewMockWebhookRepository(ctrl)
+
+		webhookServiceImpl := services.NewWebhookService()
+		webhookServiceImpl.SetWebhookRepository(webhookRepo)
+		webhookServiceImpl.SetResolver(resolverFunc(func(ctx context.Context, host string) ([]net.IPAddr, error) {
+			switch host {
+			case "example.com":
+				return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}}, nil
+			case "internal.example.com":
+				return []net.IPAddr{{IP: net.ParseIP("10.0.0.5")}}, nil
+			}
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}))
+		webhookServiceImpl.SetClock(func() time.Time { return now })
+		webhookService = webhookServiceImpl
+
+		actor = &models.Actor{ID: "restaurant:9"}
+	})
+
+	Describe("CreateSubscription", func() {
+		It("forbids other restaurants", func() {
+			_, err := webhookService.CreateSubscription(context.Background(), 8, services.WebhookSubscriptionRequest{URL: "https://example.com"}, actor)
+			Expect(err.(*services.Error).Kind).To(Equal(services.ErrorKindForbidden))
+		})
+
+		It("rejects URLs that are not absolute http or https URLs", func() {
+			_, err := webhookService.CreateSubscription(context.Background(), 9, services.WebhookSubscriptionRequest{URL: "ftp://example.com"}, actor)
+			Expect(err.(*services.Error).Code).To(Equal("invalid_webhook_url"))
+		})
+
+		It("rejects URLs on this service's own network", func() {
+			for _, u := range []string{"http://127.0.0.1:8080/admin", "http://[::1]/", "http://169.254.169.254/latest/meta-data", "https://internal.example.com/hooks"} {
+				_, err := webhookService.CreateSubscription(context.Background(), 9, services.WebhookSubscriptionRequest{URL: u}, actor)
+				Expect(err.(*services.Error).Code).To(Equal("invalid_webhook_url"), u)
+			}
+		})
+
+		It("rejects URLs whose host cannot be resolved", func() {
+			_, err := webhookService.CreateSubscription(context.Background(), 9, services.WebhookSubscriptionRequest{URL: "https://missing.example.com"}, actor)
+			Expect(err.(*services.Error).Code).To(Equal("invalid_webhook_url"))
+		})
+
+		It("rejects unknown event types", func() {
+			_, err := webhookService.CreateSubscription(context.Background(), 9, services.WebhookSubscriptionRequest{
+				URL:        "https://example.com",
+				EventTypes: []string{"OrderEaten"},
+			}, actor)
+			Expect(err.(*services.Error).Code).To(Equal("invalid_event_type"))
+		})
+
+		It("generates a secret when none is given", func() {
+			webhookRepo.EXPECT().CreateSubscription(gomock.Any(), gomock.Any()).Return(nil)
+
+			subscription, err := webhookService.CreateSubscription(context.Background(), 9, services.WebhookSubscriptionRequest{
+				URL:        "https://example.com/hooks",
+				EventTypes: []string{"OrderRefunded"},
+			}, actor)
+			Expect(err).To(BeNil())
+			Expect(subscription.RestaurantID).To(Equal(9))
+			Expect(subscription.EventTypes).To(Equal([]models.EventType{models.EventTypeOrderRefunded}))
+			Expect(len(subscription.Secret)).To(Equal(64))
+			Expect(subscription.CreatedAt).To(Equal(now))
+		})
+	})
+
+	Describe("UpdateSubscription", func() {
+		var subscription *models.WebhookSubscription
+
+		BeforeEach(func() {
+			subscription = &models.WebhookSubscription{
+				ID:           2,
+				RestaurantID: 9,
+				URL:          "https://example.com/hooks",
+				EventTypes:   []models.EventType{models.EventTypeOrderRefunded},
+				Secret:       "0123456789abcdef",
+			}
+			webhookRepo.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Eq(2)).Return(subscription, error(nil))
+		})
+
+		It("hides subscriptions of other restaurants", func() {
+			actor = &models.Actor{ID: "restaurant:8"}
+			_, err := webhookService.UpdateSubscription(context.Background(), 2, services.WebhookSubscriptionRequest{}, actor)
+			Expect(err.(*services.Error).Code).To(Equal("webhook_subscription_not_found"))
+		})
+
+		It("rejects URLs on this service's own network", func() {
+			_, err := webhookService.UpdateSubscription(context.Background(), 2, services.WebhookSubscriptionRequest{URL: "http://192.168.0.1/hooks"}, actor)
+			Expect(err.(*services.Error).Code).To(Equal("invalid_webhook_url"))
+			Expect(subscription.URL).To(Equal("https://example.com/hooks"))
+		})
+
+		It("changes only the fields that are given", func() {
+			webhookRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Eq(subscription)).Return(nil)
+
+			updated, err := webhookService.UpdateSubscription(context.Background(), 2, services.WebhookSubscriptionRequest{EventTypes: []string{}}, actor)
+			Expect(err).To(BeNil())
+			Expect(updated.URL).To(Equal("https://example.com/hooks"))
+			Expect(updated.Secret).To(Equal("0123456789abcdef"))
+			Expect(updated.EventTypes).To(BeEmpty())
+			Expect(updated.UpdatedAt).To(Equal(now))
+		})
+	})
+
+	Describe("ReplayDelivery", func() {
+		BeforeEach(func() {
+			webhookRepo.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Eq(2)).Return(&models.WebhookSubscription{ID: 2, RestaurantID: 9}, error(nil))
+		})
+
+		It("does not replay deliveries of another subscription", func() {
+			webhookRepo.EXPECT().FindDeliveryByID(gomock.Any(), gomock.Eq(int64(11))).Return(&models.WebhookDelivery{ID: 11, SubscriptionID: 3}, error(nil))
+
+			_, err := webhookService.ReplayDelivery(context.Background(), 2, 11, actor)
+			Expect(err.(*services.Error).Code).To(Equal("webhook_delivery_not_found"))
+		})
+
+		It("reports unknown deliveries as not found", func() {
+			webhookRepo.EXPECT().FindDeliveryByID(gomock.Any(), gomock.Eq(int64(11))).Return(nil, repositories.ErrNotFound)
+
+			_, err := webhookService.ReplayDelivery(context.Background(), 2, 11, actor)
+			Expect(err.(*services.Error).Kind).To(Equal(services.ErrorKindNotFound))
+		})
+
+		It("adds a new pending delivery of the same body to the log", func() {
+			webhookRepo.EXPECT().FindDeliveryByID(gomock.Any(), gomock.Eq(int64(11))).Return(&models.WebhookDelivery{
+				ID:             11,
+				SubscriptionID: 2,
+				EventID:        7,
+				Body:           []byte(`{"id":7}`),
+				Status:         models.WebhookDeliveryStatusFailed,
+			}, error(nil))
+			webhookRepo.EXPECT().AddDeliveries(gomock.Any(), gomock.Any()).Return(nil)
+
+			replay, err := webhookService.ReplayDelivery(context.Background(), 2, 11, actor)
+			Expect(err).To(BeNil())
+			Expect(replay.Status).To(Equal(models.WebhookDeliveryStatusPending))
+			Expect(*replay.ReplayOf).To(Equal(int64(11)))
+			Expect(replay.NextAttemptAt).To(Equal(now))
+		})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+})
@@ -0,0 +1,90 @@
+package factories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/clients/restaurant"
+	"github.com/SebastianCoetzee/blog-order-service-example/clients/restaurant/restauranttest"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/SebastianCoetzee/blog-order-service-example/testing/factories"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestFactories(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Factories Suite")
+}
+
+var _ = Describe("Order", func() {
+	It("builds a placed order with defaults", func() {
+		order := factories.Order().Build()
+
+		Expect(order).To(Equal(&models.Order{
+			UserID:          factories.DefaultUserID,
+			RestaurantID:    factories.DefaultRestaurantID,
+			Total:           models.NewMoney(2500, models.GBP),
+			TotalMinorUnits: 2500,
+			CurrencyCode:    models.GBP,
+			Status:          models.OrderStatusPlaced,
+			PlacedAt:        factories.Epoch,
+			Version:         1,
+		}))
+	})
+
+	It("applies overrides", func() {
+		cancelledAt := factories.Epoch.Add(time.Minute)
+		order := factories.Order().
+			WithID(3).
+			WithUserID(5).
+			WithRestaurantID(2).
+			WithTotal(1800, models.EUR).
+			WithVersion(2).
+			Cancelled("user:5", models.CancellationReasonChangedMind, cancelledAt).
+			Build()
+
+		Expect(order.ID).To(Equal(3))
+		Expect(order.UserID).To(Equal(5))
+		Expect(order.RestaurantID).To(Equal(2))
+		Expect(order.Total).To(Equal(models.NewMoney(1800, models.EUR)))
+		Expect(order.TotalMinorUnits).To(Equal(int64(1800)))
+		Expect(order.CurrencyCode).To(Equal(models.EUR))
+		Expect(order.Version).To(Equal(2))
+		Expect(order.Status).To(Equal(models.OrderStatusCancelled))
+		Expect(*order.CancelledAt).To(Equal(cancelledAt))
+		Expect(order.CancelledBy).To(Equal("user:5"))
+		Expect(order.CancellationReason).To(Equal(models.CancellationReasonChangedMind))
+	})
+
+	It("builds a new order every time", func() {
+		b := factories.Order().Cancelled("user:1", models.CancellationReasonOther, factories.Epoch)
+
+		first := b.Build()
+		first.UserID = 9
+		*first.CancelledAt = factories.Epoch.Add(time.Hour)
+
+		second := b.Build()
+		Expect(second.UserID).To(Equal(factories.DefaultUserID))
+		Expect(*second.CancelledAt).To(Equal(factories.Epoch))
+	})
+})
+
+var _ = Describe("Restaurant", func() {
+	It("returns the default restaurants", func() {
+		Expect(factories.Restaurant(factories.DefaultRestaurantID)).To(Equal(&models.Restaurant{ID: 1, Name: "Pizza Palace"}))
+		Expect(factories.Restaurant(42)).To(BeNil())
+	})
+
+	It("serves the default restaurants from a fake RestaurantService", func() {
+		server := factories.NewRestaurantServer(restauranttest.Faults{})
+		defer server.Close()
+
+		client := restaurant.NewClient()
+		client.SetBaseURL(server.URL())
+		restaurants, err := client.GetRestaurantsByIDs(context.Background(), []int{2, 1})
+		Expect(err).To(BeNil())
+		Expect(restaurants).To(Equal(models.Restaurants{factories.Restaurant(2), factories.Restaurant(1)}))
+	})
+})
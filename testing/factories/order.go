@@ -0,0 +1,208 @@
+// Package factories builds models for tests and development databases. Its
+// builders fill in sensible defaults so that callers only spell out the
+// fields that matter to them.
+package factories
+
+import (
+	"time"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+	"github.com/go-pg/pg/orm"
+)
+
+// Epoch is the time that orders are placed at unless told otherwise.
+var Epoch = time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// The user that orders are placed by and the total that they are placed
+// with unless told otherwise.
+const (
+	DefaultUserID          = 1
+	DefaultTotalMinorUnits = 2500
+)
+
+// Order returns a builder for a placed order of 25.00 GBP by DefaultUserID
+// at DefaultRestaurantID, placed at Epoch.
+func Order() *OrderBuilder {
+	return &OrderBuilder{
+		order: models.Order{
+			UserID:       DefaultUserID,
+			RestaurantID: DefaultRestaurantID,
+			Total:        models.NewMoney(DefaultTotalMinorUnits, models.GBP),
+			Status:       models.OrderStatusPlaced,
+			PlacedAt:     Epoch,
+			Version:      1,
+		},
+	}
+}
+
+// OrderBuilder builds an order together with its items, status history and
+// refunds. Its methods change the builder and return it so that calls can be
+// chained.
+type OrderBuilder struct {
+	order   models.Order
+	items   models.OrderItems
+	changes models.OrderStatusChanges
+	refunds models.Refunds
+}
+
+// WithID gives the order a fixed ID instead of one from the database.
+func (b *OrderBuilder) WithID(id int) *OrderBuilder {
+	b.order.ID = id
+	return b
+}
+
+// WithUserID sets the user that placed the order.
+func (b *OrderBuilder) WithUserID(userID int) *OrderBuilder {
+	b.order.UserID = userID
+	return b
+}
+
+// WithRestaurantID sets the restaurant that the order was placed at.
+func (b *OrderBuilder) WithRestaurantID(restaurantID int) *OrderBuilder {
+	b.order.RestaurantID = restaurantID
+	return b
+}
+
+// WithTotal sets the total of the order.
+func (b *OrderBuilder) WithTotal(minorUnits int64, currency models.Currency) *OrderBuilder {
+	b.order.Total = models.NewMoney(minorUnits, currency)
+	return b
+}
+
+// WithStatus sets the status of the order.
+func (b *OrderBuilder) WithStatus(status models.OrderStatus) *OrderBuilder {
+	b.order.Status = status
+	return b
+}
+
+// WithPlacedAt sets when the order was placed.
+func (b *OrderBuilder) WithPlacedAt(placedAt time.Time) *OrderBuilder {
+	b.order.PlacedAt = placedAt
+	return b
+}
+
+// WithVersion sets the version of the order.
+func (b *OrderBuilder) WithVersion(version int) *OrderBuilder {
+	b.order.Version = version
+	return b
+}
+
+// Cancelled marks the order as cancelled by actorID at the given time.
+func (b *OrderBuilder) Cancelled(actorID string, reason models.CancellationReason, at time.Time) *OrderBuilder {
+	b.order.Status = models.OrderStatusCancelled
+	b.order.CancelledAt = &at
+	b.order.CancelledBy = actorID
+	b.order.CancellationReason = reason
+	return b
+}
+
+// Deleted marks the order as deleted at the given time.
+func (b *OrderBuilder) Deleted(at time.Time) *OrderBuilder {
+	b.order.DeletedAt = at
+	return b
+}
+
+// WithItem adds a line item. unitPrice is in minor units of the order's
+// currency.
+func (b *OrderBuilder) WithItem(name string, quantity int, unitPrice int64) *OrderBuilder {
+	b.items = append(b.items, &models.OrderItem{Name: name, Quantity: quantity, UnitPrice: unitPrice})
+	return b
+}
+
+// WithStatusChange adds a change into status to the order's history.
+func (b *OrderBuilder) WithStatusChange(status models.OrderStatus, at time.Time) *OrderBuilder {
+	b.changes = append(b.changes, &models.OrderStatusChange{Status: status, ChangedAt: at})
+	return b
+}
+
+// WithRefund adds a refund in the currency of the order, made by actorID.
+// The order's currency must be set first.
+func (b *OrderBuilder) WithRefund(minorUnits int64, reason, actorID string, at time.Time) *OrderBuilder {
+	b.refunds = append(b.refunds, &models.Refund{
+		Amount:    models.NewMoney(minorUnits, b.order.Total.Currency),
+		Reason:    reason,
+		ActorID:   actorID,
+		CreatedAt: at,
+	})
+	return b
+}
+
+// WithRefundID gives the refund added last a fixed ID instead of one from
+// the database, so that it can be compared with golden files.
+func (b *OrderBuilder) WithRefundID(id int) *OrderBuilder {
+	b.refunds[len(b.refunds)-1].ID = id
+	return b
+}
+
+// Build returns the order as it is stored in the orders table, without its
+// items, status history or refunds. Every call returns a new order.
+func (b *OrderBuilder) Build() *models.Order {
+	order := b.order
+	order.TotalMinorUnits = order.Total.MinorUnits
+	order.CurrencyCode = order.Total.Currency
+	if order.CancelledAt != nil {
+		cancelledAt := *order.CancelledAt
+		order.CancelledAt = &cancelledAt
+	}
+
+	return &order
+}
+
+// Insert stores the order, then its items, status history and refunds, in
+// db, which is usually a *pg.Tx. It returns the order with its ID and its
+// items, status history and refunds attached.
+func (b *OrderBuilder) Insert(db orm.DB) (*models.Order, error) {
+	order := b.Build()
+	if err := db.Insert(order); err != nil {
+		return nil, err
+	}
+
+	order.Items = models.OrderItems{}
+	for _, item := range b.items {
+		item := *item
+		item.OrderID = order.ID
+		if err := db.Insert(&item); err != nil {
+			return nil, err
+		}
+		order.Items = append(order.Items, &item)
+	}
+
+	order.StatusHistory = models.OrderStatusChanges{}
+	for _, change := range b.changes {
+		change := *change
+		change.OrderID = order.ID
+		if err := db.Insert(&change); err != nil {
+			return nil, err
+		}
+		order.StatusHistory = append(order.StatusHistory, &change)
+	}
+
+	refunds := models.Refunds{}
+	for _, refund := range b.refunds {
+		refund := *refund
+		refund.OrderID = order.ID
+		if err := db.Insert(&refund); err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, &refund)
+	}
+	if err := order.SetRefunds(refunds); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// InsertOrders inserts the orders of builders in db, in order.
+func InsertOrders(db orm.DB, builders ...*OrderBuilder) (models.Orders, error) {
+	orders := make(models.Orders, 0, len(builders))
+	for _, b := range builders {
+		order, err := b.Insert(db)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
@@ -0,0 +1,42 @@
+package factories
+
+import (
+	"github.com/SebastianCoetzee/blog-order-service-example/clients/restaurant/restauranttest"
+	"github.com/SebastianCoetzee/blog-order-service-example/models"
+)
+
+// DefaultRestaurantID is the restaurant that orders are placed at unless
+// told otherwise. It is one of Restaurants.
+const DefaultRestaurantID = 1
+
+var restaurants = []restauranttest.Restaurant{
+	{ID: 1, Name: "Pizza Palace"},
+	{ID: 2, Name: "Sushi Spot"},
+	{ID: 3, Name: "Burger Barn"},
+	{ID: 4, Name: "Curry House"},
+	{ID: 5, Name: "Taco Stand"},
+}
+
+// Restaurants returns the restaurants that the fake RestaurantService serves
+// by default, ordered by ID.
+func Restaurants() []restauranttest.Restaurant {
+	return append([]restauranttest.Restaurant{}, restaurants...)
+}
+
+// Restaurant returns the restaurant with the given ID as the restaurant
+// client returns it, or nil when it is not one of Restaurants.
+func Restaurant(id int) *models.Restaurant {
+	for _, r := range restaurants {
+		if r.ID == id {
+			return &models.Restaurant{ID: r.ID, Name: r.Name}
+		}
+	}
+
+	return nil
+}
+
+// NewRestaurantServer starts a fake RestaurantService that serves
+// Restaurants. Close it when done.
+func NewRestaurantServer(faults restauranttest.Faults) *restauranttest.Server {
+	return restauranttest.NewServer(restaurants, faults)
+}
@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Exporter receives spans once they end. Implementations must be safe for
+// concurrent use.
+type Exporter interface {
+	ExportSpan(span *SpanData)
+}
+
+// NopExporter discards all spans.
+type NopExporter struct{}
+
+// ExportSpan implements Exporter.
+func (NopExporter) ExportSpan(*SpanData) {}
+
+// NewStdoutExporter creates an Exporter that writes each span to w as a line
+// of JSON. Despite its name it may write to any io.Writer.
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+// StdoutExporter writes spans as newline-delimited JSON.
+type StdoutExporter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// ExportSpan implements Exporter.
+func (e *StdoutExporter) ExportSpan(span *SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Tracing must never break the request that is being traced, so encoding
+	// errors are dropped.
+	_ = e.encoder.Encode(span)
+}
+
+// NewInMemoryExporter creates an Exporter that keeps spans in memory. It is
+// intended for tests.
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// InMemoryExporter collects exported spans in memory.
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []*SpanData
+}
+
+// ExportSpan implements Exporter.
+func (e *InMemoryExporter) ExportSpan(span *SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = append(e.spans, span)
+}
+
+// Spans returns the spans exported so far, in the order they ended.
+func (e *InMemoryExporter) Spans() []*SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	spans := make([]*SpanData, len(e.spans))
+	copy(spans, e.spans)
+	return spans
+}
+
+// SpanNamed returns the first exported span with the given name, or nil.
+func (e *InMemoryExporter) SpanNamed(name string) *SpanData {
+	for _, span := range e.Spans() {
+		if span.Name == name {
+			return span
+		}
+	}
+
+	return nil
+}
+
+// Reset discards all collected spans.
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = nil
+}
@@ -0,0 +1,33 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+)
+
+// RequestIDHeader is the header that identifies a request in logs and in the
+// audit log of the changes it made.
+const RequestIDHeader = "X-Request-ID"
+
+// Inject writes the traceparent of the span in ctx to the outgoing headers.
+// Nothing is written when ctx carries no span.
+func Inject(ctx context.Context, header http.Header) {
+	sc := SpanFromContext(ctx).Context()
+	if !sc.IsValid() {
+		return
+	}
+
+	header.Set(TraceparentHeader, sc.Traceparent())
+}
+
+// Extract reads the traceparent of an incoming request and returns a copy of
+// ctx that carries it as the remote parent. Invalid or missing headers are
+// ignored, in which case a new trace is started by the next span.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	sc, err := ParseTraceparent(header.Get(TraceparentHeader))
+	if err != nil {
+		return ctx
+	}
+
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
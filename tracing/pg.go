@@ -0,0 +1,42 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/go-pg/pg"
+)
+
+type queryHookKey struct{}
+
+// QueryHook is a go-pg query hook that records a span for every query.
+type QueryHook struct{}
+
+// BeforeQuery implements pg.QueryHook.
+func (QueryHook) BeforeQuery(event *pg.QueryEvent) {
+	ctx := event.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	_, span := StartSpan(ctx, "pg.query")
+	if query, err := event.UnformattedQuery(); err == nil {
+		span.SetAttribute("db.statement", query)
+	}
+	span.SetAttribute("db.attempt", event.Attempt)
+
+	event.Data[queryHookKey{}] = span
+}
+
+// AfterQuery implements pg.QueryHook.
+func (QueryHook) AfterQuery(event *pg.QueryEvent) {
+	span, ok := event.Data[queryHookKey{}].(*Span)
+	if !ok {
+		return
+	}
+
+	if event.Result != nil {
+		span.SetAttribute("db.rows_returned", event.Result.RowsReturned())
+	}
+	span.SetError(event.Error)
+	span.End()
+}
@@ -0,0 +1,123 @@
+package tracing
+
+import (
+	"sync"
+	"time"
+)
+
+// Span records a single timed operation within a trace. A Span is safe for
+// concurrent use.
+type Span struct {
+	mu         sync.Mutex
+	tracer     *Tracer
+	name       string
+	context    SpanContext
+	parentID   SpanID
+	startTime  time.Time
+	endTime    time.Time
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+// SpanData is an immutable snapshot of a finished Span that is handed to an
+// Exporter.
+type SpanData struct {
+	Name       string                 `json:"name"`
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	StartTime  time.Time              `json:"start_time"`
+	EndTime    time.Time              `json:"end_time"`
+	Duration   time.Duration          `json:"duration_ns"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// Context returns the SpanContext of the Span. It is safe to call on a nil
+// Span, in which case an invalid SpanContext is returned.
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+
+	return s.context
+}
+
+// SetAttribute records a key/value pair on the Span.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.attributes == nil {
+		s.attributes = make(map[string]interface{})
+	}
+	s.attributes[key] = value
+}
+
+// SetError marks the Span as failed. A nil error is ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err
+}
+
+// End finishes the Span and exports it. Calling End more than once has no
+// effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.endTime = time.Now()
+	data := s.snapshot()
+	s.mu.Unlock()
+
+	if s.context.IsSampled() {
+		s.tracer.getExporter().ExportSpan(data)
+	}
+}
+
+// snapshot must be called with s.mu held.
+func (s *Span) snapshot() *SpanData {
+	data := &SpanData{
+		Name:      s.name,
+		TraceID:   s.context.TraceID.String(),
+		SpanID:    s.context.SpanID.String(),
+		StartTime: s.startTime,
+		EndTime:   s.endTime,
+		Duration:  s.endTime.Sub(s.startTime),
+	}
+
+	if s.parentID.IsValid() {
+		data.ParentID = s.parentID.String()
+	}
+
+	if len(s.attributes) > 0 {
+		data.Attributes = make(map[string]interface{}, len(s.attributes))
+		for k, v := range s.attributes {
+			data.Attributes[k] = v
+		}
+	}
+
+	if s.err != nil {
+		data.Error = s.err.Error()
+	}
+
+	return data
+}
@@ -0,0 +1,135 @@
+// Package tracing implements a small distributed tracing toolkit. Spans are
+// propagated between services using the W3C Trace Context traceparent header
+// and are handed to a pluggable Exporter once they end.
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TraceparentHeader is the W3C Trace Context header used to propagate spans
+// between services.
+const TraceparentHeader = "traceparent"
+
+const traceparentVersion = "00"
+
+// flagSampled is the trace-flags bit that marks a trace as sampled.
+const flagSampled = 0x01
+
+// TraceID identifies a trace across all of the services it touches.
+type TraceID [16]byte
+
+// String returns the lowercase hex representation of the TraceID.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid reports whether the TraceID is not all zeros.
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// SpanID identifies a single span within a trace.
+type SpanID [8]byte
+
+// String returns the lowercase hex representation of the SpanID.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid reports whether the SpanID is not all zeros.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// SpanContext is the part of a span that is propagated to other services.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   byte
+}
+
+// IsValid reports whether the SpanContext has both a trace and a span ID.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// IsSampled reports whether the sampled flag is set.
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&flagSampled != 0
+}
+
+// Traceparent formats the SpanContext as a traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("%s-%s-%s-%02x", traceparentVersion, sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// ParseTraceparent parses a traceparent header value into a SpanContext.
+func ParseTraceparent(value string) (SpanContext, error) {
+	sc := SpanContext{}
+
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return sc, errors.Errorf("malformed traceparent %q", value)
+	}
+
+	version := parts[0]
+	if len(version) != 2 || version == "ff" {
+		return sc, errors.Errorf("unsupported traceparent version %q", version)
+	}
+	// Version 00 has exactly four fields. Later versions may append more, which
+	// must be ignored.
+	if version == traceparentVersion && len(parts) != 4 {
+		return sc, errors.Errorf("malformed traceparent %q", value)
+	}
+
+	if err := decodeHex(parts[1], sc.TraceID[:]); err != nil {
+		return sc, errors.Wrap(err, "invalid trace-id")
+	}
+	if err := decodeHex(parts[2], sc.SpanID[:]); err != nil {
+		return sc, errors.Wrap(err, "invalid parent-id")
+	}
+
+	flags := make([]byte, 1)
+	if err := decodeHex(parts[3], flags); err != nil {
+		return sc, errors.Wrap(err, "invalid trace-flags")
+	}
+	sc.Flags = flags[0]
+
+	if !sc.IsValid() {
+		return sc, errors.Errorf("traceparent %q has an all-zero ID", value)
+	}
+
+	return sc, nil
+}
+
+// decodeHex decodes a lowercase hex string of exactly len(dst) bytes.
+func decodeHex(s string, dst []byte) error {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return errors.Errorf("expected %d lowercase hex characters, got %q", hex.EncodedLen(len(dst)), s)
+	}
+
+	_, err := hex.Decode(dst, []byte(s))
+	return err
+}
+
+func newTraceID() TraceID {
+	id := TraceID{}
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	id := SpanID{}
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
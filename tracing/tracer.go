@@ -0,0 +1,133 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type contextKey int
+
+const (
+	spanKey contextKey = iota
+	remoteSpanContextKey
+	requestIDKey
+)
+
+// Tracer starts spans and hands them to its Exporter when they end.
+type Tracer struct {
+	mu       sync.RWMutex
+	exporter Exporter
+}
+
+// NewTracer creates a Tracer that exports to the given Exporter.
+func NewTracer(e Exporter) *Tracer {
+	return &Tracer{exporter: e}
+}
+
+// SetExporter replaces the Exporter of the Tracer.
+func (t *Tracer) SetExporter(e Exporter) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.exporter = e
+}
+
+func (t *Tracer) getExporter() Exporter {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.exporter != nil {
+		return t.exporter
+	}
+
+	return NopExporter{}
+}
+
+// StartSpan starts a new Span as a child of the span in ctx, or of a remote
+// parent extracted from an incoming request. A new trace is started when
+// neither is present. The returned context carries the new Span.
+func (t *Tracer) StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	span := &Span{
+		tracer:    t,
+		name:      name,
+		startTime: time.Now(),
+	}
+
+	parent := SpanFromContext(ctx).Context()
+	if !parent.IsValid() {
+		parent = RemoteSpanContextFromContext(ctx)
+	}
+
+	if parent.IsValid() {
+		span.context = SpanContext{
+			TraceID: parent.TraceID,
+			SpanID:  newSpanID(),
+			Flags:   parent.Flags,
+		}
+		span.parentID = parent.SpanID
+	} else {
+		span.context = SpanContext{
+			TraceID: newTraceID(),
+			SpanID:  newSpanID(),
+			Flags:   flagSampled,
+		}
+	}
+
+	return ContextWithSpan(ctx, span), span
+}
+
+var defaultTracer = NewTracer(NopExporter{})
+
+// DefaultTracer returns the process-wide Tracer used by StartSpan.
+func DefaultTracer() *Tracer {
+	return defaultTracer
+}
+
+// SetExporter replaces the Exporter of the default Tracer.
+func SetExporter(e Exporter) {
+	defaultTracer.SetExporter(e)
+}
+
+// StartSpan starts a Span using the default Tracer.
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	return defaultTracer.StartSpan(ctx, name)
+}
+
+// ContextWithSpan returns a copy of ctx that carries span.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey, span)
+}
+
+// SpanFromContext returns the Span carried by ctx, or nil if there is none.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey).(*Span)
+	return span
+}
+
+// ContextWithRemoteSpanContext returns a copy of ctx that carries a
+// SpanContext received from another service. Spans started from the returned
+// context become children of the remote span.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteSpanContextKey, sc)
+}
+
+// RemoteSpanContextFromContext returns the remote SpanContext carried by
+// ctx. The result is invalid if there is none.
+func RemoteSpanContextFromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(remoteSpanContextKey).(SpanContext)
+	return sc
+}
+
+// ContextWithRequestID returns a copy of ctx that carries the ID of the
+// request being served.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or an empty
+// string if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
@@ -0,0 +1,140 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/SebastianCoetzee/blog-order-service-example/tracing"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestTracing(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tracing Suite")
+}
+
+var _ = Describe("Tracing", func() {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	var (
+		exporter *tracing.InMemoryExporter
+		tracer   *tracing.Tracer
+	)
+
+	BeforeEach(func() {
+		exporter = tracing.NewInMemoryExporter()
+		tracer = tracing.NewTracer(exporter)
+	})
+
+	Describe("ParseTraceparent", func() {
+		It("parses a valid header and formats it back", func() {
+			sc, err := tracing.ParseTraceparent(traceparent)
+			Expect(err).To(BeNil())
+			Expect(sc.TraceID.String()).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+			Expect(sc.SpanID.String()).To(Equal("00f067aa0ba902b7"))
+			Expect(sc.IsSampled()).To(BeTrue())
+			Expect(sc.Traceparent()).To(Equal(traceparent))
+		})
+
+		It("rejects malformed headers", func() {
+			for _, value := range []string{
+				"",
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+				"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+				"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+				"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			} {
+				_, err := tracing.ParseTraceparent(value)
+				Expect(err).NotTo(BeNil(), value)
+			}
+		})
+	})
+
+	Describe("StartSpan", func() {
+		It("starts a new trace without a parent", func() {
+			_, span := tracer.StartSpan(context.Background(), "root")
+			span.End()
+
+			spans := exporter.Spans()
+			Expect(len(spans)).To(Equal(1))
+			Expect(spans[0].Name).To(Equal("root"))
+			Expect(spans[0].ParentID).To(Equal(""))
+		})
+
+		It("nests spans within the same trace", func() {
+			ctx, parent := tracer.StartSpan(context.Background(), "parent")
+			_, child := tracer.StartSpan(ctx, "child")
+			child.SetError(errors.New("boom"))
+			child.End()
+			parent.End()
+
+			childData := exporter.SpanNamed("child")
+			parentData := exporter.SpanNamed("parent")
+			Expect(childData.TraceID).To(Equal(parentData.TraceID))
+			Expect(childData.ParentID).To(Equal(parentData.SpanID))
+			Expect(childData.Error).To(Equal("boom"))
+		})
+
+		It("continues a remote trace extracted from headers", func() {
+			header := http.Header{}
+			header.Set(tracing.TraceparentHeader, traceparent)
+
+			ctx := tracing.Extract(context.Background(), header)
+			_, span := tracer.StartSpan(ctx, "server")
+			span.End()
+
+			data := exporter.SpanNamed("server")
+			Expect(data.TraceID).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+			Expect(data.ParentID).To(Equal("00f067aa0ba902b7"))
+		})
+
+		It("does not export spans of unsampled traces", func() {
+			header := http.Header{}
+			header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+
+			_, span := tracer.StartSpan(tracing.Extract(context.Background(), header), "server")
+			span.End()
+
+			Expect(len(exporter.Spans())).To(Equal(0))
+		})
+	})
+
+	Describe("Inject", func() {
+		It("writes the traceparent of the current span", func() {
+			ctx, span := tracer.StartSpan(context.Background(), "client")
+			header := http.Header{}
+			tracing.Inject(ctx, header)
+
+			Expect(header.Get(tracing.TraceparentHeader)).To(Equal(span.Context().Traceparent()))
+		})
+
+		It("writes nothing without a span", func() {
+			header := http.Header{}
+			tracing.Inject(context.Background(), header)
+
+			Expect(header.Get(tracing.TraceparentHeader)).To(Equal(""))
+		})
+	})
+
+	Describe("StdoutExporter", func() {
+		It("writes each span as a line of JSON", func() {
+			buf := &bytes.Buffer{}
+			tracer.SetExporter(tracing.NewStdoutExporter(buf))
+
+			_, span := tracer.StartSpan(context.Background(), "query")
+			span.SetAttribute("db.rows_returned", 2)
+			span.End()
+
+			data := map[string]interface{}{}
+			Expect(json.Unmarshal(buf.Bytes(), &data)).To(Succeed())
+			Expect(data["name"]).To(Equal("query"))
+			Expect(data["attributes"]).To(HaveKeyWithValue("db.rows_returned", BeNumerically("==", 2)))
+		})
+	})
+})